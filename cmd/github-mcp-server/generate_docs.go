@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
 	"regexp"
@@ -32,21 +33,25 @@ func init() {
 	rootCmd.AddCommand(generateDocsCmd)
 }
 
-// mockGetClient returns a mock GitHub client for documentation generation
+// mockGetClient returns a mock GitHub client for building tool definitions, e.g. for documentation generation
 func mockGetClient(_ context.Context) (*gogithub.Client, error) {
 	return gogithub.NewClient(nil), nil
 }
 
-// mockGetGQLClient returns a mock GraphQL client for documentation generation
+// mockGetGQLClient returns a mock GraphQL client for building tool definitions
 func mockGetGQLClient(_ context.Context) (*githubv4.Client, error) {
 	return githubv4.NewClient(nil), nil
 }
 
-// mockGetRawClient returns a mock raw client for documentation generation
+// mockGetRawClient returns a mock raw client for building tool definitions
 func mockGetRawClient(_ context.Context) (*raw.Client, error) {
 	return nil, nil
 }
 
+func mockGetDownloadClient(_ context.Context) (*http.Client, error) {
+	return nil, nil
+}
+
 func generateAllDocs() error {
 	if err := generateReadmeDocs("README.md"); err != nil {
 		return fmt.Errorf("failed to generate README docs: %w", err)
@@ -64,7 +69,7 @@ func generateReadmeDocs(readmePath string) error {
 	t, _ := translations.TranslationHelper()
 
 	// Create toolset group with mock clients
-	tsg := github.DefaultToolsetGroup(false, mockGetClient, mockGetGQLClient, mockGetRawClient, t, 5000)
+	tsg := github.DefaultToolsetGroup(false, mockGetClient, mockGetGQLClient, mockGetRawClient, mockGetDownloadClient, t, 5000)
 
 	// Generate toolsets documentation
 	toolsetsDoc := generateToolsetsDoc(tsg)
@@ -302,7 +307,7 @@ func generateRemoteToolsetsDoc() string {
 	t, _ := translations.TranslationHelper()
 
 	// Create toolset group with mock clients
-	tsg := github.DefaultToolsetGroup(false, mockGetClient, mockGetGQLClient, mockGetRawClient, t, 5000)
+	tsg := github.DefaultToolsetGroup(false, mockGetClient, mockGetGQLClient, mockGetRawClient, mockGetDownloadClient, t, 5000)
 
 	// Generate table header
 	buf.WriteString("| Name           | Description                                      | API URL                                               | 1-Click Install (VS Code)                                                                                                                                                                                                 | Read-only Link                                                                                                 | 1-Click Read-only Install (VS Code)                                                                                                                                                                                                 |\n")
@@ -6,8 +6,13 @@ import (
 	"os"
 	"strings"
 
+	"github.com/github/github-mcp-server/internal/config"
 	"github.com/github/github-mcp-server/internal/ghmcp"
+	"github.com/github/github-mcp-server/pkg/auth"
+	"github.com/github/github-mcp-server/pkg/cassette"
 	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/ratelimit"
+	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -24,6 +29,9 @@ var (
 		Short:   "GitHub MCP Server",
 		Long:    `A GitHub MCP server that handles various tools and resources.`,
 		Version: fmt.Sprintf("Version: %s\nCommit: %s\nBuild Date: %s", version, commit, date),
+		PersistentPreRunE: func(_ *cobra.Command, _ []string) error {
+			return loadConfigFile()
+		},
 	}
 
 	stdioCmd = &cobra.Command{
@@ -32,8 +40,28 @@ var (
 		Long:  `Start a server that communicates via standard input/output streams using JSON-RPC messages.`,
 		RunE: func(_ *cobra.Command, _ []string) error {
 			token := viper.GetString("personal_access_token")
-			if token == "" {
-				return errors.New("GITHUB_PERSONAL_ACCESS_TOKEN not set")
+			app := ghmcp.AppConfig{
+				ID:             viper.GetInt64("app_id"),
+				InstallationID: viper.GetInt64("app_installation_id"),
+				PrivateKeyPath: viper.GetString("app_private_key_path"),
+			}
+			switch {
+			case token == "" && app.ID == 0 && viper.GetString("replay") != "":
+				// Replayed responses need no credentials, as they were scrubbed from the cassettes anyway
+				token = cassette.Redacted
+			case token == "" && app.ID == 0:
+				// Fall back to the token stored by `auth login`
+				store, err := auth.DefaultCredentialStore()
+				if err != nil {
+					return err
+				}
+				token, err = ghmcp.StoredToken(store, viper.GetString("host"))
+				if errors.Is(err, auth.ErrCredentialNotFound) {
+					return errors.New("GITHUB_PERSONAL_ACCESS_TOKEN not set, set it or run `github-mcp-server auth login`")
+				}
+				if err != nil {
+					return fmt.Errorf("failed to load stored credential: %w", err)
+				}
 			}
 
 			// If you're wondering why we're not using viper.GetStringSlice("toolsets"),
@@ -49,23 +77,424 @@ var (
 				enabledToolsets = github.GetDefaultToolsetIDs()
 			}
 
+			tools, excludeTools, err := toolPatterns()
+			if err != nil {
+				return err
+			}
+
+			confirmTools, err := confirmToolPatterns()
+			if err != nil {
+				return err
+			}
+
+			allowRepos, denyRepos, err := repoPatterns()
+			if err != nil {
+				return err
+			}
+
+			auditConfig, err := auditConfig()
+			if err != nil {
+				return err
+			}
+
+			logConfig, err := logConfig()
+			if err != nil {
+				return err
+			}
+
+			hosts, err := additionalHosts()
+			if err != nil {
+				return err
+			}
+			owners, err := ownerHosts()
+			if err != nil {
+				return err
+			}
+
 			stdioServerConfig := ghmcp.StdioServerConfig{
 				Version:              version,
 				Host:                 viper.GetString("host"),
+				APIURLs:              apiURLs(),
 				Token:                token,
+				App:                  app,
 				EnabledToolsets:      enabledToolsets,
 				DynamicToolsets:      viper.GetBool("dynamic_toolsets"),
 				ReadOnly:             viper.GetBool("read-only"),
+				DryRun:               viper.GetBool("dry_run"),
+				Tools:                tools,
+				ExcludeTools:         excludeTools,
+				AllowRepos:           allowRepos,
+				DenyRepos:            denyRepos,
+				ConfirmTools:         confirmTools,
+				Lockdown:             viper.GetBool("lockdown"),
+				Sanitize:             viper.GetBool("sanitize"),
 				ExportTranslations:   viper.GetBool("export-translations"),
 				EnableCommandLogging: viper.GetBool("enable-command-logging"),
 				LogFilePath:          viper.GetString("log-file"),
+				Log:                  logConfig,
 				ContentWindowSize:    viper.GetInt("content-window-size"),
+				MaxOutputTokens:      viper.GetInt("max_output_tokens"),
+				HideUnusableTools:    viper.GetBool("hide_unusable_tools"),
+				RateLimit:            rateLimitConfig(),
+				Cache:                cacheConfig(),
+				Transport:            transportConfig(),
+				AdditionalHosts:      hosts,
+				OwnerHosts:           owners,
+				Audit:                auditConfig,
+				Tracing:              ghmcp.TracingConfig{Endpoint: viper.GetString("otlp_endpoint")},
 			}
 			return ghmcp.RunStdioServer(stdioServerConfig)
 		},
 	}
+
+	httpCmd = &cobra.Command{
+		Use:   "http",
+		Short: "Start HTTP server",
+		Long:  `Start a server that communicates via the MCP streamable HTTP transport (and the legacy SSE transport), authenticating each request with the GitHub token in its Authorization header.`,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			// See stdioCmd for why we're not using viper.GetStringSlice("toolsets").
+			var enabledToolsets []string
+			if err := viper.UnmarshalKey("toolsets", &enabledToolsets); err != nil {
+				return fmt.Errorf("failed to unmarshal toolsets: %w", err)
+			}
+
+			if len(enabledToolsets) == 0 {
+				enabledToolsets = github.GetDefaultToolsetIDs()
+			}
+
+			tools, excludeTools, err := toolPatterns()
+			if err != nil {
+				return err
+			}
+
+			confirmTools, err := confirmToolPatterns()
+			if err != nil {
+				return err
+			}
+
+			allowRepos, denyRepos, err := repoPatterns()
+			if err != nil {
+				return err
+			}
+
+			auditConfig, err := auditConfig()
+			if err != nil {
+				return err
+			}
+
+			logConfig, err := logConfig()
+			if err != nil {
+				return err
+			}
+
+			httpServerConfig := ghmcp.HTTPServerConfig{
+				Version:            version,
+				Host:               viper.GetString("host"),
+				APIURLs:            apiURLs(),
+				Address:            viper.GetString("address"),
+				EnabledToolsets:    enabledToolsets,
+				DynamicToolsets:    viper.GetBool("dynamic_toolsets"),
+				ReadOnly:           viper.GetBool("read-only"),
+				DryRun:             viper.GetBool("dry_run"),
+				Tools:              tools,
+				ExcludeTools:       excludeTools,
+				AllowRepos:         allowRepos,
+				DenyRepos:          denyRepos,
+				ConfirmTools:       confirmTools,
+				Lockdown:           viper.GetBool("lockdown"),
+				Sanitize:           viper.GetBool("sanitize"),
+				ExportTranslations: viper.GetBool("export-translations"),
+				LogFilePath:        viper.GetString("log-file"),
+				Log:                logConfig,
+				ContentWindowSize:  viper.GetInt("content-window-size"),
+				MaxOutputTokens:    viper.GetInt("max_output_tokens"),
+				RateLimit:          rateLimitConfig(),
+				Cache:              cacheConfig(),
+				Transport:          transportConfig(),
+				Audit:              auditConfig,
+				Tracing:            ghmcp.TracingConfig{Endpoint: viper.GetString("otlp_endpoint")},
+			}
+			return ghmcp.RunHTTPServer(httpServerConfig)
+		},
+	}
+
+	authCmd = &cobra.Command{
+		Use:   "auth",
+		Short: "Manage stored GitHub credentials",
+		Long:  `Log in to a GitHub host using the OAuth device flow, storing the token for the stdio server to use when GITHUB_PERSONAL_ACCESS_TOKEN is not set.`,
+	}
+
+	authLoginCmd = &cobra.Command{
+		Use:   "login",
+		Short: "Log in to a GitHub host",
+		Long:  `Authorize the server with the OAuth device flow and store the resulting token for the host.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			authConfig, err := newAuthConfig(cmd)
+			if err != nil {
+				return err
+			}
+			if len(authConfig.Scopes) == 0 {
+				if authConfig.Scopes, err = toolsetScopes(); err != nil {
+					return err
+				}
+			}
+			return ghmcp.RunAuthLogin(authConfig)
+		},
+	}
+
+	authStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show the stored credential for a GitHub host",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			authConfig, err := newAuthConfig(cmd)
+			if err != nil {
+				return err
+			}
+			return ghmcp.RunAuthStatus(authConfig)
+		},
+	}
+
+	authLogoutCmd = &cobra.Command{
+		Use:   "logout",
+		Short: "Remove the stored credential for a GitHub host",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			authConfig, err := newAuthConfig(cmd)
+			if err != nil {
+				return err
+			}
+			return ghmcp.RunAuthLogout(authConfig)
+		},
+	}
 )
 
+// toolPatterns returns the glob patterns of the tools to offer and to exclude.
+func toolPatterns() ([]string, []string, error) {
+	// See stdioCmd for why we're not using viper.GetStringSlice.
+	var tools, excludeTools []string
+	if err := viper.UnmarshalKey("tools", &tools); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal tools: %w", err)
+	}
+	if err := viper.UnmarshalKey("exclude_tools", &excludeTools); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal excluded tools: %w", err)
+	}
+	return tools, excludeTools, nil
+}
+
+// confirmToolPatterns returns the glob patterns of the tools whose calls the user is asked to confirm.
+func confirmToolPatterns() ([]string, error) {
+	// See stdioCmd for why we're not using viper.GetStringSlice.
+	var confirmTools []string
+	if err := viper.UnmarshalKey("confirm_tools", &confirmTools); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal confirmed tools: %w", err)
+	}
+	return confirmTools, nil
+}
+
+// repoPatterns returns the owner/repo glob patterns of the repositories tools may and may not access.
+func repoPatterns() ([]string, []string, error) {
+	var allowRepos, denyRepos []string
+	if err := viper.UnmarshalKey("allow_repos", &allowRepos); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal allowed repositories: %w", err)
+	}
+	if err := viper.UnmarshalKey("deny_repos", &denyRepos); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal denied repositories: %w", err)
+	}
+	return allowRepos, denyRepos, nil
+}
+
+// toolsetScopes returns the OAuth scopes the tools of the enabled toolsets need, or of every toolset when they
+// are enabled dynamically.
+func toolsetScopes() ([]string, error) {
+	// See stdioCmd for why we're not using viper.GetStringSlice("toolsets").
+	var enabledToolsets []string
+	if err := viper.UnmarshalKey("toolsets", &enabledToolsets); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal toolsets: %w", err)
+	}
+	if len(enabledToolsets) == 0 {
+		enabledToolsets = github.GetDefaultToolsetIDs()
+	}
+	if viper.GetBool("dynamic_toolsets") {
+		enabledToolsets = []string{"all"}
+	}
+
+	tsg := github.DefaultToolsetGroup(viper.GetBool("read-only"), mockGetClient, mockGetGQLClient, mockGetRawClient, mockGetDownloadClient, translations.NullTranslationHelper, 0)
+	if err := tsg.EnableToolsets(enabledToolsets); err != nil {
+		return nil, err
+	}
+	return github.ToolsetScopes(tsg), nil
+}
+
+// additionalHosts returns the hosts to serve alongside the default one, authenticated with the tokens
+// stored for them by `auth login`.
+func additionalHosts() ([]ghmcp.HostConfig, error) {
+	// See stdioCmd for why we're not using viper.GetStringSlice.
+	var hosts []string
+	if err := viper.UnmarshalKey("hosts", &hosts); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal hosts: %w", err)
+	}
+	if len(hosts) == 0 {
+		return nil, nil
+	}
+
+	store, err := auth.DefaultCredentialStore()
+	if err != nil {
+		return nil, err
+	}
+	configs := make([]ghmcp.HostConfig, 0, len(hosts))
+	for _, host := range hosts {
+		token, err := ghmcp.StoredToken(store, host)
+		if errors.Is(err, auth.ErrCredentialNotFound) {
+			return nil, fmt.Errorf("no token stored for %s, run `github-mcp-server auth login --gh-host %s`", host, host)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load stored credential for %s: %w", host, err)
+		}
+		configs = append(configs, ghmcp.HostConfig{Host: host, Token: token})
+	}
+	return configs, nil
+}
+
+// ownerHosts parses the owner=host pairs routing owners to hosts.
+func ownerHosts() (map[string]string, error) {
+	var pairs []string
+	if err := viper.UnmarshalKey("owner_hosts", &pairs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal owner hosts: %w", err)
+	}
+
+	ownerHosts := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		owner, host, ok := strings.Cut(pair, "=")
+		if !ok || owner == "" || host == "" {
+			return nil, fmt.Errorf("invalid owner host %q, expected owner=host", pair)
+		}
+		ownerHosts[owner] = host
+	}
+	return ownerHosts, nil
+}
+
+func apiURLs() ghmcp.APIURLs {
+	return ghmcp.APIURLs{
+		REST:    viper.GetString("rest_url"),
+		GraphQL: viper.GetString("graphql_url"),
+		Upload:  viper.GetString("upload_url"),
+		Raw:     viper.GetString("raw_url"),
+	}
+}
+
+func rateLimitConfig() ghmcp.RateLimitConfig {
+	return ghmcp.RateLimitConfig{
+		MaxRetries: viper.GetInt("rate_limit_max_retries"),
+		MaxWait:    viper.GetDuration("rate_limit_max_wait"),
+	}
+}
+
+func cacheConfig() ghmcp.CacheConfig {
+	return ghmcp.CacheConfig{
+		Enabled: viper.GetBool("cache") || viper.GetString("cache_dir") != "",
+		Dir:     viper.GetString("cache_dir"),
+		MaxSize: viper.GetInt64("cache_max_size") << 20,
+	}
+}
+
+// auditConfig returns where to keep the audit log of write tool calls, and which further arguments to redact.
+func auditConfig() (ghmcp.AuditConfig, error) {
+	// See stdioCmd for why we're not using viper.GetStringSlice.
+	var redact []string
+	if err := viper.UnmarshalKey("audit_log_redact", &redact); err != nil {
+		return ghmcp.AuditConfig{}, fmt.Errorf("failed to unmarshal audit log redactions: %w", err)
+	}
+	return ghmcp.AuditConfig{
+		Path:   viper.GetString("audit_log"),
+		Redact: redact,
+	}, nil
+}
+
+// logConfig returns the format and rotation of the log, and which further text to redact from logged messages.
+func logConfig() (ghmcp.LogConfig, error) {
+	// See stdioCmd for why we're not using viper.GetStringSlice.
+	var redact []string
+	if err := viper.UnmarshalKey("log_redact", &redact); err != nil {
+		return ghmcp.LogConfig{}, fmt.Errorf("failed to unmarshal log redactions: %w", err)
+	}
+	return ghmcp.LogConfig{
+		Format:     viper.GetString("log_format"),
+		MaxSize:    viper.GetInt64("log_max_size") << 20,
+		MaxBackups: viper.GetInt("log_max_backups"),
+		Redact:     redact,
+	}, nil
+}
+
+func transportConfig() ghmcp.TransportConfig {
+	return ghmcp.TransportConfig{
+		Proxy:          viper.GetString("proxy"),
+		CAFile:         viper.GetString("ca_file"),
+		ClientCertFile: viper.GetString("client_cert_file"),
+		ClientKeyFile:  viper.GetString("client_key_file"),
+		Record:         viper.GetString("record"),
+		Replay:         viper.GetString("replay"),
+	}
+}
+
+// configFlags are the flags that can also be set in a config file, by name, and configKeys the viper
+// keys they are bound to.
+var (
+	configFlags = make(map[string]*pflag.Flag)
+	configKeys  = make(map[string]string)
+)
+
+// bindFlag binds flag to the viper key, making it settable in a config file too.
+func bindFlag(key string, flag *pflag.Flag) {
+	_ = viper.BindPFlag(key, flag)
+	configFlags[flag.Name] = flag
+	configKeys[flag.Name] = key
+}
+
+// loadConfigFile merges the settings of the config file and profile given with --config and --profile
+// into viper, below flags and environment variables.
+func loadConfigFile() error {
+	path := viper.GetString("config")
+	profile := viper.GetString("profile")
+	if path == "" {
+		if profile != "" {
+			return errors.New("--profile requires a config file, set with --config")
+		}
+		return nil
+	}
+
+	settings, err := config.Load(path, profile, configFlags)
+	if err != nil {
+		return err
+	}
+	values := make(map[string]any, len(settings))
+	for name, value := range settings {
+		values[configKeys[name]] = value
+	}
+	return viper.MergeConfigMap(values)
+}
+
+func newAuthConfig(cmd *cobra.Command) (ghmcp.AuthConfig, error) {
+	store, err := auth.DefaultCredentialStore()
+	if err != nil {
+		return ghmcp.AuthConfig{}, err
+	}
+
+	// See stdioCmd for why we're not using viper.GetStringSlice.
+	var scopes []string
+	if err := viper.UnmarshalKey("oauth_scopes", &scopes); err != nil {
+		return ghmcp.AuthConfig{}, fmt.Errorf("failed to unmarshal OAuth scopes: %w", err)
+	}
+
+	return ghmcp.AuthConfig{
+		Version:   version,
+		Host:      viper.GetString("host"),
+		ClientID:  viper.GetString("oauth_client_id"),
+		Scopes:    scopes,
+		Store:     store,
+		Out:       cmd.OutOrStdout(),
+		Transport: transportConfig(),
+	}, nil
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
 	rootCmd.SetGlobalNormalizationFunc(wordSepNormalizeFunc)
@@ -73,27 +502,122 @@ func init() {
 	rootCmd.SetVersionTemplate("{{.Short}}\n{{.Version}}\n")
 
 	// Add global flags that will be shared by all commands
+	rootCmd.PersistentFlags().String("config", "", "Path to a YAML or JSON config file setting any of the flags")
+	rootCmd.PersistentFlags().String("profile", "", "Name of the config file profile to use")
 	rootCmd.PersistentFlags().StringSlice("toolsets", nil, github.GenerateToolsetsHelp())
 	rootCmd.PersistentFlags().Bool("dynamic-toolsets", false, "Enable dynamic toolsets")
 	rootCmd.PersistentFlags().Bool("read-only", false, "Restrict the server to read-only operations")
+	rootCmd.PersistentFlags().Bool("dry-run", false, "Make write tools return the changes they would make, without making them")
+	rootCmd.PersistentFlags().StringSlice("tools", nil, "Comma-separated glob patterns of the tools to offer from the enabled toolsets, e.g. get_*,list_*")
+	rootCmd.PersistentFlags().StringSlice("confirm-tools", github.DefaultConfirmTools, "Comma-separated glob patterns of the tools whose calls the user is asked to confirm, if their client supports elicitation")
+	rootCmd.PersistentFlags().Bool("lockdown", false, "Withhold the bodies of issues and comments written by users who cannot push to their repository")
+	rootCmd.PersistentFlags().Bool("sanitize", true, "Remove hidden content, such as HTML comments and invisible Unicode characters, from the issues, pull requests, comments and other user-written text returned by tools")
+	rootCmd.PersistentFlags().StringSlice("exclude-tools", nil, "Comma-separated glob patterns of the tools to remove from the enabled toolsets, e.g. merge_pull_request,delete_*")
 	rootCmd.PersistentFlags().String("log-file", "", "Path to log file")
+	rootCmd.PersistentFlags().String("log-format", "text", "Format of the log, text or json")
+	rootCmd.PersistentFlags().Int64("log-max-size", 0, "Rotate the log file before it grows beyond this many megabytes, or 0 to never rotate it")
+	rootCmd.PersistentFlags().Int("log-max-backups", 3, "Number of rotated log files to keep")
 	rootCmd.PersistentFlags().Bool("enable-command-logging", false, "When enabled, the server will log all command requests and responses to the log file")
+	rootCmd.PersistentFlags().StringSlice("log-redact", nil, "Comma-separated regular expressions of further text to redact from the logged requests and responses, in addition to GitHub tokens")
+	rootCmd.PersistentFlags().String("audit-log", "", "Path to a file to append a JSON line to for every call to a write tool")
+	rootCmd.PersistentFlags().StringSlice("audit-log-redact", nil, "Comma-separated glob patterns of further argument names whose values are redacted from the audit log, e.g. body")
+	rootCmd.PersistentFlags().String("otlp-endpoint", "", "URL of an OTLP/HTTP collector to send OpenTelemetry traces to, e.g. http://localhost:4318")
+	rootCmd.PersistentFlags().StringSlice("allow-repos", nil, "Comma-separated owner/repo glob patterns of the only repositories tools may access, e.g. myorg/*")
+	rootCmd.PersistentFlags().StringSlice("deny-repos", nil, "Comma-separated owner/repo glob patterns of repositories tools may not access, e.g. myorg/secrets-*")
 	rootCmd.PersistentFlags().Bool("export-translations", false, "Save translations to a JSON file")
 	rootCmd.PersistentFlags().String("gh-host", "", "Specify the GitHub hostname (for GitHub Enterprise etc.)")
 	rootCmd.PersistentFlags().Int("content-window-size", 5000, "Specify the content window size")
+	rootCmd.PersistentFlags().Int("max-output-tokens", 25000, "Truncate tool results beyond roughly this many tokens, the rest being paged through with get_more_output, or 0 to never truncate them")
+	rootCmd.PersistentFlags().String("rest-url", "", "Override the REST API base URL derived from the GitHub host")
+	rootCmd.PersistentFlags().String("graphql-url", "", "Override the GraphQL API URL derived from the GitHub host")
+	rootCmd.PersistentFlags().String("upload-url", "", "Override the upload API base URL derived from the GitHub host")
+	rootCmd.PersistentFlags().String("raw-url", "", "Override the raw content base URL derived from the GitHub host")
+	rootCmd.PersistentFlags().Int64("app-id", 0, "ID of the GitHub App to authenticate as, instead of using a personal access token")
+	rootCmd.PersistentFlags().Int64("app-installation-id", 0, "ID of the GitHub App installation to authenticate as")
+	rootCmd.PersistentFlags().String("app-private-key-path", "", "Path to the PEM encoded private key of the GitHub App")
+	rootCmd.PersistentFlags().String("proxy", "", "URL of the proxy to connect to GitHub through, instead of HTTP_PROXY and HTTPS_PROXY")
+	rootCmd.PersistentFlags().String("ca-file", "", "Path to a PEM encoded bundle of certificate authorities to trust in addition to the system's")
+	rootCmd.PersistentFlags().String("client-cert-file", "", "Path to a PEM encoded client certificate to present for mutual TLS")
+	rootCmd.PersistentFlags().String("client-key-file", "", "Path to the PEM encoded private key of the client certificate")
+	rootCmd.PersistentFlags().String("record", "", "Directory to record every exchange with GitHub into a new cassette in, with credentials scrubbed")
+	rootCmd.PersistentFlags().String("replay", "", "Directory of cassettes, or a single cassette, to serve the responses to every request to GitHub from, without network access")
+	rootCmd.PersistentFlags().Int("rate-limit-max-retries", ratelimit.DefaultMaxRetries, "Maximum number of times a rate limited request is retried")
+	rootCmd.PersistentFlags().Duration("rate-limit-max-wait", ratelimit.DefaultMaxWait, "Maximum total time spent waiting to retry a rate limited request")
+	rootCmd.PersistentFlags().Bool("cache", false, "Cache REST API responses in memory, revalidating them with conditional requests")
+	rootCmd.PersistentFlags().String("cache-dir", "", "Cache REST API responses on disk in the given directory instead of in memory")
+	rootCmd.PersistentFlags().Int64("cache-max-size", 64, "Maximum size of the REST API response cache, in megabytes")
+
+	// The config file cannot set its own location, so these are bound directly
+	_ = viper.BindPFlag("config", rootCmd.PersistentFlags().Lookup("config"))
+	_ = viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
 
 	// Bind flag to viper
-	_ = viper.BindPFlag("toolsets", rootCmd.PersistentFlags().Lookup("toolsets"))
-	_ = viper.BindPFlag("dynamic_toolsets", rootCmd.PersistentFlags().Lookup("dynamic-toolsets"))
-	_ = viper.BindPFlag("read-only", rootCmd.PersistentFlags().Lookup("read-only"))
-	_ = viper.BindPFlag("log-file", rootCmd.PersistentFlags().Lookup("log-file"))
-	_ = viper.BindPFlag("enable-command-logging", rootCmd.PersistentFlags().Lookup("enable-command-logging"))
-	_ = viper.BindPFlag("export-translations", rootCmd.PersistentFlags().Lookup("export-translations"))
-	_ = viper.BindPFlag("host", rootCmd.PersistentFlags().Lookup("gh-host"))
-	_ = viper.BindPFlag("content-window-size", rootCmd.PersistentFlags().Lookup("content-window-size"))
+	bindFlag("toolsets", rootCmd.PersistentFlags().Lookup("toolsets"))
+	bindFlag("dynamic_toolsets", rootCmd.PersistentFlags().Lookup("dynamic-toolsets"))
+	bindFlag("read-only", rootCmd.PersistentFlags().Lookup("read-only"))
+	bindFlag("dry_run", rootCmd.PersistentFlags().Lookup("dry-run"))
+	bindFlag("tools", rootCmd.PersistentFlags().Lookup("tools"))
+	bindFlag("exclude_tools", rootCmd.PersistentFlags().Lookup("exclude-tools"))
+	bindFlag("confirm_tools", rootCmd.PersistentFlags().Lookup("confirm-tools"))
+	bindFlag("lockdown", rootCmd.PersistentFlags().Lookup("lockdown"))
+	bindFlag("sanitize", rootCmd.PersistentFlags().Lookup("sanitize"))
+	bindFlag("log-file", rootCmd.PersistentFlags().Lookup("log-file"))
+	bindFlag("log_format", rootCmd.PersistentFlags().Lookup("log-format"))
+	bindFlag("log_max_size", rootCmd.PersistentFlags().Lookup("log-max-size"))
+	bindFlag("log_max_backups", rootCmd.PersistentFlags().Lookup("log-max-backups"))
+	bindFlag("enable-command-logging", rootCmd.PersistentFlags().Lookup("enable-command-logging"))
+	bindFlag("log_redact", rootCmd.PersistentFlags().Lookup("log-redact"))
+	bindFlag("audit_log", rootCmd.PersistentFlags().Lookup("audit-log"))
+	bindFlag("audit_log_redact", rootCmd.PersistentFlags().Lookup("audit-log-redact"))
+	bindFlag("otlp_endpoint", rootCmd.PersistentFlags().Lookup("otlp-endpoint"))
+	bindFlag("allow_repos", rootCmd.PersistentFlags().Lookup("allow-repos"))
+	bindFlag("deny_repos", rootCmd.PersistentFlags().Lookup("deny-repos"))
+	bindFlag("export-translations", rootCmd.PersistentFlags().Lookup("export-translations"))
+	bindFlag("host", rootCmd.PersistentFlags().Lookup("gh-host"))
+	bindFlag("content-window-size", rootCmd.PersistentFlags().Lookup("content-window-size"))
+	bindFlag("max_output_tokens", rootCmd.PersistentFlags().Lookup("max-output-tokens"))
+	bindFlag("rest_url", rootCmd.PersistentFlags().Lookup("rest-url"))
+	bindFlag("graphql_url", rootCmd.PersistentFlags().Lookup("graphql-url"))
+	bindFlag("upload_url", rootCmd.PersistentFlags().Lookup("upload-url"))
+	bindFlag("raw_url", rootCmd.PersistentFlags().Lookup("raw-url"))
+	bindFlag("app_id", rootCmd.PersistentFlags().Lookup("app-id"))
+	bindFlag("app_installation_id", rootCmd.PersistentFlags().Lookup("app-installation-id"))
+	bindFlag("app_private_key_path", rootCmd.PersistentFlags().Lookup("app-private-key-path"))
+	bindFlag("proxy", rootCmd.PersistentFlags().Lookup("proxy"))
+	bindFlag("ca_file", rootCmd.PersistentFlags().Lookup("ca-file"))
+	bindFlag("client_cert_file", rootCmd.PersistentFlags().Lookup("client-cert-file"))
+	bindFlag("client_key_file", rootCmd.PersistentFlags().Lookup("client-key-file"))
+	bindFlag("record", rootCmd.PersistentFlags().Lookup("record"))
+	bindFlag("replay", rootCmd.PersistentFlags().Lookup("replay"))
+	bindFlag("rate_limit_max_retries", rootCmd.PersistentFlags().Lookup("rate-limit-max-retries"))
+	bindFlag("rate_limit_max_wait", rootCmd.PersistentFlags().Lookup("rate-limit-max-wait"))
+	bindFlag("cache", rootCmd.PersistentFlags().Lookup("cache"))
+	bindFlag("cache_dir", rootCmd.PersistentFlags().Lookup("cache-dir"))
+	bindFlag("cache_max_size", rootCmd.PersistentFlags().Lookup("cache-max-size"))
+
+	// Add stdio flags
+	stdioCmd.Flags().Bool("hide-unusable-tools", true, "Hide the tools the token lacks the scopes for, checked once at startup")
+	stdioCmd.Flags().StringSlice("hosts", nil, "Additional GitHub hosts to serve, each authenticated with the token stored for it by auth login")
+	stdioCmd.Flags().StringSlice("owner-hosts", nil, "Comma-separated owner=host pairs routing the tool calls for an owner to one of the hosts")
+	bindFlag("hide_unusable_tools", stdioCmd.Flags().Lookup("hide-unusable-tools"))
+	bindFlag("hosts", stdioCmd.Flags().Lookup("hosts"))
+	bindFlag("owner_hosts", stdioCmd.Flags().Lookup("owner-hosts"))
+
+	// Add http flags
+	httpCmd.Flags().String("address", ":8080", "Address for the HTTP server to listen on")
+	bindFlag("address", httpCmd.Flags().Lookup("address"))
+
+	// Add auth flags
+	authLoginCmd.Flags().String("client-id", "", "Client ID of the OAuth App to authorize, which must have the device flow enabled")
+	authLoginCmd.Flags().StringSlice("scopes", nil, "Comma-separated list of OAuth scopes to request (default: the scopes the tools of the enabled toolsets need)")
+	bindFlag("oauth_client_id", authLoginCmd.Flags().Lookup("client-id"))
+	bindFlag("oauth_scopes", authLoginCmd.Flags().Lookup("scopes"))
 
 	// Add subcommands
+	authCmd.AddCommand(authLoginCmd, authStatusCmd, authLogoutCmd)
 	rootCmd.AddCommand(stdioCmd)
+	rootCmd.AddCommand(httpCmd)
+	rootCmd.AddCommand(authCmd)
 }
 
 func initConfig() {
@@ -0,0 +1,238 @@
+// Package config loads the server's settings from a YAML or JSON configuration file.
+//
+// Settings are keyed by the name of the command line flag they stand in for, such as `gh-host`,
+// `toolsets` or `read-only`. Settings at the top level of the file apply to every profile, and
+// those of the profile selected under `profiles` override them:
+//
+//	toolsets: [repos, issues, pull_requests]
+//	profiles:
+//	  work-ghes:
+//	    gh-host: https://github.example.com
+//	    read-only: true
+//	  oss-dotcom:
+//	    toolsets: [all]
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// profilesKey is the key of the file under which profiles are defined.
+const profilesKey = "profiles"
+
+// Load reads the configuration file at path and returns the settings of the named profile, or only
+// the top level settings if profile is empty. The settings are keyed by flag name, with each value
+// converted to the type of its flag in flags. Errors name the offending key.
+func Load(path, profile string, flags map[string]*pflag.Flag) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	raw, err := parse(path, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	settings, err := loadSettings(raw, "", flags)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	profiles, err := loadProfiles(raw[profilesKey], flags)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	if profile == "" {
+		return settings, nil
+	}
+	overrides, ok := profiles[profile]
+	if !ok && len(profiles) == 0 {
+		return nil, fmt.Errorf("profile %q not found in config file %s, which defines no profiles", profile, path)
+	}
+	if !ok {
+		return nil, fmt.Errorf("profile %q not found in config file %s, expected one of: %s", profile, path, strings.Join(sortedKeys(profiles), ", "))
+	}
+	for name, value := range overrides {
+		settings[name] = value
+	}
+	return settings, nil
+}
+
+// parse decodes data as JSON if path has a .json extension, and as YAML otherwise.
+func parse(path string, data []byte) (map[string]any, error) {
+	raw := make(map[string]any)
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&raw); err != nil {
+			return nil, err
+		}
+		return raw, nil
+	}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+func loadProfiles(raw any, flags map[string]*pflag.Flag) (map[string]map[string]any, error) {
+	profiles := make(map[string]map[string]any)
+	if raw == nil {
+		return profiles, nil
+	}
+
+	entries, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%s: expected a map of profile names to settings", profilesKey)
+	}
+	for name, entry := range entries {
+		prefix := profilesKey + "." + name
+		values, ok := entry.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a map of settings", prefix)
+		}
+		if _, ok := values[profilesKey]; ok {
+			return nil, fmt.Errorf("%s.%s: profiles cannot be nested", prefix, profilesKey)
+		}
+		settings, err := loadSettings(values, prefix+".", flags)
+		if err != nil {
+			return nil, err
+		}
+		profiles[name] = settings
+	}
+	return profiles, nil
+}
+
+// loadSettings converts the values of raw to the types of their flags, skipping the profiles.
+// Keys may separate words with underscores instead of dashes, as flags do.
+func loadSettings(raw map[string]any, prefix string, flags map[string]*pflag.Flag) (map[string]any, error) {
+	settings := make(map[string]any)
+	for _, key := range sortedKeys(raw) {
+		if key == profilesKey {
+			continue
+		}
+
+		name := strings.ReplaceAll(key, "_", "-")
+		flag, ok := flags[name]
+		if !ok {
+			return nil, fmt.Errorf("%s%s: unknown setting", prefix, key)
+		}
+		if _, ok := settings[name]; ok {
+			return nil, fmt.Errorf("%s%s: set more than once", prefix, key)
+		}
+
+		value, err := convert(raw[key], flag.Value.Type())
+		if err != nil {
+			return nil, fmt.Errorf("%s%s: %w", prefix, key, err)
+		}
+		settings[name] = value
+	}
+	return settings, nil
+}
+
+// convert checks that value can stand in for a flag of the given type, as reported by pflag.Value.
+func convert(value any, flagType string) (any, error) {
+	switch flagType {
+	case "bool":
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		return nil, fmt.Errorf("expected true or false, got %s", describe(value))
+	case "int", "int64":
+		n, ok := toInt64(value)
+		if !ok {
+			return nil, fmt.Errorf("expected an integer, got %s", describe(value))
+		}
+		if flagType == "int" {
+			return int(n), nil
+		}
+		return n, nil
+	case "string":
+		if s, ok := value.(string); ok {
+			return s, nil
+		}
+		return nil, fmt.Errorf("expected a string, got %s", describe(value))
+	case "stringSlice":
+		// A comma-separated string is accepted too, as it is on the command line
+		if s, ok := value.(string); ok {
+			return strings.Split(s, ","), nil
+		}
+		items, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("expected a list of strings, got %s", describe(value))
+		}
+		values := make([]string, 0, len(items))
+		for i, item := range items {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("item %d: expected a string, got %s", i, describe(item))
+			}
+			values = append(values, s)
+		}
+		return values, nil
+	case "duration":
+		s, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected a duration such as \"30s\", got %s", describe(value))
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return nil, fmt.Errorf("expected a duration such as \"30s\", got %q", s)
+		}
+		return d, nil
+	default:
+		return nil, fmt.Errorf("cannot be set in a config file")
+	}
+}
+
+func toInt64(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint64:
+		return int64(v), v <= 1<<63-1
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func describe(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "nothing"
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []any:
+		return "a list"
+	case map[string]any:
+		return "a map"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,197 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFlags() map[string]*pflag.Flag {
+	flagSet := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flagSet.String("gh-host", "", "")
+	flagSet.StringSlice("toolsets", nil, "")
+	flagSet.Bool("read-only", false, "")
+	flagSet.Int("content-window-size", 5000, "")
+	flagSet.Int64("app-id", 0, "")
+	flagSet.Duration("rate-limit-max-wait", time.Minute, "")
+	flagSet.Float64("ratio", 0, "")
+
+	flags := make(map[string]*pflag.Flag)
+	flagSet.VisitAll(func(flag *pflag.Flag) { flags[flag.Name] = flag })
+	return flags
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+const testYAML = `
+toolsets: [repos, issues]
+content_window_size: 8000
+rate-limit-max-wait: 30s
+profiles:
+  work-ghes:
+    gh-host: https://github.example.com
+    read-only: true
+    app-id: 42
+  oss-dotcom:
+    toolsets: all
+`
+
+func Test_Load(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		profile  string
+		expected map[string]any
+	}{
+		{
+			name:    "top level settings only",
+			file:    "config.yaml",
+			content: testYAML,
+			expected: map[string]any{
+				"toolsets":            []string{"repos", "issues"},
+				"content-window-size": 8000,
+				"rate-limit-max-wait": 30 * time.Second,
+			},
+		},
+		{
+			name:    "profile adds settings",
+			file:    "config.yaml",
+			content: testYAML,
+			profile: "work-ghes",
+			expected: map[string]any{
+				"toolsets":            []string{"repos", "issues"},
+				"content-window-size": 8000,
+				"rate-limit-max-wait": 30 * time.Second,
+				"gh-host":             "https://github.example.com",
+				"read-only":           true,
+				"app-id":              int64(42),
+			},
+		},
+		{
+			name:    "profile overrides settings",
+			file:    "config.yaml",
+			content: testYAML,
+			profile: "oss-dotcom",
+			expected: map[string]any{
+				"toolsets":            []string{"all"},
+				"content-window-size": 8000,
+				"rate-limit-max-wait": 30 * time.Second,
+			},
+		},
+		{
+			name:    "JSON",
+			file:    "config.json",
+			content: `{"content-window-size": 8000, "profiles": {"work-ghes": {"read-only": true}}}`,
+			profile: "work-ghes",
+			expected: map[string]any{
+				"content-window-size": 8000,
+				"read-only":           true,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			settings, err := Load(writeFile(t, tc.file, tc.content), tc.profile, testFlags())
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, settings)
+		})
+	}
+}
+
+func Test_LoadErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		content     string
+		profile     string
+		expectedErr string
+	}{
+		{
+			name:        "unknown setting",
+			file:        "config.yaml",
+			content:     "read-onyl: true",
+			expectedErr: "read-onyl: unknown setting",
+		},
+		{
+			name:        "unknown setting in profile",
+			file:        "config.yaml",
+			content:     "profiles:\n  work-ghes:\n    host: github.example.com",
+			expectedErr: "profiles.work-ghes.host: unknown setting",
+		},
+		{
+			name:        "wrong type",
+			file:        "config.yaml",
+			content:     "read-only: yes please",
+			expectedErr: `read-only: expected true or false, got "yes please"`,
+		},
+		{
+			name:        "wrong type in profile",
+			file:        "config.json",
+			content:     `{"profiles": {"work-ghes": {"content-window-size": 1.5}}}`,
+			expectedErr: "profiles.work-ghes.content-window-size: expected an integer, got 1.5",
+		},
+		{
+			name:        "wrong list item",
+			file:        "config.yaml",
+			content:     "toolsets: [repos, [issues]]",
+			expectedErr: "toolsets: item 1: expected a string, got a list",
+		},
+		{
+			name:        "invalid duration",
+			file:        "config.yaml",
+			content:     "rate-limit-max-wait: forever",
+			expectedErr: `rate-limit-max-wait: expected a duration such as "30s", got "forever"`,
+		},
+		{
+			name:        "unsupported flag type",
+			file:        "config.yaml",
+			content:     "ratio: 0.5",
+			expectedErr: "ratio: cannot be set in a config file",
+		},
+		{
+			name:        "set more than once",
+			file:        "config.yaml",
+			content:     "read-only: true\nread_only: false",
+			expectedErr: "read_only: set more than once",
+		},
+		{
+			name:        "profile is not a map",
+			file:        "config.yaml",
+			content:     "profiles:\n  work-ghes: true",
+			expectedErr: "profiles.work-ghes: expected a map of settings",
+		},
+		{
+			name:        "missing profile",
+			file:        "config.yaml",
+			content:     testYAML,
+			profile:     "personal",
+			expectedErr: `profile "personal" not found in config file`,
+		},
+		{
+			name:        "malformed YAML",
+			file:        "config.yaml",
+			content:     "toolsets: [repos",
+			expectedErr: "failed to parse config file",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Load(writeFile(t, tc.file, tc.content), tc.profile, testFlags())
+			require.Error(t, err)
+			assert.ErrorContains(t, err, tc.expectedErr)
+		})
+	}
+}
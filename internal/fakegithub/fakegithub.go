@@ -0,0 +1,503 @@
+// Package fakegithub provides an in-memory fake of GitHub, serving the REST API, raw file contents and the subset of
+// the GraphQL API used by the server's tools, to run multi-step flows against it in tests without network access.
+//
+// The fake keeps repositories with their git objects, branches, files, issues, pull requests and reviews. It is
+// laid out like GitHub Enterprise Server: the REST API is served under /api/v3/, the GraphQL API at /api/graphql and
+// raw contents under /raw/, so that the server's clients are pointed at it by using its URL as the host:
+//
+//	fake := fakegithub.New("octocat")
+//	api := httptest.NewServer(fake)
+//	cfg := ghmcp.MCPServerConfig{Host: api.URL, ...}
+//
+// Every user may read and write every repository. Requests unknown to the fake are answered with 404 Not Found, and
+// GraphQL fields it does not know with an error, so that tests relying on them fail rather than pass by accident.
+package fakegithub
+
+import (
+	"crypto/sha1" //nolint:gosec // git object names are SHA-1 hashes
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is an in-memory fake of GitHub. It is safe for concurrent use, requests being served one at a time.
+type Server struct {
+	mu  sync.Mutex
+	mux *http.ServeMux
+
+	// login is the user authenticated by tokens not added with AddUser
+	login string
+	// users holds the login of the users added with AddUser, keyed by token
+	users map[string]string
+	// userIDs holds the ID of each user seen, keyed by login
+	userIDs map[string]int64
+
+	repos map[string]*repository
+	// nodes holds the issues, pull requests and reviews, keyed by GraphQL node ID
+	nodes map[string]any
+	// lastID is the last ID given to an object
+	lastID int64
+
+	// now is overridable for testing
+	now func() time.Time
+}
+
+// New returns a fake GitHub where requests are authenticated as the user login, whatever their token.
+func New(login string) *Server {
+	s := &Server{
+		login:   login,
+		users:   make(map[string]string),
+		userIDs: make(map[string]int64),
+		repos:   make(map[string]*repository),
+		nodes:   make(map[string]any),
+		now:     time.Now,
+	}
+	s.mux = http.NewServeMux()
+	s.routeREST()
+	s.mux.HandleFunc("POST /api/graphql", s.serveGraphQL)
+	s.mux.HandleFunc("GET /raw/{owner}/{repo}/{path...}", s.getRaw)
+	return s
+}
+
+// AddUser makes requests carrying token authenticated as the user login.
+func (s *Server) AddUser(login, token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[token] = login
+}
+
+// AddRepository creates the repository owner/name, with files committed to its default branch, main, keyed by path.
+// The repository is left empty, without any branch, if there are no files.
+func (s *Server) AddRepository(owner, name string, files map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repo := s.newRepository(owner, name, "")
+	if len(files) == 0 {
+		return
+	}
+	tree := make(map[string]string, len(files))
+	for path, content := range files {
+		tree[path] = repo.writeBlob([]byte(content))
+	}
+	repo.refs["refs/heads/main"] = repo.writeCommit(repo.writeTree(tree), nil, "Initial commit", owner, s.now())
+}
+
+// File returns the content of the file at path on a branch of the repository owner/name, if there is one.
+func (s *Server) File(owner, name, branch, path string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	repo, ok := s.repos[owner+"/"+name]
+	if !ok {
+		return "", false
+	}
+	sha, ok := repo.refs["refs/heads/"+branch]
+	if !ok {
+		return "", false
+	}
+	blob, ok := repo.trees[repo.commits[sha].tree][path]
+	if !ok {
+		return "", false
+	}
+	return string(repo.blobs[blob]), true
+}
+
+// ServeHTTP serves a request to the REST, GraphQL or raw contents API.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !strings.HasPrefix(r.URL.Path, "/raw/") && r.Header.Get("Authorization") == "" {
+		writeError(w, http.StatusUnauthorized, "Requires authentication")
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// viewer returns the login of the user authenticated by the request.
+func (s *Server) viewer(r *http.Request) string {
+	auth := r.Header.Get("Authorization")
+	for _, scheme := range []string{"Bearer ", "bearer ", "token "} {
+		auth = strings.TrimPrefix(auth, scheme)
+	}
+	if login, ok := s.users[auth]; ok {
+		return login
+	}
+	return s.login
+}
+
+// nextID returns a new ID, unique among all objects.
+func (s *Server) nextID() int64 {
+	s.lastID++
+	return s.lastID
+}
+
+// userID returns the ID of the user login.
+func (s *Server) userID(login string) int64 {
+	id, ok := s.userIDs[login]
+	if !ok {
+		id = s.nextID()
+		s.userIDs[login] = id
+	}
+	return id
+}
+
+// newRepository creates an empty repository.
+func (s *Server) newRepository(owner, name, description string) *repository {
+	repo := &repository{
+		id:            s.nextID(),
+		owner:         owner,
+		name:          name,
+		description:   description,
+		defaultBranch: "main",
+		createdAt:     s.now(),
+		refs:          make(map[string]string),
+		blobs:         make(map[string][]byte),
+		trees:         map[string]map[string]string{emptyTree: {}},
+		commits:       make(map[string]*commit),
+	}
+	s.repos[owner+"/"+name] = repo
+	return repo
+}
+
+// repository is a repository and its git objects. Trees are kept flat, mapping the path of each file in them to the
+// SHA of its blob; the trees of directories are derived from them when listed.
+type repository struct {
+	id            int64
+	owner         string
+	name          string
+	description   string
+	private       bool
+	defaultBranch string
+	createdAt     time.Time
+
+	// refs holds the SHA of the commit each ref points to, keyed by full name, such as refs/heads/main
+	refs    map[string]string
+	blobs   map[string][]byte
+	trees   map[string]map[string]string
+	commits map[string]*commit
+
+	// issues holds the issues and pull requests, which share their numbers, the issue numbered n at n-1
+	issues []*issue
+}
+
+// emptyTree is the SHA of the tree without any file.
+var emptyTree = hashObject("tree", nil)
+
+// commit is a git commit.
+type commit struct {
+	sha     string
+	tree    string
+	parents []string
+	message string
+	author  string
+	date    time.Time
+}
+
+// issue is an issue, or a pull request if pull is set.
+type issue struct {
+	repo        *repository
+	id          int64
+	number      int
+	title       string
+	body        string
+	state       string
+	stateReason string
+	author      string
+	labels      []string
+	assignees   []string
+	comments    []*comment
+	createdAt   time.Time
+	updatedAt   time.Time
+	closedAt    time.Time
+
+	pull *pullRequest
+}
+
+// comment is a comment on an issue or pull request.
+type comment struct {
+	id        int64
+	author    string
+	body      string
+	createdAt time.Time
+}
+
+// pullRequest holds the parts of a pull request that issues do not have.
+type pullRequest struct {
+	head  string
+	base  string
+	draft bool
+	// headSHA and baseSHA are the heads of the branches of the pull request once it is closed, the branches being
+	// followed until then
+	headSHA     string
+	baseSHA     string
+	merged      bool
+	mergedAt    time.Time
+	mergedBy    string
+	mergeCommit string
+	reviews     []*review
+}
+
+// review is a pull request review, pending until it is submitted.
+type review struct {
+	pull        *issue
+	id          int64
+	author      string
+	body        string
+	state       string
+	commitID    string
+	submittedAt time.Time
+	comments    []*reviewComment
+}
+
+// reviewComment is a comment of a review on a file of a pull request.
+type reviewComment struct {
+	id          int64
+	path        string
+	body        string
+	subjectType string
+	line        int
+	side        string
+	startLine   int
+	startSide   string
+	createdAt   time.Time
+}
+
+// hashObject returns the SHA of a git object: the real one for blobs, and a stand-in for trees and commits, whose
+// encoding is simplified.
+func hashObject(kind string, data []byte) string {
+	h := sha1.New() //nolint:gosec // git object names are SHA-1 hashes
+	_, _ = fmt.Fprintf(h, "%s %d\x00", kind, len(data))
+	_, _ = h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeBlob stores content, returning the SHA of its blob.
+func (r *repository) writeBlob(content []byte) string {
+	sha := hashObject("blob", content)
+	r.blobs[sha] = content
+	return sha
+}
+
+// writeTree stores the tree of files, keyed by path, returning its SHA.
+func (r *repository) writeTree(files map[string]string) string {
+	var data strings.Builder
+	for _, path := range sortedPaths(files) {
+		_, _ = fmt.Fprintf(&data, "%s %s\n", files[path], path)
+	}
+	sha := hashObject("tree", []byte(data.String()))
+	r.trees[sha] = files
+	return sha
+}
+
+// writeCommit stores a commit, returning its SHA.
+func (r *repository) writeCommit(tree string, parents []string, message, author string, date time.Time) string {
+	data := fmt.Sprintf("tree %s\nparents %s\nauthor %s %d\n\n%s", tree, strings.Join(parents, " "), author, date.UnixNano(), message)
+	sha := hashObject("commit", []byte(data))
+	r.commits[sha] = &commit{sha: sha, tree: tree, parents: parents, message: message, author: author, date: date}
+	return sha
+}
+
+// resolve returns the SHA of the commit ref names: a commit SHA, a branch or tag, by short or full name, or HEAD for
+// the default branch.
+func (r *repository) resolve(ref string) (string, bool) {
+	if ref == "" || ref == "HEAD" {
+		ref = "refs/heads/" + r.defaultBranch
+	}
+	if _, ok := r.commits[ref]; ok {
+		return ref, true
+	}
+	for _, name := range []string{ref, "refs/" + ref, "refs/heads/" + ref, "refs/tags/" + ref} {
+		if sha, ok := r.refs[name]; ok {
+			return sha, true
+		}
+	}
+	return "", false
+}
+
+// isAncestor reports whether the commit ancestor is reachable from the commit sha.
+func (r *repository) isAncestor(ancestor, sha string) bool {
+	_, ok := r.ancestors(sha)[ancestor]
+	return ok
+}
+
+// ancestors returns the set of commits reachable from sha, including itself.
+func (r *repository) ancestors(sha string) map[string]bool {
+	seen := make(map[string]bool)
+	queue := []string{sha}
+	for len(queue) > 0 {
+		sha, queue = queue[0], queue[1:]
+		if seen[sha] || r.commits[sha] == nil {
+			continue
+		}
+		seen[sha] = true
+		queue = append(queue, r.commits[sha].parents...)
+	}
+	return seen
+}
+
+// history returns the commits reachable from sha, newest first.
+func (r *repository) history(sha string) []*commit {
+	var commits []*commit
+	for sha := range r.ancestors(sha) {
+		commits = append(commits, r.commits[sha])
+	}
+	sort.SliceStable(commits, func(i, j int) bool {
+		if !commits[i].date.Equal(commits[j].date) {
+			return commits[i].date.After(commits[j].date)
+		}
+		return commits[i].sha < commits[j].sha
+	})
+	return commits
+}
+
+// mergeBase returns the newest common ancestor of the commits a and b, if they have one.
+func (r *repository) mergeBase(a, b string) (string, bool) {
+	ancestors := r.ancestors(a)
+	for _, c := range r.history(b) {
+		if ancestors[c.sha] {
+			return c.sha, true
+		}
+	}
+	return "", false
+}
+
+// merge merges the changes made on theirs since their merge base into ours, file by file, returning the tree of
+// the result, or the paths changed on both sides if they conflict.
+func (r *repository) merge(ours, theirs string) (string, []string) {
+	base := emptyTree
+	if sha, ok := r.mergeBase(ours, theirs); ok {
+		base = r.commits[sha].tree
+	}
+	baseFiles, ourFiles, theirFiles := r.trees[base], r.trees[r.commits[ours].tree], r.trees[r.commits[theirs].tree]
+
+	merged := make(map[string]string)
+	var conflicts []string
+	for _, path := range sortedPaths(baseFiles, ourFiles, theirFiles) {
+		baseBlob, ourBlob, theirBlob := baseFiles[path], ourFiles[path], theirFiles[path]
+		blob := ourBlob
+		switch {
+		case ourBlob == theirBlob, theirBlob == baseBlob:
+		case ourBlob == baseBlob:
+			blob = theirBlob
+		default:
+			conflicts = append(conflicts, path)
+		}
+		if blob != "" {
+			merged[path] = blob
+		}
+	}
+	if len(conflicts) > 0 {
+		return "", conflicts
+	}
+	return r.writeTree(merged), nil
+}
+
+// fileChange is a file changed between two trees.
+type fileChange struct {
+	path      string
+	status    string
+	sha       string
+	additions int
+	deletions int
+}
+
+// diff returns the files changed from the tree from to the tree to, sorted by path.
+func (r *repository) diff(from, to string) []fileChange {
+	fromFiles, toFiles := r.trees[from], r.trees[to]
+	var changes []fileChange
+	for _, path := range sortedPaths(fromFiles, toFiles) {
+		fromBlob, toBlob := fromFiles[path], toFiles[path]
+		if fromBlob == toBlob {
+			continue
+		}
+		change := fileChange{path: path, sha: toBlob}
+		switch {
+		case fromBlob == "":
+			change.status = "added"
+		case toBlob == "":
+			change.status, change.sha = "removed", fromBlob
+		default:
+			change.status = "modified"
+		}
+		change.additions, change.deletions = countChangedLines(string(r.blobs[fromBlob]), string(r.blobs[toBlob]))
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// changedFiles returns the files changed by a pull request, from the merge base of its branches to its head.
+func (r *repository) changedFiles(pr *issue) []fileChange {
+	head, base := pr.headSHA(), pr.baseSHA()
+	from := emptyTree
+	if sha, ok := r.mergeBase(base, head); ok {
+		from = r.commits[sha].tree
+	}
+	if r.commits[head] == nil {
+		return nil
+	}
+	return r.diff(from, r.commits[head].tree)
+}
+
+// headSHA returns the SHA of the head of the pull request.
+func (i *issue) headSHA() string {
+	if i.pull.headSHA != "" {
+		return i.pull.headSHA
+	}
+	return i.repo.refs["refs/heads/"+i.pull.head]
+}
+
+// baseSHA returns the SHA of the base of the pull request.
+func (i *issue) baseSHA() string {
+	if i.pull.baseSHA != "" {
+		return i.pull.baseSHA
+	}
+	return i.repo.refs["refs/heads/"+i.pull.base]
+}
+
+// countChangedLines returns the number of lines added and removed going from the text before to the text after,
+// from their longest common subsequence of lines.
+func countChangedLines(before, after string) (int, int) {
+	a, b := splitLines(before), splitLines(after)
+	common := make([]int, len(b)+1)
+	for i := range a {
+		previous := 0
+		for j := range b {
+			current := common[j+1]
+			if a[i] == b[j] {
+				common[j+1] = previous + 1
+			} else if common[j] > common[j+1] {
+				common[j+1] = common[j]
+			}
+			previous = current
+		}
+	}
+	lcs := common[len(b)]
+	return len(b) - lcs, len(a) - lcs
+}
+
+// splitLines returns the lines of text.
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// sortedPaths returns the paths keying any of the trees of files, sorted.
+func sortedPaths(trees ...map[string]string) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, files := range trees {
+		for path := range files {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,142 @@
+package fakegithub
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newClients returns REST and GraphQL clients of a fake GitHub, authenticated as octocat.
+func newClients(t *testing.T) (*Server, *github.Client, *githubv4.Client) {
+	t.Helper()
+
+	fake := New("octocat")
+	fake.AddRepository("octocat", "hello-world", map[string]string{"README.md": "# Hello\n"})
+	api := httptest.NewServer(fake)
+	t.Cleanup(api.Close)
+
+	restClient, err := github.NewClient(nil).WithAuthToken("ghp_octocat").WithEnterpriseURLs(api.URL, api.URL)
+	require.NoError(t, err)
+	httpClient := &http.Client{Transport: &github.BasicAuthTransport{Username: "octocat", Password: "ghp_octocat"}}
+	return fake, restClient, githubv4.NewEnterpriseClient(api.URL+"/api/graphql", httpClient)
+}
+
+func Test_MergeConflicts(t *testing.T) {
+	fake, client, _ := newClients(t)
+	ctx := context.Background()
+
+	// Both branches change the README, from the same commit
+	for _, branch := range []string{"english", "french"} {
+		main, _, err := client.Git.GetRef(ctx, "octocat", "hello-world", "refs/heads/main")
+		require.NoError(t, err)
+		_, _, err = client.Git.CreateRef(ctx, "octocat", "hello-world", &github.Reference{
+			Ref:    github.Ptr("refs/heads/" + branch),
+			Object: &github.GitObject{SHA: main.Object.SHA},
+		})
+		require.NoError(t, err)
+	}
+	readme, _, _, err := client.Repositories.GetContents(ctx, "octocat", "hello-world", "README.md", nil)
+	require.NoError(t, err)
+	for branch, content := range map[string]string{"english": "# Hello, world\n", "french": "# Bonjour\n"} {
+		_, _, err = client.Repositories.UpdateFile(ctx, "octocat", "hello-world", "README.md", &github.RepositoryContentFileOptions{
+			Message: github.Ptr("Translate"),
+			Content: []byte(content),
+			SHA:     readme.SHA,
+			Branch:  github.Ptr(branch),
+		})
+		require.NoError(t, err)
+	}
+
+	english, _, err := client.PullRequests.Create(ctx, "octocat", "hello-world", &github.NewPullRequest{
+		Title: github.Ptr("English"), Head: github.Ptr("english"), Base: github.Ptr("main"),
+	})
+	require.NoError(t, err)
+	french, _, err := client.PullRequests.Create(ctx, "octocat", "hello-world", &github.NewPullRequest{
+		Title: github.Ptr("French"), Head: github.Ptr("french"), Base: github.Ptr("main"),
+	})
+	require.NoError(t, err)
+	assert.True(t, french.GetMergeable())
+
+	_, _, err = client.PullRequests.Merge(ctx, "octocat", "hello-world", english.GetNumber(), "", nil)
+	require.NoError(t, err)
+	readmeOnMain, ok := fake.File("octocat", "hello-world", "main", "README.md")
+	require.True(t, ok)
+	assert.Equal(t, "# Hello, world\n", readmeOnMain)
+
+	// Once the first pull request is merged, the second one conflicts with main
+	french, _, err = client.PullRequests.Get(ctx, "octocat", "hello-world", french.GetNumber())
+	require.NoError(t, err)
+	assert.False(t, french.GetMergeable())
+	assert.Equal(t, "dirty", french.GetMergeableState())
+	_, resp, err := client.PullRequests.Merge(ctx, "octocat", "hello-world", french.GetNumber(), "", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+func Test_GraphQL(t *testing.T) {
+	_, client, graphQLClient := newClients(t)
+	ctx := context.Background()
+
+	for _, title := range []string{"First", "Second", "Third"} {
+		_, _, err := client.Issues.Create(ctx, "octocat", "hello-world", &github.IssueRequest{Title: github.Ptr(title)})
+		require.NoError(t, err)
+	}
+
+	var query struct {
+		Repository struct {
+			NameWithOwner string
+			Issues        struct {
+				Nodes []struct {
+					Number int
+					Title  string
+				}
+				TotalCount int
+				PageInfo   struct {
+					HasNextPage bool
+					EndCursor   githubv4.String
+				}
+			} `graphql:"issues(first: $first, after: $after, orderBy: {field: CREATED_AT, direction: DESC})"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	variables := map[string]any{
+		"owner": githubv4.String("octocat"),
+		"name":  githubv4.String("hello-world"),
+		"first": githubv4.Int(2),
+		"after": (*githubv4.String)(nil),
+	}
+	require.NoError(t, graphQLClient.Query(ctx, &query, variables))
+	assert.Equal(t, "octocat/hello-world", query.Repository.NameWithOwner)
+	assert.Equal(t, 3, query.Repository.Issues.TotalCount)
+	require.Len(t, query.Repository.Issues.Nodes, 2)
+	assert.Equal(t, "Third", query.Repository.Issues.Nodes[0].Title)
+	assert.True(t, query.Repository.Issues.PageInfo.HasNextPage)
+
+	variables["after"] = githubv4.NewString(query.Repository.Issues.PageInfo.EndCursor)
+	require.NoError(t, graphQLClient.Query(ctx, &query, variables))
+	require.Len(t, query.Repository.Issues.Nodes, 1)
+	assert.Equal(t, 1, query.Repository.Issues.Nodes[0].Number)
+	assert.False(t, query.Repository.Issues.PageInfo.HasNextPage)
+
+	// Fields the fake does not know about fail, rather than being silently empty
+	var unsupported struct {
+		Repository struct {
+			StargazerCount int
+		} `graphql:"repository(owner: \"octocat\", name: \"hello-world\")"`
+	}
+	err := graphQLClient.Query(ctx, &unsupported, nil)
+	assert.ErrorContains(t, err, "Field 'stargazerCount' doesn't exist on type 'Repository'")
+
+	var missing struct {
+		Repository struct {
+			Name string
+		} `graphql:"repository(owner: \"octocat\", name: \"missing\")"`
+	}
+	err = graphQLClient.Query(ctx, &missing, nil)
+	assert.ErrorContains(t, err, "Could not resolve to a Repository with the name 'octocat/missing'.")
+}
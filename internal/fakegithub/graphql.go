@@ -0,0 +1,390 @@
+package fakegithub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+)
+
+// serveGraphQL executes a GraphQL query or mutation. Only the parts of the language the queries built by
+// shurcooL/githubv4 use are supported: fields with arguments and aliases, variables and inline fragments.
+func (s *Server) serveGraphQL(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+
+	operation, selections, err := parseQuery(body.Query)
+	if err != nil {
+		writeGraphQLError(w, err)
+		return
+	}
+	root := s.queryObject(r)
+	if operation == "mutation" {
+		root = s.mutationObject(r)
+	}
+	data, err := execute(root, selections, body.Variables)
+	if err != nil {
+		writeGraphQLError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": data})
+}
+
+// writeGraphQLError writes a response reporting err, with a 200 OK status like GitHub.
+func writeGraphQLError(w http.ResponseWriter, err error) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data":   nil,
+		"errors": []map[string]string{{"message": err.Error()}},
+	})
+}
+
+// object is a GraphQL object, resolving the value of each of its fields from its arguments. Values are scalars,
+// objects, lists of objects or nil.
+type object struct {
+	typename string
+	fields   map[string]resolver
+}
+
+// resolver resolves the value of a field from its arguments.
+type resolver func(args map[string]any) (any, error)
+
+// constant returns a resolver of the value v.
+func constant(v any) resolver {
+	return func(map[string]any) (any, error) { return v, nil }
+}
+
+// selection is a field selected from an object, or an inline fragment if on is set.
+type selection struct {
+	alias      string
+	name       string
+	args       map[string]any
+	selections []selection
+	on         string
+}
+
+// variable is a reference to a variable in an argument.
+type variable string
+
+// execute resolves the selections from obj, with the variables of the query.
+func execute(obj *object, selections []selection, variables map[string]any) (map[string]any, error) {
+	result := make(map[string]any)
+	for _, sel := range selections {
+		if sel.on != "" {
+			if sel.on != obj.typename {
+				continue
+			}
+			fragment, err := execute(obj, sel.selections, variables)
+			if err != nil {
+				return nil, err
+			}
+			for key, value := range fragment {
+				result[key] = value
+			}
+			continue
+		}
+
+		key := sel.name
+		if sel.alias != "" {
+			key = sel.alias
+		}
+		if sel.name == "__typename" {
+			result[key] = obj.typename
+			continue
+		}
+		resolve, ok := obj.fields[sel.name]
+		if !ok {
+			return nil, fmt.Errorf("Field '%s' doesn't exist on type '%s'", sel.name, obj.typename) //nolint:staticcheck // GitHub's message
+		}
+		args, _ := substitute(sel.args, variables).(map[string]any)
+		value, err := resolve(args)
+		if err != nil {
+			return nil, err
+		}
+		if result[key], err = complete(value, sel, variables); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// complete resolves the selections of a field from its value.
+func complete(value any, sel selection, variables map[string]any) (any, error) {
+	switch value := value.(type) {
+	case *object:
+		if value == nil {
+			return nil, nil
+		}
+		if len(sel.selections) == 0 {
+			return nil, fmt.Errorf("Field must have selections (field '%s' returns %s but has no selections.)", sel.name, value.typename) //nolint:staticcheck // GitHub's message
+		}
+		return execute(value, sel.selections, variables)
+	case []*object:
+		list := make([]any, 0, len(value))
+		for _, item := range value {
+			completed, err := complete(item, sel, variables)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, completed)
+		}
+		return list, nil
+	default:
+		return value, nil
+	}
+}
+
+// substitute returns the argument value with the variables it references replaced by their value.
+func substitute(value any, variables map[string]any) any {
+	switch value := value.(type) {
+	case variable:
+		return variables[string(value)]
+	case map[string]any:
+		substituted := make(map[string]any, len(value))
+		for key, v := range value {
+			substituted[key] = substitute(v, variables)
+		}
+		return substituted
+	case []any:
+		substituted := make([]any, len(value))
+		for i, v := range value {
+			substituted[i] = substitute(v, variables)
+		}
+		return substituted
+	default:
+		return value
+	}
+}
+
+// parser parses a GraphQL document holding a single operation.
+type parser struct {
+	query string
+	pos   int
+}
+
+// parseQuery returns the type of the operation of the query, query or mutation, and its selections.
+func parseQuery(query string) (string, []selection, error) {
+	p := &parser{query: query}
+	operation := "query"
+	if name := p.peekName(); name == "query" || name == "mutation" {
+		operation = p.name()
+		p.name() // The operation name is optional
+		if p.peek() == '(' {
+			// Variable definitions are not needed, the variables being typed by their JSON value
+			if err := p.skipBalanced('(', ')'); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+	selections, err := p.selectionSet()
+	if err != nil {
+		return "", nil, err
+	}
+	if p.peek() != 0 {
+		return "", nil, p.errorf("unexpected %q", string(p.peek()))
+	}
+	return operation, selections, nil
+}
+
+// errorf returns a parse error at the current position.
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("Parse error at position %d: %s", p.pos, fmt.Sprintf(format, args...)) //nolint:staticcheck // GitHub's message
+}
+
+// peek skips whitespace and commas, returning the next character without consuming it, or 0 at the end.
+func (p *parser) peek() byte {
+	for p.pos < len(p.query) && (unicode.IsSpace(rune(p.query[p.pos])) || p.query[p.pos] == ',') {
+		p.pos++
+	}
+	if p.pos == len(p.query) {
+		return 0
+	}
+	return p.query[p.pos]
+}
+
+// expect consumes the character c.
+func (p *parser) expect(c byte) error {
+	if p.peek() != c {
+		return p.errorf("expected %q", string(c))
+	}
+	p.pos++
+	return nil
+}
+
+// peekName returns the next name without consuming it, or an empty string if there is none.
+func (p *parser) peekName() string {
+	p.peek()
+	end := p.pos
+	for end < len(p.query) && (p.query[end] == '_' || unicode.IsLetter(rune(p.query[end])) || (end > p.pos && unicode.IsDigit(rune(p.query[end])))) {
+		end++
+	}
+	return p.query[p.pos:end]
+}
+
+// name consumes the next name, returning an empty string if there is none.
+func (p *parser) name() string {
+	name := p.peekName()
+	p.pos += len(name)
+	return name
+}
+
+// skipBalanced consumes the text from the character open to the matching close.
+func (p *parser) skipBalanced(open, close byte) error {
+	depth := 0
+	for ; p.pos < len(p.query); p.pos++ {
+		switch p.query[p.pos] {
+		case open:
+			depth++
+		case close:
+			depth--
+			if depth == 0 {
+				p.pos++
+				return nil
+			}
+		}
+	}
+	return p.errorf("expected %q", string(close))
+}
+
+// selectionSet parses the selections between braces.
+func (p *parser) selectionSet() ([]selection, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	var selections []selection
+	for p.peek() != '}' {
+		if p.peek() == 0 {
+			return nil, p.errorf("expected %q", "}")
+		}
+
+		if strings.HasPrefix(p.query[p.pos:], "...") {
+			p.pos += len("...")
+			if p.name() != "on" {
+				return nil, p.errorf("only inline fragments are supported")
+			}
+			fragment := selection{on: p.name()}
+			var err error
+			if fragment.selections, err = p.selectionSet(); err != nil {
+				return nil, err
+			}
+			selections = append(selections, fragment)
+			continue
+		}
+
+		sel := selection{name: p.name()}
+		if sel.name == "" {
+			return nil, p.errorf("expected a field")
+		}
+		if p.peek() == ':' {
+			p.pos++
+			sel.alias, sel.name = sel.name, p.name()
+		}
+		if p.peek() == '(' {
+			args, err := p.arguments(')')
+			if err != nil {
+				return nil, err
+			}
+			sel.args = args
+		}
+		if p.peek() == '{' {
+			var err error
+			if sel.selections, err = p.selectionSet(); err != nil {
+				return nil, err
+			}
+		}
+		selections = append(selections, sel)
+	}
+	p.pos++
+	return selections, nil
+}
+
+// arguments parses the arguments of a field between parentheses, or the fields of an input object between braces,
+// up to the character end.
+func (p *parser) arguments(end byte) (map[string]any, error) {
+	p.pos++
+	args := make(map[string]any)
+	for p.peek() != end {
+		name := p.name()
+		if name == "" {
+			return nil, p.errorf("expected an argument")
+		}
+		if err := p.expect(':'); err != nil {
+			return nil, err
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		args[name] = value
+	}
+	p.pos++
+	return args, nil
+}
+
+// value parses the value of an argument.
+func (p *parser) value() (any, error) {
+	switch c := p.peek(); {
+	case c == '$':
+		p.pos++
+		return variable(p.name()), nil
+	case c == '{':
+		return p.arguments('}')
+	case c == '[':
+		p.pos++
+		var list []any
+		for p.peek() != ']' {
+			if p.peek() == 0 {
+				return nil, p.errorf("expected %q", "]")
+			}
+			item, err := p.value()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+		p.pos++
+		return list, nil
+	case c == '"':
+		end := p.pos + 1
+		for end < len(p.query) && p.query[end] != '"' {
+			if p.query[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		var s string
+		if end >= len(p.query) || json.Unmarshal([]byte(p.query[p.pos:end+1]), &s) != nil {
+			return nil, p.errorf("invalid string")
+		}
+		p.pos = end + 1
+		return s, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		start := p.pos
+		p.pos++
+		for p.pos < len(p.query) && strings.ContainsRune("0123456789.eE+-", rune(p.query[p.pos])) {
+			p.pos++
+		}
+		var n float64
+		if err := json.Unmarshal([]byte(p.query[start:p.pos]), &n); err != nil {
+			return nil, p.errorf("invalid number")
+		}
+		return n, nil
+	default:
+		switch name := p.name(); name {
+		case "":
+			return nil, p.errorf("expected a value")
+		case "true", "false":
+			return name == "true", nil
+		case "null":
+			return nil, nil
+		default:
+			// Enum values are passed on as strings, like in variables
+			return name, nil
+		}
+	}
+}
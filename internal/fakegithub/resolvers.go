@@ -0,0 +1,463 @@
+package fakegithub
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// queryObject returns the root of queries, for the user authenticated by the request.
+func (s *Server) queryObject(r *http.Request) *object {
+	return &object{typename: "Query", fields: map[string]resolver{
+		"viewer": constant(s.userObject(r, s.viewer(r))),
+		"repository": func(args map[string]any) (any, error) {
+			owner, name := stringArg(args, "owner"), stringArg(args, "name")
+			repo, ok := s.repos[owner+"/"+name]
+			if !ok {
+				return nil, fmt.Errorf("Could not resolve to a Repository with the name '%s/%s'.", owner, name) //nolint:staticcheck // GitHub's message
+			}
+			return s.repositoryObject(r, repo), nil
+		},
+	}}
+}
+
+// mutationObject returns the root of mutations, made as the user authenticated by the request.
+func (s *Server) mutationObject(r *http.Request) *object {
+	viewer := s.viewer(r)
+	return &object{typename: "Mutation", fields: map[string]resolver{
+		"addPullRequestReview": func(args map[string]any) (any, error) {
+			input := inputArg(args)
+			pr, err := s.pullRequestNode(stringArg(input, "pullRequestId"))
+			if err != nil {
+				return nil, err
+			}
+			for _, review := range pr.pull.reviews {
+				if review.author == viewer && review.state == "PENDING" {
+					return nil, errors.New("User can only have one pending review per pull request") //nolint:staticcheck // GitHub's message
+				}
+			}
+			review := &review{pull: pr, id: s.nextID(), author: viewer, body: stringArg(input, "body"), state: "PENDING"}
+			if review.commitID = stringArg(input, "commitOID"); review.commitID == "" {
+				review.commitID = pr.headSHA()
+			}
+			if event := stringArg(input, "event"); event != "" {
+				if err := s.submitReview(review, event); err != nil {
+					return nil, err
+				}
+			}
+			pr.pull.reviews = append(pr.pull.reviews, review)
+			s.nodes[review.nodeID()] = review
+			return &object{typename: "AddPullRequestReviewPayload", fields: map[string]resolver{
+				"pullRequestReview": constant(s.reviewObject(r, review)),
+			}}, nil
+		},
+		"addPullRequestReviewThread": func(args map[string]any) (any, error) {
+			input := inputArg(args)
+			review, err := s.pendingReviewNode(stringArg(input, "pullRequestReviewId"), viewer)
+			if err != nil {
+				return nil, err
+			}
+			path := stringArg(input, "path")
+			if !changesPath(review.pull, path) {
+				return nil, errors.New("Path could not be resolved") //nolint:staticcheck // GitHub's message
+			}
+			c := &reviewComment{
+				id:          s.nextID(),
+				path:        path,
+				body:        stringArg(input, "body"),
+				subjectType: stringArg(input, "subjectType"),
+				line:        intArg(input, "line"),
+				side:        stringArg(input, "side"),
+				startLine:   intArg(input, "startLine"),
+				startSide:   stringArg(input, "startSide"),
+				createdAt:   s.now(),
+			}
+			if c.subjectType == "" {
+				c.subjectType = "LINE"
+			}
+			if c.line > 0 && c.side == "" {
+				c.side = "RIGHT"
+			}
+			review.comments = append(review.comments, c)
+			return &object{typename: "AddPullRequestReviewThreadPayload", fields: map[string]resolver{
+				"thread": constant(&object{typename: "PullRequestReviewThread", fields: map[string]resolver{
+					"id":         constant(fmt.Sprintf("PRRT_%d", c.id)),
+					"path":       constant(c.path),
+					"isResolved": constant(false),
+				}}),
+			}}, nil
+		},
+		"submitPullRequestReview": func(args map[string]any) (any, error) {
+			input := inputArg(args)
+			review, err := s.pendingReviewNode(stringArg(input, "pullRequestReviewId"), viewer)
+			if err != nil {
+				return nil, err
+			}
+			if body, ok := input["body"].(string); ok {
+				review.body = body
+			}
+			if err := s.submitReview(review, stringArg(input, "event")); err != nil {
+				return nil, err
+			}
+			return &object{typename: "SubmitPullRequestReviewPayload", fields: map[string]resolver{
+				"pullRequestReview": constant(s.reviewObject(r, review)),
+			}}, nil
+		},
+		"deletePullRequestReview": func(args map[string]any) (any, error) {
+			review, err := s.pendingReviewNode(stringArg(inputArg(args), "pullRequestReviewId"), viewer)
+			if err != nil {
+				return nil, err
+			}
+			reviews := review.pull.pull.reviews
+			for i := range reviews {
+				if reviews[i] == review {
+					review.pull.pull.reviews = append(reviews[:i:i], reviews[i+1:]...)
+					break
+				}
+			}
+			delete(s.nodes, review.nodeID())
+			return &object{typename: "DeletePullRequestReviewPayload", fields: map[string]resolver{
+				"pullRequestReview": constant(s.reviewObject(r, review)),
+			}}, nil
+		},
+		"closeIssue": func(args map[string]any) (any, error) {
+			input := inputArg(args)
+			issue, err := s.issueNode(stringArg(input, "issueId"))
+			if err != nil {
+				return nil, err
+			}
+			if duplicate := stringArg(input, "duplicateIssueId"); duplicate != "" {
+				if _, err := s.issueNode(duplicate); err != nil {
+					return nil, err
+				}
+			}
+			s.setIssueState(issue, "closed", strings.ToLower(stringArg(input, "stateReason")))
+			return &object{typename: "CloseIssuePayload", fields: map[string]resolver{
+				"issue": constant(s.issueObject(r, issue)),
+			}}, nil
+		},
+		"reopenIssue": func(args map[string]any) (any, error) {
+			issue, err := s.issueNode(stringArg(inputArg(args), "issueId"))
+			if err != nil {
+				return nil, err
+			}
+			s.setIssueState(issue, "open", "")
+			return &object{typename: "ReopenIssuePayload", fields: map[string]resolver{
+				"issue": constant(s.issueObject(r, issue)),
+			}}, nil
+		},
+	}}
+}
+
+// submitReview submits a pending review with the event APPROVE, REQUEST_CHANGES or COMMENT. Authors cannot approve
+// or request changes on their own pull requests.
+func (s *Server) submitReview(review *review, event string) error {
+	states := map[string]string{"APPROVE": "APPROVED", "REQUEST_CHANGES": "CHANGES_REQUESTED", "COMMENT": "COMMENTED"}
+	state, ok := states[event]
+	switch {
+	case !ok:
+		return fmt.Errorf("Argument 'event' on InputObject has an invalid value (%s).", event) //nolint:staticcheck // GitHub's message
+	case review.author == review.pull.author && event == "APPROVE":
+		return errors.New("Can not approve your own pull request") //nolint:staticcheck // GitHub's message
+	case review.author == review.pull.author && event == "REQUEST_CHANGES":
+		return errors.New("Can not request changes on your own pull request") //nolint:staticcheck // GitHub's message
+	}
+	review.state, review.submittedAt = state, s.now()
+	return nil
+}
+
+// changesPath reports whether the pull request changes the file at path.
+func changesPath(pr *issue, path string) bool {
+	for _, change := range pr.repo.changedFiles(pr) {
+		if change.path == path {
+			return true
+		}
+	}
+	return false
+}
+
+// issueNode returns the issue or pull request with the node ID.
+func (s *Server) issueNode(id string) (*issue, error) {
+	if issue, ok := s.nodes[id].(*issue); ok {
+		return issue, nil
+	}
+	return nil, fmt.Errorf("Could not resolve to a node with the global id of '%s'", id) //nolint:staticcheck // GitHub's message
+}
+
+// pullRequestNode returns the pull request with the node ID.
+func (s *Server) pullRequestNode(id string) (*issue, error) {
+	if pr, err := s.issueNode(id); err == nil && pr.pull != nil {
+		return pr, nil
+	}
+	return nil, fmt.Errorf("Could not resolve to a PullRequest with the global id of '%s'", id) //nolint:staticcheck // GitHub's message
+}
+
+// pendingReviewNode returns the pending review of viewer with the node ID.
+func (s *Server) pendingReviewNode(id, viewer string) (*review, error) {
+	review, ok := s.nodes[id].(*review)
+	if !ok || review.author != viewer {
+		return nil, fmt.Errorf("Could not resolve to a PullRequestReview with the global id of '%s'", id) //nolint:staticcheck // GitHub's message
+	}
+	if review.state != "PENDING" {
+		return nil, errors.New("Review has already been submitted") //nolint:staticcheck // GitHub's message
+	}
+	return review, nil
+}
+
+func (s *Server) userObject(r *http.Request, login string) *object {
+	id := s.userID(login)
+	return &object{typename: "User", fields: map[string]resolver{
+		"id":         constant(fmt.Sprintf("U_%d", id)),
+		"databaseId": constant(id),
+		"login":      constant(login),
+		"url":        constant(webURL(r) + "/" + login),
+	}}
+}
+
+func (s *Server) repositoryObject(r *http.Request, repo *repository) *object {
+	return &object{typename: "Repository", fields: map[string]resolver{
+		"id":            constant(fmt.Sprintf("R_%d", repo.id)),
+		"databaseId":    constant(repo.id),
+		"name":          constant(repo.name),
+		"nameWithOwner": constant(repo.owner + "/" + repo.name),
+		"owner":         constant(s.userObject(r, repo.owner)),
+		"isPrivate":     constant(repo.private),
+		"url":           constant(htmlURL(r, repo)),
+		"pullRequest": func(args map[string]any) (any, error) {
+			number := intArg(args, "number")
+			if number < 1 || number > len(repo.issues) || repo.issues[number-1].pull == nil {
+				return nil, fmt.Errorf("Could not resolve to a PullRequest with the number of %d.", number) //nolint:staticcheck // GitHub's message
+			}
+			return s.pullRequestObject(r, repo.issues[number-1]), nil
+		},
+		"issue": func(args map[string]any) (any, error) {
+			number := intArg(args, "number")
+			if number < 1 || number > len(repo.issues) || repo.issues[number-1].pull != nil {
+				return nil, fmt.Errorf("Could not resolve to an Issue with the number of %d.", number) //nolint:staticcheck // GitHub's message
+			}
+			return s.issueObject(r, repo.issues[number-1]), nil
+		},
+		"issues": func(args map[string]any) (any, error) {
+			var issues []*issue
+			states, labels := stringsArg(args, "states"), stringsArg(args, "labels")
+			since, _ := time.Parse(time.RFC3339, stringArg(mapArg(args, "filterBy"), "since"))
+			for _, issue := range repo.issues {
+				if issue.pull == nil && (len(states) == 0 || slices.Contains(states, strings.ToUpper(issue.state))) &&
+					hasLabels(issue, labels) && !issue.updatedAt.Before(since) {
+					issues = append(issues, issue)
+				}
+			}
+			if err := sortIssues(issues, mapArg(args, "orderBy")); err != nil {
+				return nil, err
+			}
+			nodes := make([]*object, 0, len(issues))
+			for _, issue := range issues {
+				nodes = append(nodes, s.issueObject(r, issue))
+			}
+			return connection("IssueConnection", nodes, args), nil
+		},
+	}}
+}
+
+// sortIssues sorts issues by the field and in the direction of orderBy, by creation date ascending if nil.
+func sortIssues(issues []*issue, orderBy map[string]any) error {
+	keys := map[string]func(*issue) int64{
+		"":           func(i *issue) int64 { return i.createdAt.UnixNano() },
+		"CREATED_AT": func(i *issue) int64 { return i.createdAt.UnixNano() },
+		"UPDATED_AT": func(i *issue) int64 { return i.updatedAt.UnixNano() },
+		"COMMENTS":   func(i *issue) int64 { return int64(len(i.comments)) },
+	}
+	key, ok := keys[stringArg(orderBy, "field")]
+	if !ok {
+		return fmt.Errorf("Argument 'field' on InputObject 'IssueOrder' has an invalid value (%s).", stringArg(orderBy, "field")) //nolint:staticcheck // GitHub's message
+	}
+	descending := stringArg(orderBy, "direction") == "DESC"
+	sort.SliceStable(issues, func(i, j int) bool {
+		if key(issues[i]) == key(issues[j]) {
+			return (issues[i].number < issues[j].number) != descending
+		}
+		return (key(issues[i]) < key(issues[j])) != descending
+	})
+	return nil
+}
+
+func (s *Server) issueObject(r *http.Request, i *issue) *object {
+	labels := make([]*object, 0, len(i.labels))
+	for _, name := range i.labels {
+		labels = append(labels, &object{typename: "Label", fields: map[string]resolver{
+			"id":          constant("LA_" + base64.RawURLEncoding.EncodeToString([]byte(name))),
+			"name":        constant(name),
+			"description": constant(""),
+		}})
+	}
+	comments := make([]*object, 0, len(i.comments))
+	for _, c := range i.comments {
+		comments = append(comments, &object{typename: "IssueComment", fields: map[string]resolver{
+			"id":         constant(fmt.Sprintf("IC_%d", c.id)),
+			"databaseId": constant(c.id),
+			"body":       constant(c.body),
+			"author":     constant(s.userObject(r, c.author)),
+			"createdAt":  constant(formatTime(c.createdAt)),
+		}})
+	}
+	assignees := make([]*object, 0, len(i.assignees))
+	for _, login := range i.assignees {
+		assignees = append(assignees, s.userObject(r, login))
+	}
+
+	var stateReason any
+	if i.stateReason != "" {
+		stateReason = strings.ToUpper(i.stateReason)
+	}
+	var closedAt any
+	if !i.closedAt.IsZero() {
+		closedAt = formatTime(i.closedAt)
+	}
+	return &object{typename: "Issue", fields: map[string]resolver{
+		"id":          constant(i.nodeID()),
+		"databaseId":  constant(i.id),
+		"number":      constant(i.number),
+		"title":       constant(i.title),
+		"body":        constant(i.body),
+		"state":       constant(strings.ToUpper(i.state)),
+		"stateReason": constant(stateReason),
+		"url":         constant(htmlURL(r, i.repo, "issues", strconv.Itoa(i.number))),
+		"author":      constant(s.userObject(r, i.author)),
+		"createdAt":   constant(formatTime(i.createdAt)),
+		"updatedAt":   constant(formatTime(i.updatedAt)),
+		"closedAt":    constant(closedAt),
+		"labels":      func(args map[string]any) (any, error) { return connection("LabelConnection", labels, args), nil },
+		"comments": func(args map[string]any) (any, error) {
+			return connection("IssueCommentConnection", comments, args), nil
+		},
+		"assignees": func(args map[string]any) (any, error) { return connection("UserConnection", assignees, args), nil },
+	}}
+}
+
+func (s *Server) pullRequestObject(r *http.Request, pr *issue) *object {
+	state := strings.ToUpper(pr.state)
+	if pr.pull.merged {
+		state = "MERGED"
+	}
+	return &object{typename: "PullRequest", fields: map[string]resolver{
+		"id":          constant(pr.nodeID()),
+		"databaseId":  constant(pr.id),
+		"number":      constant(pr.number),
+		"title":       constant(pr.title),
+		"body":        constant(pr.body),
+		"state":       constant(state),
+		"isDraft":     constant(pr.pull.draft),
+		"merged":      constant(pr.pull.merged),
+		"url":         constant(htmlURL(r, pr.repo, "pull", strconv.Itoa(pr.number))),
+		"author":      constant(s.userObject(r, pr.author)),
+		"createdAt":   constant(formatTime(pr.createdAt)),
+		"updatedAt":   constant(formatTime(pr.updatedAt)),
+		"headRefName": constant(pr.pull.head),
+		"headRefOid":  constant(pr.headSHA()),
+		"baseRefName": constant(pr.pull.base),
+		"baseRefOid":  constant(pr.baseSHA()),
+		"reviews": func(args map[string]any) (any, error) {
+			author, states := stringArg(args, "author"), stringsArg(args, "states")
+			var nodes []*object
+			for _, review := range pr.visibleReviews(s.viewer(r)) {
+				if (author == "" || review.author == author) && (len(states) == 0 || slices.Contains(states, review.state)) {
+					nodes = append(nodes, s.reviewObject(r, review))
+				}
+			}
+			return connection("PullRequestReviewConnection", nodes, args), nil
+		},
+	}}
+}
+
+func (s *Server) reviewObject(r *http.Request, review *review) *object {
+	var submittedAt any
+	if !review.submittedAt.IsZero() {
+		submittedAt = formatTime(review.submittedAt)
+	}
+	return &object{typename: "PullRequestReview", fields: map[string]resolver{
+		"id":          constant(review.nodeID()),
+		"databaseId":  constant(review.id),
+		"state":       constant(review.state),
+		"body":        constant(review.body),
+		"url":         constant(review.url(webURL(r))),
+		"author":      constant(s.userObject(r, review.author)),
+		"submittedAt": constant(submittedAt),
+		"commit":      constant(&object{typename: "Commit", fields: map[string]resolver{"oid": constant(review.commitID)}}),
+	}}
+}
+
+// connection returns the page of nodes asked for by the first and after arguments, as a connection of typename.
+func connection(typename string, nodes []*object, args map[string]any) *object {
+	start := 0
+	if after, err := base64.StdEncoding.DecodeString(stringArg(args, "after")); err == nil {
+		if n, err := strconv.Atoi(strings.TrimPrefix(string(after), "cursor:")); err == nil {
+			start = min(n, len(nodes))
+		}
+	}
+	end := len(nodes)
+	if first := intArg(args, "first"); first > 0 {
+		end = min(start+first, len(nodes))
+	}
+	cursor := func(n int) string { return base64.StdEncoding.EncodeToString([]byte("cursor:" + strconv.Itoa(n))) }
+
+	var startCursor, endCursor any
+	if end > start {
+		startCursor, endCursor = cursor(start+1), cursor(end)
+	}
+	return &object{typename: typename, fields: map[string]resolver{
+		"nodes":      constant(nodes[start:end]),
+		"totalCount": constant(len(nodes)),
+		"pageInfo": constant(&object{typename: "PageInfo", fields: map[string]resolver{
+			"hasNextPage":     constant(end < len(nodes)),
+			"hasPreviousPage": constant(start > 0),
+			"startCursor":     constant(startCursor),
+			"endCursor":       constant(endCursor),
+		}}),
+	}}
+}
+
+// formatTime formats t like GitHub's DateTime scalars.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+// stringArg returns the string argument name, or an empty string if it is missing.
+func stringArg(args map[string]any, name string) string {
+	s, _ := args[name].(string)
+	return s
+}
+
+// intArg returns the integer argument name, or 0 if it is missing.
+func intArg(args map[string]any, name string) int {
+	n, _ := args[name].(float64)
+	return int(n)
+}
+
+// stringsArg returns the list of strings argument name.
+func stringsArg(args map[string]any, name string) []string {
+	list, _ := args[name].([]any)
+	var strs []string
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			strs = append(strs, s)
+		}
+	}
+	return strs
+}
+
+// mapArg returns the input object argument name.
+func mapArg(args map[string]any, name string) map[string]any {
+	m, _ := args[name].(map[string]any)
+	return m
+}
+
+// inputArg returns the input argument of a mutation.
+func inputArg(args map[string]any) map[string]any {
+	return mapArg(args, "input")
+}
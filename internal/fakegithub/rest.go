@@ -0,0 +1,1481 @@
+package fakegithub
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/go-github/v74/github"
+)
+
+// restPrefix is the path the REST API is served under.
+const restPrefix = "/api/v3"
+
+// routeREST registers the handlers of the REST API.
+func (s *Server) routeREST() {
+	routes := map[string]http.HandlerFunc{
+		"GET /user":              s.getUser,
+		"POST /user/repos":       s.createRepository,
+		"POST /orgs/{org}/repos": s.createRepository,
+
+		"GET /repos/{owner}/{repo}":                    s.getRepository,
+		"GET /repos/{owner}/{repo}/branches":           s.listBranches,
+		"GET /repos/{owner}/{repo}/tags":               s.listTags,
+		"GET /repos/{owner}/{repo}/commits":            s.listCommits,
+		"GET /repos/{owner}/{repo}/commits/{ref...}":   s.getCommit,
+		"GET /repos/{owner}/{repo}/contents/{path...}": s.getContents,
+		"PUT /repos/{owner}/{repo}/contents/{path...}": s.putContents,
+
+		"GET /repos/{owner}/{repo}/git/ref/{ref...}":     s.getRef,
+		"POST /repos/{owner}/{repo}/git/refs":            s.createRef,
+		"PATCH /repos/{owner}/{repo}/git/refs/{ref...}":  s.updateRef,
+		"DELETE /repos/{owner}/{repo}/git/refs/{ref...}": s.deleteRef,
+		"POST /repos/{owner}/{repo}/git/blobs":           s.createBlob,
+		"GET /repos/{owner}/{repo}/git/blobs/{sha}":      s.getBlob,
+		"POST /repos/{owner}/{repo}/git/trees":           s.createTree,
+		"GET /repos/{owner}/{repo}/git/trees/{sha...}":   s.getTree,
+		"POST /repos/{owner}/{repo}/git/commits":         s.createGitCommit,
+		"GET /repos/{owner}/{repo}/git/commits/{sha}":    s.getGitCommit,
+
+		"GET /repos/{owner}/{repo}/issues":                    s.listIssues,
+		"POST /repos/{owner}/{repo}/issues":                   s.createIssue,
+		"GET /repos/{owner}/{repo}/issues/{number}":           s.getIssue,
+		"PATCH /repos/{owner}/{repo}/issues/{number}":         s.editIssue,
+		"GET /repos/{owner}/{repo}/issues/{number}/comments":  s.listIssueComments,
+		"POST /repos/{owner}/{repo}/issues/{number}/comments": s.createIssueComment,
+
+		"GET /repos/{owner}/{repo}/pulls":                   s.listPullRequests,
+		"POST /repos/{owner}/{repo}/pulls":                  s.createPullRequest,
+		"GET /repos/{owner}/{repo}/pulls/{number}":          s.getPullRequest,
+		"PATCH /repos/{owner}/{repo}/pulls/{number}":        s.editPullRequest,
+		"GET /repos/{owner}/{repo}/pulls/{number}/files":    s.listPullRequestFiles,
+		"PUT /repos/{owner}/{repo}/pulls/{number}/merge":    s.mergePullRequest,
+		"GET /repos/{owner}/{repo}/pulls/{number}/reviews":  s.listReviews,
+		"GET /repos/{owner}/{repo}/pulls/{number}/comments": s.listReviewComments,
+	}
+	for pattern, handler := range routes {
+		method, path, _ := strings.Cut(pattern, " ")
+		s.mux.HandleFunc(method+" "+restPrefix+path, handler)
+	}
+}
+
+// writeJSON writes v as the JSON body of a response with the status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response with the status code, shaped like GitHub's.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{
+		"message":           message,
+		"documentation_url": "https://docs.github.com/rest",
+	})
+}
+
+// writeValidationError writes a 422 Validation Failed response, for a field of a resource.
+func writeValidationError(w http.ResponseWriter, resource, field, message string) {
+	writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+		"message":           "Validation Failed",
+		"errors":            []map[string]string{{"resource": resource, "field": field, "code": "custom", "message": message}},
+		"documentation_url": "https://docs.github.com/rest",
+	})
+}
+
+// decode decodes the JSON body of the request into v, writing an error response if it is invalid.
+func decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+		return false
+	}
+	return true
+}
+
+// webURL returns the URL the fake is served at.
+func webURL(r *http.Request) string {
+	if r.TLS != nil {
+		return "https://" + r.Host
+	}
+	return "http://" + r.Host
+}
+
+// queryInt returns the integer query parameter name of the request, or fallback if it is missing or invalid.
+func queryInt(r *http.Request, name string, fallback int) int {
+	n, err := strconv.Atoi(r.URL.Query().Get(name))
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+// paginate returns the page of items asked for with the page and per_page query parameters, setting the Link
+// header to the other pages like GitHub does.
+func paginate[T any](w http.ResponseWriter, r *http.Request, items []T) []T {
+	perPage := min(max(queryInt(r, "per_page", 30), 1), 100)
+	page := max(queryInt(r, "page", 1), 1)
+	last := max((len(items)+perPage-1)/perPage, 1)
+
+	link := func(page int, rel string) string {
+		u := *r.URL
+		query := u.Query()
+		query.Set("page", strconv.Itoa(page))
+		u.RawQuery = query.Encode()
+		return fmt.Sprintf(`<%s%s>; rel="%s"`, webURL(r), u.RequestURI(), rel)
+	}
+	var links []string
+	if page < last {
+		links = append(links, link(page+1, "next"), link(last, "last"))
+	}
+	if page > 1 {
+		links = append(links, link(1, "first"), link(min(page-1, last), "prev"))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+
+	start := min((page-1)*perPage, len(items))
+	return append([]T{}, items[start:min(start+perPage, len(items))]...)
+}
+
+// repository returns the repository named by the request path, writing a 404 response if there is none.
+func (s *Server) repository(w http.ResponseWriter, r *http.Request) (*repository, bool) {
+	repo, ok := s.repos[r.PathValue("owner")+"/"+r.PathValue("repo")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+	}
+	return repo, ok
+}
+
+// issue returns the issue, or the pull request if pull is set, numbered by the request path, writing a 404
+// response if there is none. Pull requests are issues too.
+func (s *Server) issue(w http.ResponseWriter, r *http.Request, pull bool) (*issue, bool) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return nil, false
+	}
+	number, err := strconv.Atoi(r.PathValue("number"))
+	if err != nil || number < 1 || number > len(repo.issues) || (pull && repo.issues[number-1].pull == nil) {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return nil, false
+	}
+	return repo.issues[number-1], true
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.userJSON(r, s.viewer(r)))
+}
+
+func (s *Server) createRepository(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Private     bool   `json:"private"`
+		AutoInit    bool   `json:"auto_init"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	owner := r.PathValue("org")
+	if owner == "" {
+		owner = s.viewer(r)
+	}
+	if body.Name == "" {
+		writeValidationError(w, "Repository", "name", "name is missing")
+		return
+	}
+	if _, ok := s.repos[owner+"/"+body.Name]; ok {
+		writeValidationError(w, "Repository", "name", "name already exists on this account")
+		return
+	}
+
+	repo := s.newRepository(owner, body.Name, body.Description)
+	repo.private = body.Private
+	if body.AutoInit {
+		readme := "# " + body.Name + "\n"
+		if body.Description != "" {
+			readme += body.Description + "\n"
+		}
+		tree := repo.writeTree(map[string]string{"README.md": repo.writeBlob([]byte(readme))})
+		repo.refs["refs/heads/main"] = repo.writeCommit(tree, nil, "Initial commit", s.viewer(r), s.now())
+	}
+	writeJSON(w, http.StatusCreated, s.repositoryJSON(r, repo))
+}
+
+func (s *Server) getRepository(w http.ResponseWriter, r *http.Request) {
+	if repo, ok := s.repository(w, r); ok {
+		writeJSON(w, http.StatusOK, s.repositoryJSON(r, repo))
+	}
+}
+
+func (s *Server) listBranches(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var branches []*github.Branch
+	for _, name := range refNames(repo, "refs/heads/") {
+		sha := repo.refs["refs/heads/"+name]
+		branches = append(branches, &github.Branch{
+			Name:      github.Ptr(name),
+			Commit:    &github.RepositoryCommit{SHA: github.Ptr(sha), URL: github.Ptr(apiURL(r, repo, "commits", sha))},
+			Protected: github.Ptr(false),
+		})
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, branches))
+}
+
+func (s *Server) listTags(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var tags []*github.RepositoryTag
+	for _, name := range refNames(repo, "refs/tags/") {
+		sha := repo.refs["refs/tags/"+name]
+		tags = append(tags, &github.RepositoryTag{
+			Name:   github.Ptr(name),
+			Commit: &github.Commit{SHA: github.Ptr(sha), URL: github.Ptr(apiURL(r, repo, "commits", sha))},
+		})
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, tags))
+}
+
+func (s *Server) listCommits(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	if len(repo.refs) == 0 {
+		writeError(w, http.StatusConflict, "Git Repository is empty.")
+		return
+	}
+	ref := r.URL.Query().Get("sha")
+	sha, ok := repo.resolve(ref)
+	if !ok {
+		writeError(w, http.StatusNotFound, "No commit found for SHA: "+ref)
+		return
+	}
+	author := r.URL.Query().Get("author")
+	commits := []*github.RepositoryCommit{}
+	for _, c := range repo.history(sha) {
+		if author == "" || c.author == author {
+			commits = append(commits, s.repositoryCommitJSON(r, repo, c, false))
+		}
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, commits))
+}
+
+func (s *Server) getCommit(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	sha, ok := repo.resolve(r.PathValue("ref"))
+	if !ok {
+		writeError(w, http.StatusUnprocessableEntity, "No commit found for SHA: "+r.PathValue("ref"))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.repositoryCommitJSON(r, repo, repo.commits[sha], true))
+}
+
+func (s *Server) getContents(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	if len(repo.refs) == 0 {
+		writeError(w, http.StatusNotFound, "This repository is empty.")
+		return
+	}
+	ref := r.URL.Query().Get("ref")
+	sha, ok := repo.resolve(ref)
+	if !ok {
+		writeError(w, http.StatusNotFound, "No commit found for the ref "+ref)
+		return
+	}
+	files := repo.trees[repo.commits[sha].tree]
+	path := strings.Trim(r.PathValue("path"), "/")
+
+	if blob, ok := files[path]; ok {
+		content := fileContentJSON(r, repo, path, blob, ref)
+		content.Encoding = github.Ptr("base64")
+		content.Content = github.Ptr(base64.StdEncoding.EncodeToString(repo.blobs[blob]))
+		writeJSON(w, http.StatusOK, content)
+		return
+	}
+
+	entries := listDirectory(repo, files, path)
+	if len(entries) == 0 {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	contents := make([]*github.RepositoryContent, 0, len(entries))
+	for _, entry := range entries {
+		if entry.isDir {
+			contents = append(contents, &github.RepositoryContent{
+				Type: github.Ptr("dir"),
+				Name: github.Ptr(entry.name),
+				Path: github.Ptr(entry.path),
+				SHA:  github.Ptr(entry.sha),
+				Size: github.Ptr(0),
+				URL:  github.Ptr(apiURL(r, repo, "contents", entry.path)),
+			})
+			continue
+		}
+		contents = append(contents, fileContentJSON(r, repo, entry.path, entry.sha, ref))
+	}
+	writeJSON(w, http.StatusOK, contents)
+}
+
+func (s *Server) putContents(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body github.RepositoryContentFileOptions
+	if !decode(w, r, &body) {
+		return
+	}
+	path := strings.Trim(r.PathValue("path"), "/")
+	if body.GetMessage() == "" {
+		writeValidationError(w, "Contents", "message", "message is missing")
+		return
+	}
+
+	branch := body.GetBranch()
+	if branch == "" {
+		branch = repo.defaultBranch
+	}
+	files := map[string]string{}
+	var parents []string
+	if sha, ok := repo.refs["refs/heads/"+branch]; ok {
+		files = repo.trees[repo.commits[sha].tree]
+		parents = []string{sha}
+	} else if len(repo.refs) > 0 {
+		writeError(w, http.StatusNotFound, "Branch "+branch+" not found")
+		return
+	}
+
+	current, exists := files[path]
+	switch {
+	case exists && body.SHA == nil:
+		writeError(w, http.StatusUnprocessableEntity, "Invalid request.\n\n\"sha\" wasn't supplied.")
+		return
+	case exists && body.GetSHA() != current:
+		writeError(w, http.StatusConflict, fmt.Sprintf("%s does not match %s", path, body.GetSHA()))
+		return
+	}
+
+	updated := make(map[string]string, len(files)+1)
+	for p, blob := range files {
+		updated[p] = blob
+	}
+	blob := repo.writeBlob(body.Content)
+	updated[path] = blob
+	sha := repo.writeCommit(repo.writeTree(updated), parents, body.GetMessage(), s.viewer(r), s.now())
+	repo.refs["refs/heads/"+branch] = sha
+
+	status := http.StatusCreated
+	if exists {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, &github.RepositoryContentResponse{
+		Content: fileContentJSON(r, repo, path, blob, branch),
+		Commit:  *gitCommitJSON(r, repo, repo.commits[sha]),
+	})
+}
+
+func (s *Server) getRef(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	name := "refs/" + r.PathValue("ref")
+	sha, ok := repo.refs[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, referenceJSON(r, repo, name, sha))
+}
+
+func (s *Server) createRef(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	switch {
+	case !strings.HasPrefix(body.Ref, "refs/") || strings.Count(body.Ref, "/") < 2:
+		writeError(w, http.StatusUnprocessableEntity, "Reference name is not valid")
+	case repo.commits[body.SHA] == nil:
+		writeError(w, http.StatusUnprocessableEntity, "Object does not exist")
+	case repo.refs[body.Ref] != "":
+		writeError(w, http.StatusUnprocessableEntity, "Reference already exists")
+	default:
+		repo.refs[body.Ref] = body.SHA
+		writeJSON(w, http.StatusCreated, referenceJSON(r, repo, body.Ref, body.SHA))
+	}
+}
+
+func (s *Server) updateRef(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		SHA   string `json:"sha"`
+		Force bool   `json:"force"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	name := "refs/" + r.PathValue("ref")
+	current, ok := repo.refs[name]
+	switch {
+	case !ok:
+		writeError(w, http.StatusUnprocessableEntity, "Reference does not exist")
+	case repo.commits[body.SHA] == nil:
+		writeError(w, http.StatusUnprocessableEntity, "Object does not exist")
+	case !body.Force && !repo.isAncestor(current, body.SHA):
+		writeError(w, http.StatusUnprocessableEntity, "Update is not a fast forward")
+	default:
+		repo.refs[name] = body.SHA
+		writeJSON(w, http.StatusOK, referenceJSON(r, repo, name, body.SHA))
+	}
+}
+
+func (s *Server) deleteRef(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	name := "refs/" + r.PathValue("ref")
+	if _, ok := repo.refs[name]; !ok {
+		writeError(w, http.StatusUnprocessableEntity, "Reference does not exist")
+		return
+	}
+	delete(repo.refs, name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) createBlob(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	content := []byte(body.Content)
+	if body.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(body.Content)
+		if err != nil {
+			writeError(w, http.StatusUnprocessableEntity, "Invalid base64 content")
+			return
+		}
+		content = decoded
+	}
+	sha := repo.writeBlob(content)
+	writeJSON(w, http.StatusCreated, &github.Blob{SHA: github.Ptr(sha), URL: github.Ptr(apiURL(r, repo, "git/blobs", sha))})
+}
+
+func (s *Server) getBlob(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	sha := r.PathValue("sha")
+	content, ok := repo.blobs[sha]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, &github.Blob{
+		SHA:      github.Ptr(sha),
+		Content:  github.Ptr(base64.StdEncoding.EncodeToString(content)),
+		Encoding: github.Ptr("base64"),
+		Size:     github.Ptr(len(content)),
+		URL:      github.Ptr(apiURL(r, repo, "git/blobs", sha)),
+	})
+}
+
+func (s *Server) createTree(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		BaseTree string `json:"base_tree"`
+		Tree     []struct {
+			Path    string          `json:"path"`
+			Type    string          `json:"type"`
+			SHA     json.RawMessage `json:"sha"`
+			Content *string         `json:"content"`
+		} `json:"tree"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+
+	files := make(map[string]string)
+	if body.BaseTree != "" {
+		base, ok := repo.trees[body.BaseTree]
+		if !ok {
+			writeValidationError(w, "Tree", "base_tree", "base_tree is not a valid tree")
+			return
+		}
+		for path, blob := range base {
+			files[path] = blob
+		}
+	}
+	for _, entry := range body.Tree {
+		var sha *string
+		if err := json.Unmarshal(entry.SHA, &sha); err != nil && len(entry.SHA) > 0 {
+			writeError(w, http.StatusBadRequest, "Problems parsing JSON")
+			return
+		}
+		switch {
+		case entry.Content != nil:
+			files[entry.Path] = repo.writeBlob([]byte(*entry.Content))
+		case sha == nil:
+			// A null SHA deletes the file, or the directory, at the path
+			delete(files, entry.Path)
+			for path := range files {
+				if strings.HasPrefix(path, entry.Path+"/") {
+					delete(files, path)
+				}
+			}
+		case repo.blobs[*sha] != nil && entry.Type != "tree":
+			files[entry.Path] = *sha
+		case repo.trees[*sha] != nil && entry.Type == "tree":
+			for path, blob := range repo.trees[*sha] {
+				files[entry.Path+"/"+path] = blob
+			}
+		default:
+			writeValidationError(w, "Tree", "tree.sha", fmt.Sprintf("tree.sha %s is not a valid %s", *sha, entry.Type))
+			return
+		}
+	}
+	writeJSON(w, http.StatusCreated, treeJSON(r, repo, repo.writeTree(files), false))
+}
+
+func (s *Server) getTree(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	sha := r.PathValue("sha")
+	if _, ok := repo.trees[sha]; !ok {
+		commit, ok := repo.resolve(sha)
+		if !ok {
+			writeError(w, http.StatusNotFound, "Not Found")
+			return
+		}
+		sha = repo.commits[commit].tree
+	}
+	writeJSON(w, http.StatusOK, treeJSON(r, repo, sha, r.URL.Query().Get("recursive") != ""))
+}
+
+func (s *Server) createGitCommit(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Message string   `json:"message"`
+		Tree    string   `json:"tree"`
+		Parents []string `json:"parents"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	if _, ok := repo.trees[body.Tree]; !ok {
+		writeValidationError(w, "Commit", "tree", "Tree SHA does not exist")
+		return
+	}
+	for _, parent := range body.Parents {
+		if repo.commits[parent] == nil {
+			writeValidationError(w, "Commit", "parents", "Parent SHA does not exist or is not a commit object")
+			return
+		}
+	}
+	sha := repo.writeCommit(body.Tree, body.Parents, body.Message, s.viewer(r), s.now())
+	writeJSON(w, http.StatusCreated, gitCommitJSON(r, repo, repo.commits[sha]))
+}
+
+func (s *Server) getGitCommit(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	c, ok := repo.commits[r.PathValue("sha")]
+	if !ok {
+		writeError(w, http.StatusNotFound, "Not Found")
+		return
+	}
+	writeJSON(w, http.StatusOK, gitCommitJSON(r, repo, c))
+}
+
+func (s *Server) listIssues(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	state := r.URL.Query().Get("state")
+	var labels []string
+	if l := r.URL.Query().Get("labels"); l != "" {
+		labels = strings.Split(l, ",")
+	}
+	issues := []*github.Issue{}
+	for i := len(repo.issues) - 1; i >= 0; i-- {
+		if issue := repo.issues[i]; matchesState(issue.state, state) && hasLabels(issue, labels) {
+			issues = append(issues, s.issueJSON(r, issue))
+		}
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, issues))
+}
+
+func (s *Server) createIssue(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body github.IssueRequest
+	if !decode(w, r, &body) {
+		return
+	}
+	if body.GetTitle() == "" {
+		writeValidationError(w, "Issue", "title", "title is missing")
+		return
+	}
+	issue := s.newIssue(repo, body.GetTitle(), body.GetBody(), s.viewer(r), nil)
+	if body.Labels != nil {
+		issue.labels = *body.Labels
+	}
+	if body.Assignees != nil {
+		issue.assignees = *body.Assignees
+	}
+	writeJSON(w, http.StatusCreated, s.issueJSON(r, issue))
+}
+
+// newIssue opens an issue in the repository, or a pull request if pull is set.
+func (s *Server) newIssue(repo *repository, title, body, author string, pull *pullRequest) *issue {
+	now := s.now()
+	issue := &issue{
+		repo:      repo,
+		id:        s.nextID(),
+		number:    len(repo.issues) + 1,
+		title:     title,
+		body:      body,
+		state:     "open",
+		author:    author,
+		createdAt: now,
+		updatedAt: now,
+		pull:      pull,
+	}
+	repo.issues = append(repo.issues, issue)
+	s.nodes[issue.nodeID()] = issue
+	return issue
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request) {
+	if issue, ok := s.issue(w, r, false); ok {
+		writeJSON(w, http.StatusOK, s.issueJSON(r, issue))
+	}
+}
+
+func (s *Server) editIssue(w http.ResponseWriter, r *http.Request) {
+	issue, ok := s.issue(w, r, false)
+	if !ok {
+		return
+	}
+	var body github.IssueRequest
+	if !decode(w, r, &body) {
+		return
+	}
+	if body.Title != nil {
+		issue.title = body.GetTitle()
+	}
+	if body.Body != nil {
+		issue.body = body.GetBody()
+	}
+	if body.Labels != nil {
+		issue.labels = *body.Labels
+	}
+	if body.Assignees != nil {
+		issue.assignees = *body.Assignees
+	}
+	if body.State != nil {
+		s.setIssueState(issue, body.GetState(), body.GetStateReason())
+	}
+	issue.updatedAt = s.now()
+	writeJSON(w, http.StatusOK, s.issueJSON(r, issue))
+}
+
+// setIssueState closes or reopens an issue, with the reason given or the default one.
+func (s *Server) setIssueState(issue *issue, state, reason string) {
+	if state == issue.state {
+		return
+	}
+	issue.state = state
+	issue.updatedAt = s.now()
+	if state == "closed" {
+		if reason == "" {
+			reason = "completed"
+		}
+		issue.stateReason, issue.closedAt = reason, s.now()
+		return
+	}
+	issue.stateReason, issue.closedAt = "reopened", time.Time{}
+}
+
+func (s *Server) listIssueComments(w http.ResponseWriter, r *http.Request) {
+	issue, ok := s.issue(w, r, false)
+	if !ok {
+		return
+	}
+	comments := []*github.IssueComment{}
+	for _, c := range issue.comments {
+		comments = append(comments, s.issueCommentJSON(r, issue, c))
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, comments))
+}
+
+func (s *Server) createIssueComment(w http.ResponseWriter, r *http.Request) {
+	issue, ok := s.issue(w, r, false)
+	if !ok {
+		return
+	}
+	var body struct {
+		Body string `json:"body"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	if body.Body == "" {
+		writeValidationError(w, "IssueComment", "body", "body is missing")
+		return
+	}
+	c := &comment{id: s.nextID(), author: s.viewer(r), body: body.Body, createdAt: s.now()}
+	issue.comments = append(issue.comments, c)
+	issue.updatedAt = c.createdAt
+	writeJSON(w, http.StatusCreated, s.issueCommentJSON(r, issue, c))
+}
+
+func (s *Server) listPullRequests(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	query := r.URL.Query()
+	head := query.Get("head")
+	if _, branch, ok := strings.Cut(head, ":"); ok {
+		head = branch
+	}
+	pulls := []*github.PullRequest{}
+	for i := len(repo.issues) - 1; i >= 0; i-- {
+		pr := repo.issues[i]
+		if pr.pull == nil || !matchesState(pr.state, query.Get("state")) ||
+			(head != "" && pr.pull.head != head) || (query.Get("base") != "" && pr.pull.base != query.Get("base")) {
+			continue
+		}
+		pulls = append(pulls, s.pullRequestJSON(r, pr))
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, pulls))
+}
+
+func (s *Server) createPullRequest(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repository(w, r)
+	if !ok {
+		return
+	}
+	var body github.NewPullRequest
+	if !decode(w, r, &body) {
+		return
+	}
+	head := body.GetHead()
+	if _, branch, ok := strings.Cut(head, ":"); ok {
+		head = branch
+	}
+	base := body.GetBase()
+	headSHA, headOK := repo.refs["refs/heads/"+head]
+	baseSHA, baseOK := repo.refs["refs/heads/"+base]
+	switch {
+	case body.GetTitle() == "":
+		writeValidationError(w, "PullRequest", "title", "title is missing")
+		return
+	case !baseOK:
+		writeValidationError(w, "PullRequest", "base", "base is invalid")
+		return
+	case !headOK:
+		writeValidationError(w, "PullRequest", "head", "head is invalid")
+		return
+	case repo.isAncestor(headSHA, baseSHA):
+		writeValidationError(w, "PullRequest", "", fmt.Sprintf("No commits between %s and %s", base, head))
+		return
+	}
+	for _, existing := range repo.issues {
+		if existing.pull != nil && existing.state == "open" && existing.pull.head == head && existing.pull.base == base {
+			writeValidationError(w, "PullRequest", "", fmt.Sprintf("A pull request already exists for %s:%s.", repo.owner, head))
+			return
+		}
+	}
+
+	pr := s.newIssue(repo, body.GetTitle(), body.GetBody(), s.viewer(r), &pullRequest{head: head, base: base, draft: body.GetDraft()})
+	writeJSON(w, http.StatusCreated, s.pullRequestJSON(r, pr))
+}
+
+func (s *Server) getPullRequest(w http.ResponseWriter, r *http.Request) {
+	if pr, ok := s.issue(w, r, true); ok {
+		writeJSON(w, http.StatusOK, s.pullRequestJSON(r, pr))
+	}
+}
+
+func (s *Server) editPullRequest(w http.ResponseWriter, r *http.Request) {
+	pr, ok := s.issue(w, r, true)
+	if !ok {
+		return
+	}
+	var body struct {
+		Title *string `json:"title"`
+		Body  *string `json:"body"`
+		State *string `json:"state"`
+		Base  *string `json:"base"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	if body.Base != nil {
+		if _, ok := pr.repo.refs["refs/heads/"+*body.Base]; !ok {
+			writeValidationError(w, "PullRequest", "base", "base is invalid")
+			return
+		}
+		pr.pull.base = *body.Base
+	}
+	if body.Title != nil {
+		pr.title = *body.Title
+	}
+	if body.Body != nil {
+		pr.body = *body.Body
+	}
+	if body.State != nil && !pr.pull.merged {
+		// The branches of a closed pull request stay where they were when it was closed
+		if *body.State == "closed" {
+			pr.pull.headSHA, pr.pull.baseSHA = pr.headSHA(), pr.baseSHA()
+		} else {
+			pr.pull.headSHA, pr.pull.baseSHA = "", ""
+		}
+		s.setIssueState(pr, *body.State, "")
+	}
+	pr.updatedAt = s.now()
+	writeJSON(w, http.StatusOK, s.pullRequestJSON(r, pr))
+}
+
+func (s *Server) listPullRequestFiles(w http.ResponseWriter, r *http.Request) {
+	pr, ok := s.issue(w, r, true)
+	if !ok {
+		return
+	}
+	files := []*github.CommitFile{}
+	for _, change := range pr.repo.changedFiles(pr) {
+		files = append(files, commitFileJSON(r, pr.repo, change, pr.headSHA()))
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, files))
+}
+
+func (s *Server) mergePullRequest(w http.ResponseWriter, r *http.Request) {
+	pr, ok := s.issue(w, r, true)
+	if !ok {
+		return
+	}
+	var body struct {
+		CommitTitle   string `json:"commit_title"`
+		CommitMessage string `json:"commit_message"`
+		MergeMethod   string `json:"merge_method"`
+		SHA           string `json:"sha"`
+	}
+	if !decode(w, r, &body) {
+		return
+	}
+	repo := pr.repo
+	head := pr.headSHA()
+	base, baseOK := repo.refs["refs/heads/"+pr.pull.base]
+	switch {
+	case pr.state != "open" || pr.pull.draft || !baseOK:
+		writeError(w, http.StatusMethodNotAllowed, "Pull Request is not mergeable")
+		return
+	case body.SHA != "" && body.SHA != head:
+		writeError(w, http.StatusConflict, "Head branch was modified. Review and try the merge again.")
+		return
+	}
+	tree, conflicts := repo.merge(base, head)
+	if len(conflicts) > 0 {
+		writeError(w, http.StatusMethodNotAllowed, "Pull Request is not mergeable")
+		return
+	}
+
+	// Squash and rebase merges are both applied as a single commit on the base branch
+	title, parents := body.CommitTitle, []string{base}
+	switch body.MergeMethod {
+	case "", "merge":
+		if title == "" {
+			title = fmt.Sprintf("Merge pull request #%d from %s/%s", pr.number, repo.owner, pr.pull.head)
+		}
+		parents = append(parents, head)
+	case "squash", "rebase":
+		if title == "" {
+			title = fmt.Sprintf("%s (#%d)", pr.title, pr.number)
+		}
+	default:
+		writeValidationError(w, "PullRequest", "merge_method", "merge_method is invalid")
+		return
+	}
+	message := title
+	if body.CommitMessage != "" {
+		message += "\n\n" + body.CommitMessage
+	}
+
+	sha := repo.writeCommit(tree, parents, message, s.viewer(r), s.now())
+	repo.refs["refs/heads/"+pr.pull.base] = sha
+	pr.pull.headSHA, pr.pull.baseSHA = head, base
+	pr.pull.merged, pr.pull.mergedAt, pr.pull.mergedBy, pr.pull.mergeCommit = true, s.now(), s.viewer(r), sha
+	s.setIssueState(pr, "closed", "")
+	writeJSON(w, http.StatusOK, &github.PullRequestMergeResult{
+		SHA:     github.Ptr(sha),
+		Merged:  github.Ptr(true),
+		Message: github.Ptr("Pull Request successfully merged"),
+	})
+}
+
+func (s *Server) listReviews(w http.ResponseWriter, r *http.Request) {
+	pr, ok := s.issue(w, r, true)
+	if !ok {
+		return
+	}
+	reviews := []*github.PullRequestReview{}
+	for _, review := range pr.visibleReviews(s.viewer(r)) {
+		reviews = append(reviews, s.reviewJSON(r, review))
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, reviews))
+}
+
+func (s *Server) listReviewComments(w http.ResponseWriter, r *http.Request) {
+	pr, ok := s.issue(w, r, true)
+	if !ok {
+		return
+	}
+	comments := []*github.PullRequestComment{}
+	for _, review := range pr.pull.reviews {
+		if review.state == "PENDING" {
+			continue
+		}
+		for _, c := range review.comments {
+			comments = append(comments, s.reviewCommentJSON(r, review, c))
+		}
+	}
+	writeJSON(w, http.StatusOK, paginate(w, r, comments))
+}
+
+// visibleReviews returns the reviews of a pull request viewer can see: the submitted ones and their own pending one.
+func (i *issue) visibleReviews(viewer string) []*review {
+	var reviews []*review
+	for _, review := range i.pull.reviews {
+		if review.state != "PENDING" || review.author == viewer {
+			reviews = append(reviews, review)
+		}
+	}
+	return reviews
+}
+
+func (s *Server) getRaw(w http.ResponseWriter, r *http.Request) {
+	repo, ok := s.repos[r.PathValue("owner")+"/"+r.PathValue("repo")]
+	if !ok {
+		http.Error(w, "404: Not Found", http.StatusNotFound)
+		return
+	}
+	// The ref and the path of the file are told apart by trying each split, as refs may contain slashes
+	path := r.PathValue("path")
+	for i := strings.Index(path, "/"); i >= 0; i = nextSlash(path, i) {
+		sha, ok := repo.resolve(path[:i])
+		if !ok {
+			continue
+		}
+		blob, ok := repo.trees[repo.commits[sha].tree][path[i+1:]]
+		if !ok {
+			continue
+		}
+		content := repo.blobs[blob]
+		if utf8.Valid(content) {
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		} else {
+			w.Header().Set("Content-Type", "application/octet-stream")
+		}
+		_, _ = w.Write(content)
+		return
+	}
+	http.Error(w, "404: Not Found", http.StatusNotFound)
+}
+
+// nextSlash returns the index of the first slash in s after index i, or -1 if there is none.
+func nextSlash(s string, i int) int {
+	j := strings.Index(s[i+1:], "/")
+	if j < 0 {
+		return -1
+	}
+	return i + 1 + j
+}
+
+// refNames returns the names of the refs of the repository starting with prefix, without it, sorted.
+func refNames(repo *repository, prefix string) []string {
+	var names []string
+	for ref := range repo.refs {
+		if name, ok := strings.CutPrefix(ref, prefix); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matchesState reports whether an issue or pull request in state is listed when asking for those in filter, which
+// defaults to open ones.
+func matchesState(state, filter string) bool {
+	switch strings.ToLower(filter) {
+	case "all":
+		return true
+	case "":
+		return state == "open"
+	default:
+		return state == strings.ToLower(filter)
+	}
+}
+
+// hasLabels reports whether the issue has all the labels.
+func hasLabels(issue *issue, labels []string) bool {
+	for _, label := range labels {
+		found := false
+		for _, l := range issue.labels {
+			found = found || strings.EqualFold(l, label)
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// directoryEntry is a file or directory directly in a directory.
+type directoryEntry struct {
+	name  string
+	path  string
+	sha   string
+	isDir bool
+}
+
+// listDirectory returns the entries of the directory at dir in the tree of files, the root if dir is empty, sorted
+// by name.
+func listDirectory(repo *repository, files map[string]string, dir string) []directoryEntry {
+	prefix := ""
+	if dir != "" {
+		prefix = dir + "/"
+	}
+	subtrees := make(map[string]map[string]string)
+	var entries []directoryEntry
+	for _, path := range sortedPaths(files) {
+		rest, ok := strings.CutPrefix(path, prefix)
+		if !ok {
+			continue
+		}
+		name, subpath, isDir := strings.Cut(rest, "/")
+		if !isDir {
+			entries = append(entries, directoryEntry{name: name, path: path, sha: files[path]})
+			continue
+		}
+		if subtrees[name] == nil {
+			subtrees[name] = make(map[string]string)
+			entries = append(entries, directoryEntry{name: name, path: prefix + name, isDir: true})
+		}
+		subtrees[name][subpath] = files[path]
+	}
+	for i, entry := range entries {
+		if entry.isDir {
+			entries[i].sha = repo.writeTree(subtrees[entry.name])
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].name < entries[j].name })
+	return entries
+}
+
+// apiURL returns the REST API URL of a resource of the repository.
+func apiURL(r *http.Request, repo *repository, parts ...string) string {
+	return fmt.Sprintf("%s%s/repos/%s/%s/%s", webURL(r), restPrefix, repo.owner, repo.name, strings.Join(parts, "/"))
+}
+
+// htmlURL returns the web URL of a page of the repository.
+func htmlURL(r *http.Request, repo *repository, parts ...string) string {
+	return strings.TrimSuffix(fmt.Sprintf("%s/%s/%s/%s", webURL(r), repo.owner, repo.name, strings.Join(parts, "/")), "/")
+}
+
+func (s *Server) userJSON(r *http.Request, login string) *github.User {
+	id := s.userID(login)
+	return &github.User{
+		Login:   github.Ptr(login),
+		ID:      github.Ptr(id),
+		NodeID:  github.Ptr(fmt.Sprintf("U_%d", id)),
+		Type:    github.Ptr("User"),
+		HTMLURL: github.Ptr(webURL(r) + "/" + login),
+		URL:     github.Ptr(webURL(r) + restPrefix + "/users/" + login),
+	}
+}
+
+func (s *Server) repositoryJSON(r *http.Request, repo *repository) *github.Repository {
+	visibility := "public"
+	if repo.private {
+		visibility = "private"
+	}
+	return &github.Repository{
+		ID:            github.Ptr(repo.id),
+		NodeID:        github.Ptr(fmt.Sprintf("R_%d", repo.id)),
+		Owner:         s.userJSON(r, repo.owner),
+		Name:          github.Ptr(repo.name),
+		FullName:      github.Ptr(repo.owner + "/" + repo.name),
+		Description:   github.Ptr(repo.description),
+		Private:       github.Ptr(repo.private),
+		Visibility:    github.Ptr(visibility),
+		DefaultBranch: github.Ptr(repo.defaultBranch),
+		HTMLURL:       github.Ptr(htmlURL(r, repo)),
+		CloneURL:      github.Ptr(htmlURL(r, repo) + ".git"),
+		URL:           github.Ptr(strings.TrimSuffix(apiURL(r, repo), "/")),
+		CreatedAt:     &github.Timestamp{Time: repo.createdAt},
+	}
+}
+
+func referenceJSON(r *http.Request, repo *repository, name, sha string) *github.Reference {
+	return &github.Reference{
+		Ref: github.Ptr(name),
+		URL: github.Ptr(apiURL(r, repo, "git", name)),
+		Object: &github.GitObject{
+			Type: github.Ptr("commit"),
+			SHA:  github.Ptr(sha),
+			URL:  github.Ptr(apiURL(r, repo, "git/commits", sha)),
+		},
+	}
+}
+
+func gitCommitJSON(r *http.Request, repo *repository, c *commit) *github.Commit {
+	author := &github.CommitAuthor{
+		Name:  github.Ptr(c.author),
+		Email: github.Ptr(c.author + "@users.noreply.github.com"),
+		Date:  &github.Timestamp{Time: c.date},
+	}
+	parents := []*github.Commit{}
+	for _, parent := range c.parents {
+		parents = append(parents, &github.Commit{SHA: github.Ptr(parent), URL: github.Ptr(apiURL(r, repo, "git/commits", parent))})
+	}
+	return &github.Commit{
+		SHA:       github.Ptr(c.sha),
+		Message:   github.Ptr(c.message),
+		Author:    author,
+		Committer: author,
+		Tree:      &github.Tree{SHA: github.Ptr(c.tree)},
+		Parents:   parents,
+		HTMLURL:   github.Ptr(htmlURL(r, repo, "commit", c.sha)),
+		URL:       github.Ptr(apiURL(r, repo, "git/commits", c.sha)),
+	}
+}
+
+// repositoryCommitJSON returns the commit as listed by the commits API, with the files it changed if withFiles.
+func (s *Server) repositoryCommitJSON(r *http.Request, repo *repository, c *commit, withFiles bool) *github.RepositoryCommit {
+	parents := []*github.Commit{}
+	for _, parent := range c.parents {
+		parents = append(parents, &github.Commit{SHA: github.Ptr(parent), URL: github.Ptr(apiURL(r, repo, "commits", parent))})
+	}
+	commit := &github.RepositoryCommit{
+		SHA:       github.Ptr(c.sha),
+		Commit:    gitCommitJSON(r, repo, c),
+		Author:    s.userJSON(r, c.author),
+		Committer: s.userJSON(r, c.author),
+		Parents:   parents,
+		HTMLURL:   github.Ptr(htmlURL(r, repo, "commit", c.sha)),
+		URL:       github.Ptr(apiURL(r, repo, "commits", c.sha)),
+	}
+	if !withFiles {
+		return commit
+	}
+
+	from := emptyTree
+	if len(c.parents) > 0 {
+		from = repo.commits[c.parents[0]].tree
+	}
+	additions, deletions := 0, 0
+	commit.Files = []*github.CommitFile{}
+	for _, change := range repo.diff(from, c.tree) {
+		commit.Files = append(commit.Files, commitFileJSON(r, repo, change, c.sha))
+		additions += change.additions
+		deletions += change.deletions
+	}
+	commit.Stats = &github.CommitStats{
+		Additions: github.Ptr(additions),
+		Deletions: github.Ptr(deletions),
+		Total:     github.Ptr(additions + deletions),
+	}
+	return commit
+}
+
+func commitFileJSON(r *http.Request, repo *repository, change fileChange, ref string) *github.CommitFile {
+	return &github.CommitFile{
+		SHA:         github.Ptr(change.sha),
+		Filename:    github.Ptr(change.path),
+		Status:      github.Ptr(change.status),
+		Additions:   github.Ptr(change.additions),
+		Deletions:   github.Ptr(change.deletions),
+		Changes:     github.Ptr(change.additions + change.deletions),
+		BlobURL:     github.Ptr(htmlURL(r, repo, "blob", ref, change.path)),
+		RawURL:      github.Ptr(fmt.Sprintf("%s/raw/%s/%s/%s/%s", webURL(r), repo.owner, repo.name, ref, change.path)),
+		ContentsURL: github.Ptr(apiURL(r, repo, "contents", change.path) + "?ref=" + ref),
+	}
+}
+
+// fileContentJSON returns the file at path, with its blob, as listed by the contents API, without its content.
+func fileContentJSON(r *http.Request, repo *repository, path, blob, ref string) *github.RepositoryContent {
+	if ref == "" {
+		ref = repo.defaultBranch
+	}
+	name := path[strings.LastIndex(path, "/")+1:]
+	return &github.RepositoryContent{
+		Type:        github.Ptr("file"),
+		Name:        github.Ptr(name),
+		Path:        github.Ptr(path),
+		SHA:         github.Ptr(blob),
+		Size:        github.Ptr(len(repo.blobs[blob])),
+		URL:         github.Ptr(apiURL(r, repo, "contents", path) + "?ref=" + ref),
+		GitURL:      github.Ptr(apiURL(r, repo, "git/blobs", blob)),
+		HTMLURL:     github.Ptr(htmlURL(r, repo, "blob", ref, path)),
+		DownloadURL: github.Ptr(fmt.Sprintf("%s/raw/%s/%s/%s/%s", webURL(r), repo.owner, repo.name, ref, path)),
+	}
+}
+
+func treeJSON(r *http.Request, repo *repository, sha string, recursive bool) *github.Tree {
+	entries := []*github.TreeEntry{}
+	var add func(dir string, files map[string]string)
+	add = func(dir string, files map[string]string) {
+		for _, entry := range listDirectory(repo, files, dir) {
+			treeEntry := &github.TreeEntry{Path: github.Ptr(entry.path), SHA: github.Ptr(entry.sha)}
+			if entry.isDir {
+				treeEntry.Mode, treeEntry.Type = github.Ptr("040000"), github.Ptr("tree")
+				treeEntry.URL = github.Ptr(apiURL(r, repo, "git/trees", entry.sha))
+			} else {
+				treeEntry.Mode, treeEntry.Type = github.Ptr("100644"), github.Ptr("blob")
+				treeEntry.Size = github.Ptr(len(repo.blobs[entry.sha]))
+				treeEntry.URL = github.Ptr(apiURL(r, repo, "git/blobs", entry.sha))
+			}
+			entries = append(entries, treeEntry)
+			if entry.isDir && recursive {
+				add(entry.path, files)
+			}
+		}
+	}
+	add("", repo.trees[sha])
+	return &github.Tree{SHA: github.Ptr(sha), Entries: entries, Truncated: github.Ptr(false)}
+}
+
+func (s *Server) issueJSON(r *http.Request, i *issue) *github.Issue {
+	labels := []*github.Label{}
+	for _, name := range i.labels {
+		labels = append(labels, &github.Label{Name: github.Ptr(name)})
+	}
+	assignees := []*github.User{}
+	for _, login := range i.assignees {
+		assignees = append(assignees, s.userJSON(r, login))
+	}
+	kind := "issues"
+	if i.pull != nil {
+		kind = "pull"
+	}
+	issue := &github.Issue{
+		ID:        github.Ptr(i.id),
+		NodeID:    github.Ptr(i.nodeID()),
+		Number:    github.Ptr(i.number),
+		State:     github.Ptr(i.state),
+		Title:     github.Ptr(i.title),
+		Body:      github.Ptr(i.body),
+		User:      s.userJSON(r, i.author),
+		Labels:    labels,
+		Assignees: assignees,
+		Comments:  github.Ptr(len(i.comments)),
+		CreatedAt: &github.Timestamp{Time: i.createdAt},
+		UpdatedAt: &github.Timestamp{Time: i.updatedAt},
+		HTMLURL:   github.Ptr(htmlURL(r, i.repo, kind, strconv.Itoa(i.number))),
+		URL:       github.Ptr(apiURL(r, i.repo, "issues", strconv.Itoa(i.number))),
+	}
+	if i.stateReason != "" {
+		issue.StateReason = github.Ptr(i.stateReason)
+	}
+	if !i.closedAt.IsZero() {
+		issue.ClosedAt = &github.Timestamp{Time: i.closedAt}
+	}
+	if i.pull != nil {
+		issue.PullRequestLinks = &github.PullRequestLinks{
+			URL:     github.Ptr(apiURL(r, i.repo, "pulls", strconv.Itoa(i.number))),
+			HTMLURL: issue.HTMLURL,
+		}
+	}
+	return issue
+}
+
+func (s *Server) issueCommentJSON(r *http.Request, i *issue, c *comment) *github.IssueComment {
+	return &github.IssueComment{
+		ID:        github.Ptr(c.id),
+		NodeID:    github.Ptr(fmt.Sprintf("IC_%d", c.id)),
+		Body:      github.Ptr(c.body),
+		User:      s.userJSON(r, c.author),
+		CreatedAt: &github.Timestamp{Time: c.createdAt},
+		UpdatedAt: &github.Timestamp{Time: c.createdAt},
+		HTMLURL:   github.Ptr(fmt.Sprintf("%s#issuecomment-%d", htmlURL(r, i.repo, "issues", strconv.Itoa(i.number)), c.id)),
+		URL:       github.Ptr(apiURL(r, i.repo, "issues/comments", strconv.FormatInt(c.id, 10))),
+	}
+}
+
+func (s *Server) pullRequestJSON(r *http.Request, pr *issue) *github.PullRequest {
+	repo := pr.repo
+	head, base := pr.headSHA(), pr.baseSHA()
+	branch := func(name, sha string) *github.PullRequestBranch {
+		return &github.PullRequestBranch{
+			Label: github.Ptr(repo.owner + ":" + name),
+			Ref:   github.Ptr(name),
+			SHA:   github.Ptr(sha),
+			Repo:  s.repositoryJSON(r, repo),
+			User:  s.userJSON(r, repo.owner),
+		}
+	}
+
+	issue := s.issueJSON(r, pr)
+	changes := repo.changedFiles(pr)
+	additions, deletions := 0, 0
+	for _, change := range changes {
+		additions += change.additions
+		deletions += change.deletions
+	}
+	pull := &github.PullRequest{
+		ID:           issue.ID,
+		NodeID:       github.Ptr(pr.nodeID()),
+		Number:       issue.Number,
+		State:        issue.State,
+		Title:        issue.Title,
+		Body:         issue.Body,
+		User:         issue.User,
+		Labels:       issue.Labels,
+		Assignees:    issue.Assignees,
+		Draft:        github.Ptr(pr.pull.draft),
+		Merged:       github.Ptr(pr.pull.merged),
+		Comments:     issue.Comments,
+		Additions:    github.Ptr(additions),
+		Deletions:    github.Ptr(deletions),
+		ChangedFiles: github.Ptr(len(changes)),
+		Head:         branch(pr.pull.head, head),
+		Base:         branch(pr.pull.base, base),
+		CreatedAt:    issue.CreatedAt,
+		UpdatedAt:    issue.UpdatedAt,
+		ClosedAt:     issue.ClosedAt,
+		HTMLURL:      issue.HTMLURL,
+		URL:          github.Ptr(apiURL(r, repo, "pulls", strconv.Itoa(pr.number))),
+		DiffURL:      github.Ptr(*issue.HTMLURL + ".diff"),
+	}
+	if pr.pull.merged {
+		pull.MergedAt = &github.Timestamp{Time: pr.pull.mergedAt}
+		pull.MergedBy = s.userJSON(r, pr.pull.mergedBy)
+		pull.MergeCommitSHA = github.Ptr(pr.pull.mergeCommit)
+	}
+	if pr.state == "open" && base != "" && head != "" {
+		_, conflicts := repo.merge(base, head)
+		pull.Mergeable = github.Ptr(len(conflicts) == 0)
+		switch {
+		case pr.pull.draft:
+			pull.MergeableState = github.Ptr("draft")
+		case len(conflicts) > 0:
+			pull.MergeableState = github.Ptr("dirty")
+		default:
+			pull.MergeableState = github.Ptr("clean")
+		}
+	}
+	return pull
+}
+
+func (s *Server) reviewJSON(r *http.Request, review *review) *github.PullRequestReview {
+	pr := review.pull
+	pullReview := &github.PullRequestReview{
+		ID:             github.Ptr(review.id),
+		NodeID:         github.Ptr(review.nodeID()),
+		User:           s.userJSON(r, review.author),
+		Body:           github.Ptr(review.body),
+		State:          github.Ptr(review.state),
+		CommitID:       github.Ptr(review.commitID),
+		HTMLURL:        github.Ptr(review.url(webURL(r))),
+		PullRequestURL: github.Ptr(apiURL(r, pr.repo, "pulls", strconv.Itoa(pr.number))),
+	}
+	if !review.submittedAt.IsZero() {
+		pullReview.SubmittedAt = &github.Timestamp{Time: review.submittedAt}
+	}
+	return pullReview
+}
+
+func (s *Server) reviewCommentJSON(r *http.Request, review *review, c *reviewComment) *github.PullRequestComment {
+	pr := review.pull
+	comment := &github.PullRequestComment{
+		ID:                  github.Ptr(c.id),
+		NodeID:              github.Ptr(fmt.Sprintf("PRRC_%d", c.id)),
+		Body:                github.Ptr(c.body),
+		Path:                github.Ptr(c.path),
+		SubjectType:         github.Ptr(strings.ToLower(c.subjectType)),
+		PullRequestReviewID: github.Ptr(review.id),
+		CommitID:            github.Ptr(review.commitID),
+		User:                s.userJSON(r, review.author),
+		CreatedAt:           &github.Timestamp{Time: c.createdAt},
+		UpdatedAt:           &github.Timestamp{Time: c.createdAt},
+		HTMLURL:             github.Ptr(fmt.Sprintf("%s#discussion_r%d", htmlURL(r, pr.repo, "pull", strconv.Itoa(pr.number)), c.id)),
+		PullRequestURL:      github.Ptr(apiURL(r, pr.repo, "pulls", strconv.Itoa(pr.number))),
+	}
+	if c.line > 0 {
+		comment.Line, comment.Side = github.Ptr(c.line), github.Ptr(c.side)
+	}
+	if c.startLine > 0 {
+		comment.StartLine, comment.StartSide = github.Ptr(c.startLine), github.Ptr(c.startSide)
+	}
+	return comment
+}
+
+// nodeID returns the GraphQL node ID of the issue or pull request.
+func (i *issue) nodeID() string {
+	if i.pull != nil {
+		return fmt.Sprintf("PR_%d", i.id)
+	}
+	return fmt.Sprintf("I_%d", i.id)
+}
+
+// nodeID returns the GraphQL node ID of the review.
+func (r *review) nodeID() string {
+	return fmt.Sprintf("PRR_%d", r.id)
+}
+
+// url returns the web URL of the review, with web the URL of the fake.
+func (r *review) url(web string) string {
+	return fmt.Sprintf("%s/%s/%s/pull/%d#pullrequestreview-%d", web, r.pull.repo.owner, r.pull.repo.name, r.pull.number, r.id)
+}
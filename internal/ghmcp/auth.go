@@ -0,0 +1,181 @@
+package ghmcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/auth"
+)
+
+type AuthConfig struct {
+	// Version of the server
+	Version string
+
+	// GitHub Host to authenticate with (e.g. github.com or github.enterprise.com)
+	Host string
+
+	// ClientID of the OAuth App to authorize when logging in
+	ClientID string
+
+	// Scopes to request when logging in
+	Scopes []string
+
+	// Store keeps the credentials obtained for each host
+	Store *auth.CredentialStore
+
+	// Out receives the messages meant for the user
+	Out io.Writer
+
+	// Transport configures how to connect to GitHub
+	Transport TransportConfig
+}
+
+// RunAuthLogin runs the OAuth device flow for the configured host and stores the resulting token.
+func RunAuthLogin(cfg AuthConfig) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	apiHost, err := parseAPIHost(cfg.Host)
+	if err != nil {
+		return fmt.Errorf("failed to parse API host: %w", err)
+	}
+
+	// The server does not come with an OAuth App, so tell where to register one on the host
+	if cfg.ClientID == "" {
+		return fmt.Errorf("logging in requires the client ID of an OAuth App with the device flow enabled: register one at %s, "+
+			"tick \"Enable Device Flow\", then pass its client ID with --client-id or GITHUB_OAUTH_CLIENT_ID",
+			apiHost.webURL.JoinPath("settings", "applications", "new"))
+	}
+
+	transport, err := newTransport(cfg.Transport)
+	if err != nil {
+		return err
+	}
+
+	flow := &auth.DeviceFlow{
+		ClientID:   cfg.ClientID,
+		Scopes:     cfg.Scopes,
+		WebURL:     apiHost.webURL,
+		HTTPClient: &http.Client{Transport: transport},
+	}
+
+	code, err := flow.RequestCode(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cfg.Out, "First copy your one-time code: %s\n", code.UserCode)
+	_, _ = fmt.Fprintf(cfg.Out, "Then open %s in your browser and enter the code to authorize the GitHub MCP Server.\n", code.VerificationURI)
+
+	token, err := flow.PollToken(ctx, code)
+	if err != nil {
+		return err
+	}
+
+	login, _, err := whoAmI(ctx, apiHost, transport, token.AccessToken, cfg.Version)
+	if err != nil {
+		return err
+	}
+
+	credential := &auth.Credential{
+		Host:      apiHost.webURL.Host,
+		Token:     token.AccessToken,
+		Scopes:    splitScopes(token.Scope),
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := cfg.Store.Save(credential); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cfg.Out, "Logged in to %s as %s\n", credential.Host, login)
+	return nil
+}
+
+// RunAuthStatus reports whether a credential is stored for the configured host, and whether it is still valid.
+func RunAuthStatus(cfg AuthConfig) error {
+	apiHost, err := parseAPIHost(cfg.Host)
+	if err != nil {
+		return fmt.Errorf("failed to parse API host: %w", err)
+	}
+
+	credential, err := cfg.Store.Load(apiHost.webURL.Host)
+	if errors.Is(err, auth.ErrCredentialNotFound) {
+		return fmt.Errorf("not logged in to %s, run `github-mcp-server auth login` first", apiHost.webURL.Host)
+	}
+	if err != nil {
+		return err
+	}
+
+	transport, err := newTransport(cfg.Transport)
+	if err != nil {
+		return err
+	}
+
+	login, scopes, err := whoAmI(context.Background(), apiHost, transport, credential.Token, cfg.Version)
+	if err != nil {
+		return fmt.Errorf("stored credential for %s is no longer valid: %w", credential.Host, err)
+	}
+
+	_, _ = fmt.Fprintf(cfg.Out, "Logged in to %s as %s\n", credential.Host, login)
+	_, _ = fmt.Fprintf(cfg.Out, "Token scopes: %s\n", scopes)
+	return nil
+}
+
+// RunAuthLogout removes the credential stored for the configured host.
+func RunAuthLogout(cfg AuthConfig) error {
+	apiHost, err := parseAPIHost(cfg.Host)
+	if err != nil {
+		return fmt.Errorf("failed to parse API host: %w", err)
+	}
+
+	err = cfg.Store.Delete(apiHost.webURL.Host)
+	if errors.Is(err, auth.ErrCredentialNotFound) {
+		return fmt.Errorf("not logged in to %s", apiHost.webURL.Host)
+	}
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(cfg.Out, "Logged out of %s\n", apiHost.webURL.Host)
+	return nil
+}
+
+// StoredToken returns the token stored by `auth login` for the given GitHub host.
+func StoredToken(store *auth.CredentialStore, host string) (string, error) {
+	apiHost, err := parseAPIHost(host)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse API host: %w", err)
+	}
+
+	credential, err := store.Load(apiHost.webURL.Host)
+	if err != nil {
+		return "", err
+	}
+	return credential.Token, nil
+}
+
+// whoAmI returns the login of the user token belongs to, and the scopes granted to it.
+func whoAmI(ctx context.Context, apiHost apiHost, transport http.RoundTripper, token string, version string) (string, string, error) {
+	client := newRESTClient(apiHost, transport, auth.StaticTokenSource(token), fmt.Sprintf("github-mcp-server/%s", version))
+
+	user, resp, err := client.Users.Get(ctx, "")
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+	return user.GetLogin(), resp.Header.Get("X-OAuth-Scopes"), nil
+}
+
+func splitScopes(scope string) []string {
+	if scope == "" {
+		return nil
+	}
+	return strings.Split(scope, ",")
+}
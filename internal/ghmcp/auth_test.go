@@ -0,0 +1,15 @@
+package ghmcp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RunAuthLoginWithoutClientID(t *testing.T) {
+	// The error tells where to register an OAuth App on the host logged in to
+	err := RunAuthLogin(AuthConfig{Host: "https://ghe.example.com"})
+	assert.EqualError(t, err, "logging in requires the client ID of an OAuth App with the device flow enabled: "+
+		"register one at https://ghe.example.com/settings/applications/new, tick \"Enable Device Flow\", "+
+		"then pass its client ID with --client-id or GITHUB_OAUTH_CLIENT_ID")
+}
@@ -0,0 +1,182 @@
+package ghmcp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/auth"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	gogithub "github.com/google/go-github/v74/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/shurcooL/githubv4"
+)
+
+// HostConfig is a GitHub host served alongside the default one.
+type HostConfig struct {
+	// GitHub Host to target for API requests, e.g. https://github.example.com
+	Host string
+
+	// APIURLs override the API URLs derived from Host
+	APIURLs APIURLs
+
+	// GitHub Token to authenticate with the host's API
+	Token string
+}
+
+// hostParameter is the tool argument selecting the host a call is made against.
+const hostParameter = "host"
+
+// hostClients are the API clients of a single GitHub host.
+type hostClients struct {
+	// name identifies the host in tool arguments, e.g. github.com or github.example.com:8443
+	name          string
+	apiHost       apiHost
+	restClient    *gogithub.Client
+	gqlHTTPClient *http.Client
+	gqlClient     *githubv4.Client
+}
+
+func newHostClients(apiHost apiHost, restTransport, gqlTransport http.RoundTripper, tokenSource auth.TokenSource, userAgent string) *hostClients {
+	gqlHTTPClient := newGQLHTTPClient(gqlTransport, tokenSource, "") // We're going to wrap the Transport later in setUserAgent
+	return &hostClients{
+		name:          apiHost.webURL.Host,
+		apiHost:       apiHost,
+		restClient:    newRESTClient(apiHost, restTransport, tokenSource, userAgent),
+		gqlHTTPClient: gqlHTTPClient,
+		gqlClient:     githubv4.NewEnterpriseClient(apiHost.graphqlURL.String(), gqlHTTPClient),
+	}
+}
+
+// setUserAgent updates the user agent of the host's REST and GraphQL clients.
+func (c *hostClients) setUserAgent(userAgent string) {
+	c.restClient.UserAgent = userAgent
+	c.gqlHTTPClient.Transport = &userAgentTransport{
+		transport: c.gqlHTTPClient.Transport,
+		agent:     userAgent,
+	}
+}
+
+// hostRouter picks the host a tool call is made against: the one named by its host argument, the one its
+// owner is mapped to, or the default host.
+type hostRouter struct {
+	defaultHost *hostClients
+	hosts       map[string]*hostClients // by lower case name
+	ownerHosts  map[string]*hostClients // by lower case owner
+}
+
+func newHostRouter(defaultHost *hostClients, additionalHosts []*hostClients, ownerHosts map[string]string) (*hostRouter, error) {
+	r := &hostRouter{
+		defaultHost: defaultHost,
+		hosts:       map[string]*hostClients{strings.ToLower(defaultHost.name): defaultHost},
+		ownerHosts:  make(map[string]*hostClients),
+	}
+
+	for _, host := range additionalHosts {
+		name := strings.ToLower(host.name)
+		if _, ok := r.hosts[name]; ok {
+			return nil, fmt.Errorf("host %s is configured more than once", host.name)
+		}
+		r.hosts[name] = host
+	}
+
+	for owner, name := range ownerHosts {
+		host, ok := r.lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("owner %s is mapped to host %s, which is not configured", owner, name)
+		}
+		r.ownerHosts[strings.ToLower(owner)] = host
+	}
+	return r, nil
+}
+
+// lookup returns the host with the given name, which may also be given as a URL.
+func (r *hostRouter) lookup(name string) (*hostClients, bool) {
+	if u, err := url.Parse(name); err == nil && u.Host != "" {
+		name = u.Host
+	}
+	host, ok := r.hosts[strings.ToLower(strings.TrimSuffix(name, "/"))]
+	if !ok && strings.EqualFold(name, "api.github.com") {
+		host, ok = r.hosts["github.com"]
+	}
+	return host, ok
+}
+
+// names returns the names of the hosts, sorted.
+func (r *hostRouter) names() []string {
+	names := make([]string, 0, len(r.hosts))
+	for _, host := range r.hosts {
+		names = append(names, host.name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// searchOwnerPattern matches the search qualifiers naming the owner of the searched repositories.
+var searchOwnerPattern = regexp.MustCompile(`(?:^|\s)(?:org|user|repo):"?([^/\s"]+)`)
+
+// route returns the host a tool call with the given arguments is made against.
+func (r *hostRouter) route(args map[string]any) (*hostClients, error) {
+	if name, _ := args[hostParameter].(string); name != "" {
+		host, ok := r.lookup(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown host %s, expected one of: %s", name, strings.Join(r.names(), ", "))
+		}
+		return host, nil
+	}
+
+	owners := make([]string, 0, 2)
+	for _, param := range []string{"owner", "org"} {
+		if owner, _ := args[param].(string); owner != "" {
+			owners = append(owners, owner)
+		}
+	}
+	if query, _ := args["query"].(string); query != "" {
+		for _, match := range searchOwnerPattern.FindAllStringSubmatch(query, -1) {
+			owners = append(owners, match[1])
+		}
+	}
+	for _, owner := range owners {
+		if host, ok := r.ownerHosts[strings.ToLower(owner)]; ok {
+			return host, nil
+		}
+	}
+	return r.defaultHost, nil
+}
+
+// ToolHandlerMiddleware makes the host picked for each tool call available to the clients it uses.
+func (r *hostRouter) ToolHandlerMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		host, err := r.route(request.GetArguments())
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return next(context.WithValue(ctx, hostContextKey{}, host), request)
+	}
+}
+
+// clientsFor returns the clients of the host picked for the tool call in ctx, or of the default host.
+func (r *hostRouter) clientsFor(ctx context.Context) *hostClients {
+	if host, ok := ctx.Value(hostContextKey{}).(*hostClients); ok {
+		return host
+	}
+	return r.defaultHost
+}
+
+// addHostParameter adds the optional host argument to every tool of tsg.
+func (r *hostRouter) addHostParameter(tsg *toolsets.ToolsetGroup) {
+	description := fmt.Sprintf("GitHub host to use. Defaults to the host configured for the owner, or else %s", r.defaultHost.name)
+	tsg.UpdateTools(func(tool *mcp.Tool) {
+		mcp.WithString(hostParameter,
+			mcp.Description(description),
+			mcp.Enum(r.names()...),
+		)(tool)
+	})
+}
+
+type hostContextKey struct{}
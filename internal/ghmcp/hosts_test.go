@@ -0,0 +1,181 @@
+package ghmcp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_HostRouterRoute(t *testing.T) {
+	dotcom := &hostClients{name: "github.com"}
+	ghes := &hostClients{name: "github.example.com"}
+	router, err := newHostRouter(dotcom, []*hostClients{ghes}, map[string]string{"Corp": "https://github.example.com"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		args           map[string]any
+		expected       *hostClients
+		expectedErrMsg string
+	}{
+		{
+			name:     "default host",
+			args:     map[string]any{"owner": "octocat"},
+			expected: dotcom,
+		},
+		{
+			name:     "host argument",
+			args:     map[string]any{"host": "github.example.com", "owner": "octocat"},
+			expected: ghes,
+		},
+		{
+			name:     "host argument given as URL",
+			args:     map[string]any{"host": "https://GitHub.example.com/"},
+			expected: ghes,
+		},
+		{
+			name:     "host argument wins over owner",
+			args:     map[string]any{"host": "github.com", "owner": "corp"},
+			expected: dotcom,
+		},
+		{
+			name:     "owner mapped to host",
+			args:     map[string]any{"owner": "corp"},
+			expected: ghes,
+		},
+		{
+			name:     "org mapped to host",
+			args:     map[string]any{"org": "CORP"},
+			expected: ghes,
+		},
+		{
+			name:     "search query qualifier mapped to host",
+			args:     map[string]any{"query": "is:open repo:corp/service label:bug"},
+			expected: ghes,
+		},
+		{
+			name:           "unknown host",
+			args:           map[string]any{"host": "gitlab.com"},
+			expectedErrMsg: "unknown host gitlab.com, expected one of: github.com, github.example.com",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			host, err := router.route(tc.args)
+			if tc.expectedErrMsg != "" {
+				require.EqualError(t, err, tc.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Same(t, tc.expected, host)
+		})
+	}
+
+	_, err = newHostRouter(dotcom, []*hostClients{ghes}, map[string]string{"corp": "gitlab.com"})
+	assert.EqualError(t, err, "owner corp is mapped to host gitlab.com, which is not configured")
+
+	_, err = newHostRouter(dotcom, []*hostClients{ghes, {name: "GitHub.example.com"}}, nil)
+	assert.EqualError(t, err, "host GitHub.example.com is configured more than once")
+}
+
+// fakeHost is a stand-in for a GitHub Enterprise Server instance, recording the tokens it was called with.
+type fakeHost struct {
+	server *httptest.Server
+
+	mu     sync.Mutex
+	tokens []string
+}
+
+func newFakeHost(t *testing.T) *fakeHost {
+	t.Helper()
+
+	h := &fakeHost{}
+	h.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.mu.Lock()
+		h.tokens = append(h.tokens, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		h.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.Path, "/branches") {
+			_, _ = w.Write([]byte(`[]`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"login": "octocat"}`))
+	}))
+	t.Cleanup(h.server.Close)
+	return h
+}
+
+func (h *fakeHost) name(t *testing.T) string {
+	u, err := url.Parse(h.server.URL)
+	require.NoError(t, err)
+	return u.Host
+}
+
+func (h *fakeHost) calls() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.tokens...)
+}
+
+func Test_MultipleHosts(t *testing.T) {
+	primary := newFakeHost(t)
+	secondary := newFakeHost(t)
+
+	ghServer, err := NewMCPServer(MCPServerConfig{
+		Version:         "test",
+		Host:            primary.server.URL,
+		Token:           "primary-token",
+		EnabledToolsets: []string{"context", "repos"},
+		Translator:      translations.NullTranslationHelper,
+		AdditionalHosts: []HostConfig{{Host: secondary.server.URL, Token: "secondary-token"}},
+		OwnerHosts:      map[string]string{"corp": secondary.server.URL},
+	})
+	require.NoError(t, err)
+	session := newTestSession("a")
+
+	// Every tool takes the host argument
+	var listResult mcp.ListToolsResult
+	require.NoError(t, json.Unmarshal(handle(t, ghServer, session, "tools/list", map[string]any{}), &listResult))
+	require.NotEmpty(t, listResult.Tools)
+	for _, tool := range listResult.Tools {
+		property, ok := tool.InputSchema.Properties["host"].(map[string]any)
+		require.True(t, ok, "expected %s to take a host argument", tool.Name)
+		assert.ElementsMatch(t, []any{primary.name(t), secondary.name(t)}, property["enum"])
+	}
+
+	callTool := func(name string, args map[string]any) mcp.CallToolResult {
+		var result mcp.CallToolResult
+		require.NoError(t, json.Unmarshal(handle(t, ghServer, session, "tools/call", map[string]any{
+			"name":      name,
+			"arguments": args,
+		}), &result))
+		return result
+	}
+
+	result := callTool("get_me", map[string]any{})
+	require.False(t, result.IsError)
+	assert.Equal(t, []string{"primary-token"}, primary.calls())
+
+	result = callTool("get_me", map[string]any{"host": secondary.name(t)})
+	require.False(t, result.IsError)
+	assert.Equal(t, []string{"secondary-token"}, secondary.calls())
+
+	result = callTool("list_branches", map[string]any{"owner": "corp", "repo": "service"})
+	require.False(t, result.IsError)
+	assert.Equal(t, []string{"secondary-token", "secondary-token"}, secondary.calls())
+	assert.Len(t, primary.calls(), 1)
+
+	result = callTool("get_me", map[string]any{"host": "gitlab.com"})
+	assert.True(t, result.IsError)
+}
@@ -0,0 +1,267 @@
+package ghmcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+const httpServerLogPrefix = "httpserver"
+
+type HTTPServerConfig struct {
+	// Version of the server
+	Version string
+
+	// GitHub Host to target for API requests (e.g. github.com or github.enterprise.com)
+	Host string
+
+	// APIURLs override the API URLs derived from Host
+	APIURLs APIURLs
+
+	// Address to listen on (e.g. :8080)
+	Address string
+
+	// EnabledToolsets is a list of toolsets to enable
+	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#tool-configuration
+	EnabledToolsets []string
+
+	// Whether to enable dynamic toolsets
+	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#dynamic-tool-discovery
+	DynamicToolsets bool
+
+	// ReadOnly indicates if we should only register read-only tools
+	ReadOnly bool
+
+	// DryRun makes every call to a write tool return the changes it would make, without making them
+	DryRun bool
+
+	// Tools limits the tools offered to those matching any of these glob patterns, e.g. create_*
+	Tools []string
+
+	// ExcludeTools removes the tools matching any of these glob patterns, e.g. merge_pull_request
+	ExcludeTools []string
+
+	// AllowRepos limits the repositories tools can access to those matching any of these owner/repo glob
+	// patterns, e.g. myorg/*
+	AllowRepos []string
+
+	// DenyRepos keeps tools from accessing the repositories matching any of these owner/repo glob patterns,
+	// e.g. myorg/secrets-*
+	DenyRepos []string
+
+	// ConfirmTools are glob patterns of the tools whose calls the user is asked to confirm, when their client
+	// supports elicitation, e.g. delete_*
+	ConfirmTools []string
+
+	// Lockdown withholds the bodies of issues and comments written by users who cannot push to their repository
+	Lockdown bool
+
+	// Sanitize removes hidden content, such as HTML comments and invisible Unicode characters, from user-written text
+	Sanitize bool
+
+	// ExportTranslations indicates if we should export translations
+	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#i18n--overriding-descriptions
+	ExportTranslations bool
+
+	// Path to the log file if not stderr
+	LogFilePath string
+
+	// Log configures the format and rotation of the log
+	Log LogConfig
+
+	// Content window size
+	ContentWindowSize int
+
+	// MaxOutputTokens truncates tool results beyond roughly this many tokens, 0 to never truncate them
+	MaxOutputTokens int
+
+	// RateLimit bounds the retries of requests hitting GitHub's rate limits
+	RateLimit RateLimitConfig
+
+	// Cache configures the cache of REST API responses
+	Cache CacheConfig
+
+	// Transport configures how the server connects to GitHub
+	Transport TransportConfig
+
+	// Audit configures the audit log of the calls to write tools
+	Audit AuditConfig
+
+	// Tracing configures the export of OpenTelemetry traces
+	Tracing TracingConfig
+}
+
+// RunHTTPServer serves the MCP streamable HTTP transport on /mcp and the legacy SSE transport on /sse and /message.
+// Every request must carry a GitHub token in its Authorization header, which is used for the API calls it makes,
+// except those for the Prometheus metrics on /metrics.
+func RunHTTPServer(cfg HTTPServerConfig) error {
+	// Create app context
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	t, dumpTranslations := translations.TranslationHelper()
+
+	logger, logOutput, err := newLogger(cfg.LogFilePath, cfg.Log)
+	if err != nil {
+		return err
+	}
+
+	auditLogger, err := newAuditLogger(cfg.Audit, logger)
+	if err != nil {
+		return err
+	}
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	tel, shutdownTelemetry, err := newTelemetry(cfg.Tracing, cfg.Version, registry, logger)
+	if err != nil {
+		return err
+	}
+	defer shutdownTelemetry()
+
+	ghServer, err := NewMCPServer(MCPServerConfig{
+		Version:           cfg.Version,
+		Host:              cfg.Host,
+		APIURLs:           cfg.APIURLs,
+		EnabledToolsets:   cfg.EnabledToolsets,
+		DynamicToolsets:   cfg.DynamicToolsets,
+		ReadOnly:          cfg.ReadOnly,
+		DryRun:            cfg.DryRun,
+		Tools:             cfg.Tools,
+		ExcludeTools:      cfg.ExcludeTools,
+		AllowRepos:        cfg.AllowRepos,
+		DenyRepos:         cfg.DenyRepos,
+		ConfirmTools:      cfg.ConfirmTools,
+		Lockdown:          cfg.Lockdown,
+		Sanitize:          cfg.Sanitize,
+		Translator:        t,
+		ContentWindowSize: cfg.ContentWindowSize,
+		MaxOutputTokens:   cfg.MaxOutputTokens,
+		Logger:            logger,
+		RateLimit:         cfg.RateLimit,
+		Cache:             cfg.Cache,
+		Transport:         cfg.Transport,
+		AuditLogger:       auditLogger,
+		Telemetry:         tel,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create MCP server: %w", err)
+	}
+
+	logger.Info("starting server", "version", cfg.Version, "host", cfg.Host, "address", cfg.Address, "dynamicToolsets", cfg.DynamicToolsets, "readOnly", cfg.ReadOnly, "dryRun", cfg.DryRun, "lockdown", cfg.Lockdown)
+
+	if cfg.ExportTranslations {
+		// Once server is initialized, all translations are loaded
+		dumpTranslations()
+	}
+
+	httpServer := &http.Server{
+		Addr:              cfg.Address,
+		Handler:           withMetrics(NewHTTPHandler(ghServer), registry),
+		ReadHeaderTimeout: 10 * time.Second,
+		ErrorLog:          log.New(logOutput, httpServerLogPrefix, 0),
+	}
+
+	// Start listening for requests
+	errC := make(chan error, 1)
+	go func() {
+		errC <- httpServer.ListenAndServe()
+	}()
+
+	// Output github-mcp-server string
+	_, _ = fmt.Fprintf(os.Stderr, "GitHub MCP Server running on http://%s\n", cfg.Address)
+
+	// Wait for shutdown signal
+	select {
+	case <-ctx.Done():
+		logger.Info("shutting down server", "signal", "context done")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("error shutting down server: %w", err)
+		}
+	case err := <-errC:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("error running server", "error", err)
+			return fmt.Errorf("error running server: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// NewHTTPHandler returns a handler serving ghServer over the streamable HTTP transport on /mcp,
+// and over the legacy SSE transport on /sse and /message.
+func NewHTTPHandler(ghServer *server.MCPServer) http.Handler {
+	contextFunc := func(ctx context.Context, r *http.Request) context.Context {
+		// The token has already been validated by requireToken
+		token, _ := parseAuthorizationHeader(r.Header.Get("Authorization"))
+		// enable GitHub errors in the context
+		return ContextWithToken(ghErrors.ContextWithGitHubErrors(ctx), token)
+	}
+
+	streamableServer := server.NewStreamableHTTPServer(ghServer,
+		server.WithEndpointPath("/mcp"),
+		server.WithHTTPContextFunc(contextFunc),
+	)
+	sseServer := server.NewSSEServer(ghServer,
+		server.WithSSEContextFunc(contextFunc),
+	)
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", streamableServer)
+	mux.Handle(sseServer.CompleteSsePath(), sseServer)
+	mux.Handle(sseServer.CompleteMessagePath(), sseServer)
+
+	return requireToken(mux)
+}
+
+// requireToken rejects requests without a GitHub token in their Authorization header.
+func requireToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := parseAuthorizationHeader(r.Header.Get("Authorization")); err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="github-mcp-server"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// parseAuthorizationHeader extracts the token from an Authorization header using either the
+// "Bearer" or the "token" scheme, as accepted by the GitHub API.
+func parseAuthorizationHeader(header string) (string, error) {
+	if header == "" {
+		return "", errors.New("missing Authorization header")
+	}
+
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok {
+		return "", errors.New("malformed Authorization header")
+	}
+
+	switch strings.ToLower(scheme) {
+	case "bearer", "token":
+	default:
+		return "", fmt.Errorf("unsupported authorization scheme: %s", scheme)
+	}
+
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", errors.New("missing token in Authorization header")
+	}
+	return token, nil
+}
@@ -0,0 +1,121 @@
+package ghmcp
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ParseAuthorizationHeader(t *testing.T) {
+	tests := []struct {
+		name          string
+		header        string
+		expectedToken string
+		expectError   bool
+	}{
+		{name: "bearer scheme", header: "Bearer ghp_abc", expectedToken: "ghp_abc"},
+		{name: "token scheme", header: "token ghp_abc", expectedToken: "ghp_abc"},
+		{name: "scheme is case insensitive", header: "bearer ghp_abc", expectedToken: "ghp_abc"},
+		{name: "missing header", header: "", expectError: true},
+		{name: "missing token", header: "Bearer ", expectError: true},
+		{name: "no scheme", header: "ghp_abc", expectError: true},
+		{name: "unsupported scheme", header: "Basic dXNlcjpwYXNz", expectError: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := parseAuthorizationHeader(tc.header)
+			if tc.expectError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedToken, token)
+		})
+	}
+}
+
+func Test_TokenFromContext(t *testing.T) {
+	_, ok := TokenFromContext(context.Background())
+	assert.False(t, ok)
+
+	_, ok = TokenFromContext(ContextWithToken(context.Background(), ""))
+	assert.False(t, ok)
+
+	token, ok := TokenFromContext(ContextWithToken(context.Background(), "ghp_abc"))
+	assert.True(t, ok)
+	assert.Equal(t, "ghp_abc", token)
+}
+
+func Test_HTTPHandler(t *testing.T) {
+	ghServer, err := NewMCPServer(MCPServerConfig{
+		Version:         "test",
+		EnabledToolsets: []string{"context"},
+		Translator:      translations.NullTranslationHelper,
+	})
+	require.NoError(t, err)
+
+	ts := httptest.NewServer(NewHTTPHandler(ghServer))
+	defer ts.Close()
+
+	initialize := `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","clientInfo":{"name":"test","version":"1.0"},"capabilities":{}}}`
+
+	t.Run("rejects requests without a token", func(t *testing.T) {
+		resp, err := http.Post(ts.URL+"/mcp", "application/json", strings.NewReader(initialize))
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get("WWW-Authenticate"))
+	})
+
+	t.Run("initializes a session with a token", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, ts.URL+"/mcp", strings.NewReader(initialize))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer ghp_abc")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() { _ = resp.Body.Close() }()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get("Mcp-Session-Id"))
+	})
+}
+
+func Test_HTTPHandlerMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_total", Help: "Test counter."})
+	registry.MustRegister(counter)
+	counter.Inc()
+
+	mcpHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+	ts := httptest.NewServer(withMetrics(mcpHandler, registry))
+	defer ts.Close()
+
+	// Metrics are scraped without a token
+	resp, err := http.Get(ts.URL + "/metrics")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "test_total 1")
+
+	// Every other request is left to the MCP handler
+	resp, err = http.Get(ts.URL + "/mcp")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
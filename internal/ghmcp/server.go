@@ -10,13 +10,29 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"path"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
+	"github.com/github/github-mcp-server/pkg/audit"
+	"github.com/github/github-mcp-server/pkg/auth"
+	"github.com/github/github-mcp-server/pkg/budget"
+	"github.com/github/github-mcp-server/pkg/confirmation"
+	"github.com/github/github-mcp-server/pkg/dryrun"
 	"github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/github"
+	"github.com/github/github-mcp-server/pkg/httpcache"
+	"github.com/github/github-mcp-server/pkg/lockdown"
 	mcplog "github.com/github/github-mcp-server/pkg/log"
+	"github.com/github/github-mcp-server/pkg/policy"
+	"github.com/github/github-mcp-server/pkg/ratelimit"
 	"github.com/github/github-mcp-server/pkg/raw"
+	"github.com/github/github-mcp-server/pkg/sanitize"
+	"github.com/github/github-mcp-server/pkg/telemetry"
+	"github.com/github/github-mcp-server/pkg/toolsets"
 	"github.com/github/github-mcp-server/pkg/translations"
 	gogithub "github.com/google/go-github/v74/github"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -31,9 +47,16 @@ type MCPServerConfig struct {
 	// GitHub Host to target for API requests (e.g. github.com or github.enterprise.com)
 	Host string
 
-	// GitHub Token to authenticate with the GitHub API
+	// APIURLs override the API URLs derived from Host
+	APIURLs APIURLs
+
+	// GitHub Token to authenticate with the GitHub API.
+	// A token carried by the request context (see ContextWithToken) takes precedence.
 	Token string
 
+	// GitHub App to authenticate as an installation of, instead of using Token
+	App AppConfig
+
 	// EnabledToolsets is a list of toolsets to enable
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#tool-configuration
 	EnabledToolsets []string
@@ -45,40 +68,220 @@ type MCPServerConfig struct {
 	// ReadOnly indicates if we should only offer read-only tools
 	ReadOnly bool
 
+	// DryRun makes every call to a write tool return the changes it would make, without making them.
+	// Otherwise, write tools take a dry_run argument to do so for a single call.
+	DryRun bool
+
+	// Tools limits the tools offered to those matching any of these glob patterns, e.g. create_*
+	Tools []string
+
+	// ExcludeTools removes the tools matching any of these glob patterns, e.g. merge_pull_request
+	ExcludeTools []string
+
+	// AllowRepos limits the repositories tools can access to those matching any of these owner/repo glob
+	// patterns, e.g. myorg/*
+	AllowRepos []string
+
+	// DenyRepos keeps tools from accessing the repositories matching any of these owner/repo glob patterns,
+	// e.g. myorg/secrets-*
+	DenyRepos []string
+
+	// ConfirmTools are glob patterns of the tools whose calls the user is asked to confirm, when their client
+	// supports elicitation, e.g. delete_*
+	ConfirmTools []string
+
+	// Lockdown withholds the bodies of issues and comments written by users who cannot push to their repository
+	Lockdown bool
+
+	// Sanitize removes hidden content, such as HTML comments and invisible Unicode characters, from user-written text
+	Sanitize bool
+
 	// Translator provides translated text for the server tooling
 	Translator translations.TranslationHelperFunc
 
 	// Content window size
 	ContentWindowSize int
+
+	// MaxOutputTokens truncates tool results beyond roughly this many tokens, the rest being paged through with
+	// the get_more_output tool. Results are not truncated if 0.
+	MaxOutputTokens int
+
+	// HideUnusableTools hides the tools the configured token lacks the scopes for
+	HideUnusableTools bool
+
+	// Logger receives messages about the server's setup, slog.Default() if nil
+	Logger *slog.Logger
+
+	// RateLimit bounds the retries of requests hitting GitHub's rate limits
+	RateLimit RateLimitConfig
+
+	// Cache configures the cache of REST API responses
+	Cache CacheConfig
+
+	// Transport configures how the server connects to GitHub
+	Transport TransportConfig
+
+	// AdditionalHosts are served alongside Host, each tool taking a host argument to pick one
+	AdditionalHosts []HostConfig
+
+	// OwnerHosts maps owners to the host their tool calls are made against when no host is given
+	OwnerHosts map[string]string
+
+	// AuditLogger records the calls to write tools, if not nil
+	AuditLogger *audit.Logger
+
+	// Telemetry traces and measures tool calls, resource reads and API requests, if not nil
+	Telemetry *telemetry.Telemetry
+}
+
+// AuditConfig configures the audit log of the calls to write tools.
+type AuditConfig struct {
+	// Path is the file the records are appended to, no audit log is kept if empty
+	Path string
+
+	// Redact are glob patterns of further argument names whose values are left out of the records
+	Redact []string
+}
+
+// LogConfig configures the server's log.
+type LogConfig struct {
+	// Format is either text, the default, or json for a JSON object per line
+	Format string
+
+	// MaxSize rotates the log file before it grows beyond this many bytes, 0 to never rotate it
+	MaxSize int64
+
+	// MaxBackups is the number of rotated log files kept
+	MaxBackups int
+
+	// Redact are regular expressions of further text redacted from the logged JSON-RPC messages, in addition to
+	// GitHub tokens
+	Redact []string
+}
+
+// CacheConfig configures the cache of REST API responses, revalidated with conditional requests.
+type CacheConfig struct {
+	// Enabled turns the cache on
+	Enabled bool
+
+	// Dir keeps the cache on disk in the given directory rather than in memory
+	Dir string
+
+	// MaxSize is the maximum total size of the cached responses, in bytes
+	MaxSize int64
+}
+
+// RateLimitConfig is the budget for retrying a request that hit GitHub's rate limits.
+type RateLimitConfig struct {
+	// MaxRetries is the maximum number of times a request is retried
+	MaxRetries int
+
+	// MaxWait is the maximum total time spent waiting to retry a request
+	MaxWait time.Duration
 }
 
 const stdioServerLogPrefix = "stdioserver"
 
 func NewMCPServer(cfg MCPServerConfig) (*server.MCPServer, error) {
-	apiHost, err := parseAPIHost(cfg.Host)
+	apiHost, err := newAPIHost(cfg.Host, cfg.APIURLs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse API host: %w", err)
 	}
 
-	// Construct our REST client
-	restClient := gogithub.NewClient(nil).WithAuthToken(cfg.Token)
-	restClient.UserAgent = fmt.Sprintf("github-mcp-server/%s", cfg.Version)
-	restClient.BaseURL = apiHost.baseRESTURL
-	restClient.UploadURL = apiHost.uploadURL
+	// Every client connects to GitHub through the same configured transport
+	baseTransport, err := newBaseTransport(cfg.Transport)
+	if err != nil {
+		return nil, err
+	}
 
-	// Construct our GraphQL client
-	// We're using NewEnterpriseClient here unconditionally as opposed to NewClient because we already
-	// did the necessary API host parsing so that github.com will return the correct URL anyway.
-	gqlHTTPClient := &http.Client{
-		Transport: &bearerAuthTransport{
-			transport: http.DefaultTransport,
-			token:     cfg.Token,
-		},
-	} // We're going to wrap the Transport later in beforeInit
-	gqlClient := githubv4.NewEnterpriseClient(apiHost.graphqlURL.String(), gqlHTTPClient)
+	tokenSource, err := newTokenSource(cfg, apiHost, baseTransport)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	defaultUserAgent := fmt.Sprintf("github-mcp-server/%s", cfg.Version)
+
+	hostAPIHosts, err := newAdditionalAPIHosts(cfg.AdditionalHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	// Record the IDs GitHub gives the requests made for audited tool calls
+	var apiTransport http.RoundTripper = baseTransport
+	if cfg.AuditLogger != nil {
+		apiTransport = &audit.Transport{Base: baseTransport}
+	}
+
+	// Trace the requests actually sent, each retry on its own
+	if cfg.Telemetry != nil {
+		apiTransport = &telemetry.Transport{
+			Base:      apiTransport,
+			Telemetry: cfg.Telemetry,
+			API:       apiOf(apiHost, hostAPIHosts),
+		}
+	}
+
+	// Keep dry runs from sending their mutations
+	apiTransport = &dryrun.Transport{Base: apiTransport}
+
+	// Retry requests hitting the rate limits, for both the REST and GraphQL APIs
+	transport := &ratelimit.Transport{
+		Base:       apiTransport,
+		MaxRetries: cfg.RateLimit.MaxRetries,
+		MaxWait:    cfg.RateLimit.MaxWait,
+	}
+
+	// Serve unchanged REST API responses from the cache
+	restTransport, err := newCacheTransport(cfg.Cache, transport)
+	if err != nil {
+		return nil, err
+	}
+
+	// Construct our REST and GraphQL clients, for every host
+	defaultHost := newHostClients(apiHost, restTransport, transport, tokenSource, defaultUserAgent)
+	additionalHosts := make([]*hostClients, 0, len(cfg.AdditionalHosts))
+	for i, host := range cfg.AdditionalHosts {
+		additionalHosts = append(additionalHosts, newHostClients(hostAPIHosts[i], restTransport, transport, auth.StaticTokenSource(host.Token), defaultUserAgent))
+	}
+	router, err := newHostRouter(defaultHost, additionalHosts, cfg.OwnerHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	var confirmer *confirmation.Confirmer
+	if len(cfg.ConfirmTools) > 0 {
+		confirmFilter, err := toolsets.NewPatternToolFilter(cfg.ConfirmTools, nil)
+		if err != nil {
+			return nil, err
+		}
+		confirmer = confirmation.New(func(tool string) bool {
+			_, ok := confirmFilter(tool)
+			return ok
+		}, github.ConfirmationSummary)
+	}
+
+	var outputBudget *budget.Budget
+	if cfg.MaxOutputTokens > 0 {
+		outputBudget = budget.New(cfg.MaxOutputTokens * budget.BytesPerToken)
+	}
+
+	// User agents of sessions whose token is supplied per request, keyed by session ID.
+	var sessionUserAgents sync.Map
 
 	// When a client send an initialize request, update the user agent to include the client info.
-	beforeInit := func(_ context.Context, _ any, message *mcp.InitializeRequest) {
+	beforeInit := func(ctx context.Context, _ any, message *mcp.InitializeRequest) {
+		if cfg.AuditLogger != nil {
+			cfg.AuditLogger.SetClient(toolsets.SessionIDFromContext(ctx), message.Params.ClientInfo)
+		}
+		if confirmer != nil {
+			confirmer.SetClientCapabilities(toolsets.SessionIDFromContext(ctx), message.Params.Capabilities)
+		}
+
 		userAgent := fmt.Sprintf(
 			"github-mcp-server/%s (%s/%s)",
 			cfg.Version,
@@ -86,16 +289,36 @@ func NewMCPServer(cfg MCPServerConfig) (*server.MCPServer, error) {
 			message.Params.ClientInfo.Version,
 		)
 
-		restClient.UserAgent = userAgent
+		// Clients built per request are shared by nobody, so only remember the agent for the session.
+		if _, ok := TokenFromContext(ctx); ok {
+			if session := server.ClientSessionFromContext(ctx); session != nil {
+				sessionUserAgents.Store(session.SessionID(), userAgent)
+			}
+			return
+		}
 
-		gqlHTTPClient.Transport = &userAgentTransport{
-			transport: gqlHTTPClient.Transport,
-			agent:     userAgent,
+		defaultHost.setUserAgent(userAgent)
+		for _, host := range additionalHosts {
+			host.setUserAgent(userAgent)
 		}
 	}
 
 	hooks := &server.Hooks{
 		OnBeforeInitialize: []server.OnBeforeInitializeFunc{beforeInit},
+		OnUnregisterSession: []server.OnUnregisterSessionHookFunc{
+			func(_ context.Context, session server.ClientSession) {
+				sessionUserAgents.Delete(session.SessionID())
+				if cfg.AuditLogger != nil {
+					cfg.AuditLogger.ForgetSession(session.SessionID())
+				}
+				if confirmer != nil {
+					confirmer.ForgetSession(session.SessionID())
+				}
+				if outputBudget != nil {
+					outputBudget.ForgetSession(session.SessionID())
+				}
+			},
+		},
 		OnBeforeAny: []server.BeforeAnyHookFunc{
 			func(ctx context.Context, _ any, _ mcp.MCPMethod, _ any) {
 				// Ensure the context is cleared of any previous errors
@@ -119,17 +342,47 @@ func NewMCPServer(cfg MCPServerConfig) (*server.MCPServer, error) {
 	// Generate instructions based on enabled toolsets
 	instructions := github.GenerateInstructions(enabledToolsets)
 
-	ghServer := github.NewServer(cfg.Version,
-		server.WithInstructions(instructions),
-		server.WithHooks(hooks),
-	)
+	// userAgentFor returns the user agent recorded for the session in ctx, if any.
+	userAgentFor := func(ctx context.Context) string {
+		if session := server.ClientSessionFromContext(ctx); session != nil {
+			if userAgent, ok := sessionUserAgents.Load(session.SessionID()); ok {
+				return userAgent.(string)
+			}
+		}
+		return defaultUserAgent
+	}
 
-	getClient := func(_ context.Context) (*gogithub.Client, error) {
-		return restClient, nil // closing over client
+	// requestToken returns the token carried by ctx, refusing to send it to any host but the default one.
+	requestToken := func(ctx context.Context, host *hostClients) (string, bool, error) {
+		token, ok := TokenFromContext(ctx)
+		if ok && host != defaultHost {
+			return "", false, fmt.Errorf("the request's token can only be used with %s, not %s", defaultHost.name, host.name)
+		}
+		return token, ok, nil
 	}
 
-	getGQLClient := func(_ context.Context) (*githubv4.Client, error) {
-		return gqlClient, nil // closing over client
+	getClient := func(ctx context.Context) (*gogithub.Client, error) {
+		host := router.clientsFor(ctx)
+		token, ok, err := requestToken(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return newRESTClient(host.apiHost, restTransport, auth.StaticTokenSource(token), userAgentFor(ctx)), nil
+		}
+		return host.restClient, nil // closing over client
+	}
+
+	getGQLClient := func(ctx context.Context) (*githubv4.Client, error) {
+		host := router.clientsFor(ctx)
+		token, ok, err := requestToken(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return githubv4.NewEnterpriseClient(host.apiHost.graphqlURL.String(), newGQLHTTPClient(transport, auth.StaticTokenSource(token), userAgentFor(ctx))), nil
+		}
+		return host.gqlClient, nil // closing over client
 	}
 
 	getRawClient := func(ctx context.Context) (*raw.Client, error) {
@@ -137,28 +390,298 @@ func NewMCPServer(cfg MCPServerConfig) (*server.MCPServer, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 		}
-		return raw.NewClient(client, apiHost.rawURL), nil // closing over client
+		return raw.NewClient(client, router.clientsFor(ctx).apiHost.rawURL), nil // closing over client
+	}
+
+	// Downloads from outside the API, such as job logs, go through the base transport without the token
+	downloadClient := &http.Client{Transport: baseTransport}
+	getDownloadClient := func(_ context.Context) (*http.Client, error) {
+		return downloadClient, nil // closing over client
 	}
 
 	// Create default toolsets
-	tsg := github.DefaultToolsetGroup(cfg.ReadOnly, getClient, getGQLClient, getRawClient, cfg.Translator, cfg.ContentWindowSize)
+	tsg := github.DefaultToolsetGroup(cfg.ReadOnly, getClient, getGQLClient, getRawClient, getDownloadClient, cfg.Translator, cfg.ContentWindowSize)
+
+	if len(cfg.Tools) > 0 || len(cfg.ExcludeTools) > 0 {
+		if err := filterToolsByPattern(tsg, cfg.Tools, cfg.ExcludeTools, logger); err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.HideUnusableTools {
+		hideUnusableTools(tsg, append([]*hostClients{defaultHost}, additionalHosts...), logger)
+	}
+
+	var repoPolicy *policy.Policy
+	if len(cfg.AllowRepos) > 0 || len(cfg.DenyRepos) > 0 {
+		repoPolicy, err = policy.New(cfg.AllowRepos, cfg.DenyRepos)
+		if err != nil {
+			return nil, err
+		}
+		tsg.UpdateResourceTemplates(func(template *server.ServerResourceTemplate) {
+			template.Handler = repoPolicy.ResourceTemplateHandler(template.Handler)
+		})
+	}
+
+	if cfg.Telemetry != nil {
+		tsg.UpdateResourceTemplates(func(template *server.ServerResourceTemplate) {
+			template.Handler = cfg.Telemetry.ResourceTemplateHandler(template.Handler)
+		})
+	}
+
+	// Let any call to a write tool be a dry run
+	if !cfg.DryRun {
+		tsg.UpdateTools(func(tool *mcp.Tool) {
+			if tsg.IsWriteTool(tool.Name) {
+				dryrun.WithParameter()(tool)
+			}
+		})
+	}
+
+	// Let every tool be called against any of the hosts
+	if len(additionalHosts) > 0 {
+		router.addHostParameter(tsg)
+	}
+
 	err = tsg.EnableToolsets(enabledToolsets)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to enable toolsets: %w", err)
 	}
 
+	serverOpts := []server.ServerOption{
+		server.WithInstructions(instructions),
+		server.WithHooks(hooks),
+	}
+	if cfg.Telemetry != nil {
+		// Trace every tool call from start to end, including those rejected by the other middlewares
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(cfg.Telemetry.ToolHandlerMiddleware))
+	}
+	if outputBudget != nil {
+		// Truncate every result, including errors, dry runs and the remainders paged through by get_more_output
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(outputBudget.ToolHandlerMiddleware))
+	}
+	serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(dryrun.ToolHandlerMiddleware(cfg.DryRun, tsg.IsWriteTool)))
+	if cfg.AuditLogger != nil {
+		// Audit every call to a write tool, including those rejected by the other middlewares
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(cfg.AuditLogger.ToolHandlerMiddleware(tsg.IsWriteTool)))
+	}
+	serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(ratelimit.ToolHandlerMiddleware))
+	if len(additionalHosts) > 0 {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(router.ToolHandlerMiddleware))
+	}
+	if repoPolicy != nil {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(repoPolicy.ToolHandlerMiddleware))
+	}
+	if cfg.Sanitize {
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(sanitize.ToolHandlerMiddleware))
+	}
+	if cfg.Lockdown {
+		// Permissions are looked up with the client of the call, so against the host it is routed to
+		lock := lockdown.New(getClient)
+		serverOpts = append(serverOpts, server.WithToolHandlerMiddleware(lock.ToolHandlerMiddleware))
+		hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+			lock.ForgetSession(session.SessionID())
+		})
+	}
+	if cfg.DynamicToolsets {
+		// Toolsets enabled dynamically are only visible to, and callable by, the session enabling them
+		serverOpts = append(serverOpts,
+			server.WithToolFilter(tsg.FilterSessionTools),
+			server.WithToolHandlerMiddleware(tsg.SessionToolMiddleware),
+		)
+		hooks.AddOnUnregisterSession(func(_ context.Context, session server.ClientSession) {
+			tsg.ForgetSession(session.SessionID())
+		})
+	}
+	if confirmer != nil {
+		// Only ask users to confirm the calls the other middlewares let through, which will actually be made
+		serverOpts = append(serverOpts, server.WithElicitation(), server.WithToolHandlerMiddleware(confirmer.ToolHandlerMiddleware))
+	}
+
+	ghServer := github.NewServer(cfg.Version, serverOpts...)
+
 	// Register all mcp functionality with the server
 	tsg.RegisterAll(ghServer)
 
+	// Results can be truncated whatever tools are enabled, so the tool to get the rest always is
+	if outputBudget != nil {
+		ghServer.AddTool(github.GetMoreOutput(outputBudget, cfg.Translator))
+	}
+
 	if cfg.DynamicToolsets {
-		dynamic := github.InitDynamicToolset(ghServer, tsg, cfg.Translator)
+		tsg.RegisterSessionTools(ghServer)
+
+		dynamic := github.InitDynamicToolset(tsg, cfg.Translator)
 		dynamic.RegisterTools(ghServer)
 	}
 
 	return ghServer, nil
 }
 
+// filterToolsByPattern removes the tools not matching the include patterns, or matching the exclude patterns,
+// from tsg. Patterns matching no tool at all are most likely typos, so they are warned about.
+func filterToolsByPattern(tsg *toolsets.ToolsetGroup, include, exclude []string, logger *slog.Logger) error {
+	filter, err := toolsets.NewPatternToolFilter(include, exclude)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, toolset := range tsg.Toolsets {
+		for _, tool := range toolset.GetAvailableTools() {
+			names = append(names, tool.Tool.Name)
+		}
+	}
+	for _, pattern := range append(append([]string(nil), include...), exclude...) {
+		if !slices.ContainsFunc(names, func(name string) bool {
+			ok, _ := path.Match(pattern, name)
+			return ok
+		}) {
+			logger.Warn("tool pattern matches no tool", "pattern", pattern)
+		}
+	}
+
+	for _, tool := range tsg.FilterTools(filter) {
+		logger.Debug("hiding tool", "tool", tool.Name, "toolset", tool.Toolset, "reason", tool.Reason)
+	}
+	return nil
+}
+
+// hideUnusableTools removes the tools that the tokens of none of the hosts have the scopes for from tsg. The tools
+// are left alone if the scopes cannot be determined, as hiding tools by mistake is worse than offering a failing one.
+func hideUnusableTools(tsg *toolsets.ToolsetGroup, hosts []*hostClients, logger *slog.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), scopeCheckTimeout)
+	defer cancel()
+
+	filters := make([]toolsets.ToolFilter, 0, len(hosts))
+	for _, host := range hosts {
+		scopes, err := github.FetchTokenScopes(ctx, host.restClient)
+		if err != nil {
+			logger.Warn("not hiding unusable tools", "host", host.name, "error", err)
+			return
+		}
+		filters = append(filters, github.ScopeToolFilter(scopes, tsg))
+	}
+
+	// A tool is kept if it can be used with any of the hosts, giving the reason of the first host otherwise
+	filter := func(tool string) (string, bool) {
+		var reason string
+		for i, filter := range filters {
+			hostReason, ok := filter(tool)
+			if ok {
+				return "", true
+			}
+			if i == 0 {
+				reason = hostReason
+			}
+		}
+		return reason, false
+	}
+
+	for _, tool := range tsg.FilterTools(filter) {
+		logger.Info("hiding tool", "tool", tool.Name, "toolset", tool.Toolset, "reason", tool.Reason)
+	}
+}
+
+// scopeCheckTimeout bounds the requests made at startup to determine the token's scopes.
+const scopeCheckTimeout = 10 * time.Second
+
+// AppConfig identifies a GitHub App installation to authenticate as.
+type AppConfig struct {
+	// ID of the GitHub App
+	ID int64
+
+	// ID of the installation of the App to act as
+	InstallationID int64
+
+	// Path to the PEM encoded private key generated for the App
+	PrivateKeyPath string
+}
+
+// newTokenSource returns the source of tokens for API requests not carrying their own token:
+// installation tokens if a GitHub App is configured, exchanged through base, the configured token otherwise.
+func newTokenSource(cfg MCPServerConfig, apiHost apiHost, base http.RoundTripper) (auth.TokenSource, error) {
+	if cfg.App.ID == 0 {
+		return auth.StaticTokenSource(cfg.Token), nil
+	}
+
+	privateKey, err := os.ReadFile(cfg.App.PrivateKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read GitHub App private key: %w", err)
+	}
+
+	tokenSource, err := auth.NewAppTokenSource(cfg.App.ID, cfg.App.InstallationID, privateKey, apiHost.baseRESTURL, base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub App token source: %w", err)
+	}
+	return tokenSource, nil
+}
+
+// newCacheTransport wraps base with a cache of responses if one is configured.
+func newCacheTransport(cfg CacheConfig, base http.RoundTripper) (http.RoundTripper, error) {
+	if !cfg.Enabled {
+		return base, nil
+	}
+
+	var store httpcache.Store = httpcache.NewMemoryStore(cfg.MaxSize)
+	if cfg.Dir != "" {
+		diskStore, err := httpcache.NewDiskStore(cfg.Dir, cfg.MaxSize)
+		if err != nil {
+			return nil, err
+		}
+		store = diskStore
+	}
+
+	return &httpcache.Transport{
+		Base:  base,
+		Store: store,
+		// Keep a single large response, such as a file's contents, from evicting everything else
+		MaxEntrySize: cfg.MaxSize / 10,
+	}, nil
+}
+
+// newRESTClient constructs a REST client for the given host, sending requests authenticated with tokens
+// from tokenSource through base, or http.DefaultTransport if nil.
+func newRESTClient(apiHost apiHost, base http.RoundTripper, tokenSource auth.TokenSource, userAgent string) *gogithub.Client {
+	client := gogithub.NewClient(&http.Client{
+		Transport: &auth.Transport{Base: base, Source: tokenSource},
+	})
+	client.UserAgent = userAgent
+	client.BaseURL = apiHost.baseRESTURL
+	client.UploadURL = apiHost.uploadURL
+	return client
+}
+
+// newGQLHTTPClient constructs the HTTP client used by the GraphQL client, sending requests authenticated with
+// tokens from tokenSource through base, or http.DefaultTransport if nil.
+// An empty userAgent leaves the User-Agent header untouched.
+// We're using NewEnterpriseClient with it unconditionally as opposed to NewClient because we already
+// did the necessary API host parsing so that github.com will return the correct URL anyway.
+func newGQLHTTPClient(base http.RoundTripper, tokenSource auth.TokenSource, userAgent string) *http.Client {
+	var transport http.RoundTripper = &auth.Transport{Base: base, Source: tokenSource}
+	if userAgent != "" {
+		transport = &userAgentTransport{
+			transport: transport,
+			agent:     userAgent,
+		}
+	}
+	return &http.Client{Transport: transport}
+}
+
+type tokenContextKey struct{}
+
+// ContextWithToken returns a copy of ctx carrying the GitHub token used for API requests made on its behalf.
+func ContextWithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, token)
+}
+
+// TokenFromContext returns the GitHub token carried by ctx, if any.
+func TokenFromContext(ctx context.Context) (string, bool) {
+	token, ok := ctx.Value(tokenContextKey{}).(string)
+	return token, ok && token != ""
+}
+
 type StdioServerConfig struct {
 	// Version of the server
 	Version string
@@ -166,9 +689,15 @@ type StdioServerConfig struct {
 	// GitHub Host to target for API requests (e.g. github.com or github.enterprise.com)
 	Host string
 
+	// APIURLs override the API URLs derived from Host
+	APIURLs APIURLs
+
 	// GitHub Token to authenticate with the GitHub API
 	Token string
 
+	// GitHub App to authenticate as an installation of, instead of using Token
+	App AppConfig
+
 	// EnabledToolsets is a list of toolsets to enable
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#tool-configuration
 	EnabledToolsets []string
@@ -180,6 +709,33 @@ type StdioServerConfig struct {
 	// ReadOnly indicates if we should only register read-only tools
 	ReadOnly bool
 
+	// DryRun makes every call to a write tool return the changes it would make, without making them
+	DryRun bool
+
+	// Tools limits the tools offered to those matching any of these glob patterns, e.g. create_*
+	Tools []string
+
+	// ExcludeTools removes the tools matching any of these glob patterns, e.g. merge_pull_request
+	ExcludeTools []string
+
+	// AllowRepos limits the repositories tools can access to those matching any of these owner/repo glob
+	// patterns, e.g. myorg/*
+	AllowRepos []string
+
+	// DenyRepos keeps tools from accessing the repositories matching any of these owner/repo glob patterns,
+	// e.g. myorg/secrets-*
+	DenyRepos []string
+
+	// ConfirmTools are glob patterns of the tools whose calls the user is asked to confirm, when their client
+	// supports elicitation, e.g. delete_*
+	ConfirmTools []string
+
+	// Lockdown withholds the bodies of issues and comments written by users who cannot push to their repository
+	Lockdown bool
+
+	// Sanitize removes hidden content, such as HTML comments and invisible Unicode characters, from user-written text
+	Sanitize bool
+
 	// ExportTranslations indicates if we should export translations
 	// See: https://github.com/github/github-mcp-server?tab=readme-ov-file#i18n--overriding-descriptions
 	ExportTranslations bool
@@ -190,8 +746,38 @@ type StdioServerConfig struct {
 	// Path to the log file if not stderr
 	LogFilePath string
 
+	// Log configures the format and rotation of the log, and the redaction of the logged JSON-RPC messages
+	Log LogConfig
+
 	// Content window size
 	ContentWindowSize int
+
+	// MaxOutputTokens truncates tool results beyond roughly this many tokens, 0 to never truncate them
+	MaxOutputTokens int
+
+	// HideUnusableTools hides the tools the token lacks the scopes for
+	HideUnusableTools bool
+
+	// RateLimit bounds the retries of requests hitting GitHub's rate limits
+	RateLimit RateLimitConfig
+
+	// Cache configures the cache of REST API responses
+	Cache CacheConfig
+
+	// Transport configures how the server connects to GitHub
+	Transport TransportConfig
+
+	// AdditionalHosts are served alongside Host, each tool taking a host argument to pick one
+	AdditionalHosts []HostConfig
+
+	// OwnerHosts maps owners to the host their tool calls are made against when no host is given
+	OwnerHosts map[string]string
+
+	// Audit configures the audit log of the calls to write tools
+	Audit AuditConfig
+
+	// Tracing configures the export of OpenTelemetry traces
+	Tracing TracingConfig
 }
 
 // RunStdioServer is not concurrent safe.
@@ -202,37 +788,62 @@ func RunStdioServer(cfg StdioServerConfig) error {
 
 	t, dumpTranslations := translations.TranslationHelper()
 
+	logger, logOutput, err := newLogger(cfg.LogFilePath, cfg.Log)
+	if err != nil {
+		return err
+	}
+	redactor, err := mcplog.NewRedactor(cfg.Log.Redact)
+	if err != nil {
+		return err
+	}
+
+	auditLogger, err := newAuditLogger(cfg.Audit, logger)
+	if err != nil {
+		return err
+	}
+
+	tel, shutdownTelemetry, err := newTelemetry(cfg.Tracing, cfg.Version, nil, logger)
+	if err != nil {
+		return err
+	}
+	defer shutdownTelemetry()
+
 	ghServer, err := NewMCPServer(MCPServerConfig{
 		Version:           cfg.Version,
 		Host:              cfg.Host,
+		APIURLs:           cfg.APIURLs,
 		Token:             cfg.Token,
+		App:               cfg.App,
 		EnabledToolsets:   cfg.EnabledToolsets,
 		DynamicToolsets:   cfg.DynamicToolsets,
 		ReadOnly:          cfg.ReadOnly,
+		DryRun:            cfg.DryRun,
+		Tools:             cfg.Tools,
+		ExcludeTools:      cfg.ExcludeTools,
+		AllowRepos:        cfg.AllowRepos,
+		DenyRepos:         cfg.DenyRepos,
+		ConfirmTools:      cfg.ConfirmTools,
+		Lockdown:          cfg.Lockdown,
+		Sanitize:          cfg.Sanitize,
 		Translator:        t,
 		ContentWindowSize: cfg.ContentWindowSize,
+		MaxOutputTokens:   cfg.MaxOutputTokens,
+		HideUnusableTools: cfg.HideUnusableTools,
+		Logger:            logger,
+		RateLimit:         cfg.RateLimit,
+		Cache:             cfg.Cache,
+		Transport:         cfg.Transport,
+		AdditionalHosts:   cfg.AdditionalHosts,
+		OwnerHosts:        cfg.OwnerHosts,
+		AuditLogger:       auditLogger,
+		Telemetry:         tel,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to create MCP server: %w", err)
 	}
 
 	stdioServer := server.NewStdioServer(ghServer)
-
-	var slogHandler slog.Handler
-	var logOutput io.Writer
-	if cfg.LogFilePath != "" {
-		file, err := os.OpenFile(cfg.LogFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
-		if err != nil {
-			return fmt.Errorf("failed to open log file: %w", err)
-		}
-		logOutput = file
-		slogHandler = slog.NewTextHandler(logOutput, &slog.HandlerOptions{Level: slog.LevelDebug})
-	} else {
-		logOutput = os.Stderr
-		slogHandler = slog.NewTextHandler(logOutput, &slog.HandlerOptions{Level: slog.LevelInfo})
-	}
-	logger := slog.New(slogHandler)
-	logger.Info("starting server", "version", cfg.Version, "host", cfg.Host, "dynamicToolsets", cfg.DynamicToolsets, "readOnly", cfg.ReadOnly)
+	logger.Info("starting server", "version", cfg.Version, "host", cfg.Host, "dynamicToolsets", cfg.DynamicToolsets, "readOnly", cfg.ReadOnly, "dryRun", cfg.DryRun, "lockdown", cfg.Lockdown)
 	stdLogger := log.New(logOutput, stdioServerLogPrefix, 0)
 	stdioServer.SetErrorLogger(stdLogger)
 
@@ -247,7 +858,7 @@ func RunStdioServer(cfg StdioServerConfig) error {
 		in, out := io.Reader(os.Stdin), io.Writer(os.Stdout)
 
 		if cfg.EnableCommandLogging {
-			loggedIO := mcplog.NewIOLogger(in, out, logger)
+			loggedIO := mcplog.NewTrafficLogger(in, out, logger, redactor)
 			in, out = loggedIO, loggedIO
 		}
 		// enable GitHub errors in the context
@@ -272,11 +883,54 @@ func RunStdioServer(cfg StdioServerConfig) error {
 	return nil
 }
 
+// newLogger returns a logger writing to the file at logFilePath, or to stderr if it is empty, in the format and with
+// the rotation configured by cfg, alongside the underlying output so that it can be shared with other loggers.
+func newLogger(logFilePath string, cfg LogConfig) (*slog.Logger, io.Writer, error) {
+	var newHandler func(io.Writer, *slog.HandlerOptions) slog.Handler
+	switch cfg.Format {
+	case "", "text":
+		newHandler = func(w io.Writer, opts *slog.HandlerOptions) slog.Handler { return slog.NewTextHandler(w, opts) }
+	case "json":
+		newHandler = func(w io.Writer, opts *slog.HandlerOptions) slog.Handler { return slog.NewJSONHandler(w, opts) }
+	default:
+		return nil, nil, fmt.Errorf("unknown log format %q, expected text or json", cfg.Format)
+	}
+
+	if logFilePath == "" {
+		return slog.New(newHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelInfo})), os.Stderr, nil
+	}
+
+	file, err := mcplog.OpenRotatingFile(logFilePath, cfg.MaxSize, cfg.MaxBackups)
+	if err != nil {
+		return nil, nil, err
+	}
+	return slog.New(newHandler(file, &slog.HandlerOptions{Level: slog.LevelDebug})), file, nil
+}
+
+// newAuditLogger returns a logger appending audit records to the file configured by cfg, or nil if there is none.
+func newAuditLogger(cfg AuditConfig, logger *slog.Logger) (*audit.Logger, error) {
+	if cfg.Path == "" {
+		return nil, nil
+	}
+
+	file, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	auditLogger, err := audit.NewLogger(file, cfg.Redact, logger)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return auditLogger, nil
+}
+
 type apiHost struct {
 	baseRESTURL *url.URL
 	graphqlURL  *url.URL
 	uploadURL   *url.URL
 	rawURL      *url.URL
+	webURL      *url.URL
 }
 
 func newDotcomHost() (apiHost, error) {
@@ -300,11 +954,17 @@ func newDotcomHost() (apiHost, error) {
 		return apiHost{}, fmt.Errorf("failed to parse dotcom Raw URL: %w", err)
 	}
 
+	webURL, err := url.Parse("https://github.com/")
+	if err != nil {
+		return apiHost{}, fmt.Errorf("failed to parse dotcom Web URL: %w", err)
+	}
+
 	return apiHost{
 		baseRESTURL: baseRestURL,
 		graphqlURL:  gqlURL,
 		uploadURL:   uploadURL,
 		rawURL:      rawURL,
+		webURL:      webURL,
 	}, nil
 }
 
@@ -339,11 +999,17 @@ func newGHECHost(hostname string) (apiHost, error) {
 		return apiHost{}, fmt.Errorf("failed to parse GHEC Raw URL: %w", err)
 	}
 
+	webURL, err := url.Parse(fmt.Sprintf("https://%s/", u.Hostname()))
+	if err != nil {
+		return apiHost{}, fmt.Errorf("failed to parse GHEC Web URL: %w", err)
+	}
+
 	return apiHost{
 		baseRESTURL: restURL,
 		graphqlURL:  gqlURL,
 		uploadURL:   uploadURL,
 		rawURL:      rawURL,
+		webURL:      webURL,
 	}, nil
 }
 
@@ -353,34 +1019,39 @@ func newGHESHost(hostname string) (apiHost, error) {
 		return apiHost{}, fmt.Errorf("failed to parse GHES URL: %w", err)
 	}
 
-	restURL, err := url.Parse(fmt.Sprintf("%s://%s/api/v3/", u.Scheme, u.Hostname()))
+	restURL, err := url.Parse(fmt.Sprintf("%s://%s/api/v3/", u.Scheme, u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHES REST URL: %w", err)
 	}
 
-	gqlURL, err := url.Parse(fmt.Sprintf("%s://%s/api/graphql", u.Scheme, u.Hostname()))
+	gqlURL, err := url.Parse(fmt.Sprintf("%s://%s/api/graphql", u.Scheme, u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHES GraphQL URL: %w", err)
 	}
 
-	uploadURL, err := url.Parse(fmt.Sprintf("%s://%s/api/uploads/", u.Scheme, u.Hostname()))
+	uploadURL, err := url.Parse(fmt.Sprintf("%s://%s/api/uploads/", u.Scheme, u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHES Upload URL: %w", err)
 	}
-	rawURL, err := url.Parse(fmt.Sprintf("%s://%s/raw/", u.Scheme, u.Hostname()))
+	rawURL, err := url.Parse(fmt.Sprintf("%s://%s/raw/", u.Scheme, u.Host))
 	if err != nil {
 		return apiHost{}, fmt.Errorf("failed to parse GHES Raw URL: %w", err)
 	}
 
+	webURL, err := url.Parse(fmt.Sprintf("%s://%s/", u.Scheme, u.Host))
+	if err != nil {
+		return apiHost{}, fmt.Errorf("failed to parse GHES Web URL: %w", err)
+	}
+
 	return apiHost{
 		baseRESTURL: restURL,
 		graphqlURL:  gqlURL,
 		uploadURL:   uploadURL,
 		rawURL:      rawURL,
+		webURL:      webURL,
 	}, nil
 }
 
-// Note that this does not handle ports yet, so development environments are out.
 func parseAPIHost(s string) (apiHost, error) {
 	if s == "" {
 		return newDotcomHost()
@@ -406,24 +1077,93 @@ func parseAPIHost(s string) (apiHost, error) {
 	return newGHESHost(s)
 }
 
-type userAgentTransport struct {
-	transport http.RoundTripper
-	agent     string
+// APIURLs override the URLs derived from the host, e.g. to reach a GitHub Enterprise Server instance behind a
+// reverse proxy, or a fake GitHub API during development. Empty URLs are left as derived from the host.
+type APIURLs struct {
+	// REST API base URL, e.g. https://github.example.com/api/v3/
+	REST string
+
+	// GraphQL API URL, e.g. https://github.example.com/api/graphql
+	GraphQL string
+
+	// Upload API base URL, e.g. https://github.example.com/api/uploads/
+	Upload string
+
+	// Raw content base URL, e.g. https://github.example.com/raw/
+	Raw string
 }
 
-func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
-	req = req.Clone(req.Context())
-	req.Header.Set("User-Agent", t.agent)
-	return t.transport.RoundTrip(req)
+// newAPIHost derives the URLs of the host's APIs, replacing those that are overridden.
+func newAPIHost(host string, overrides APIURLs) (apiHost, error) {
+	h, err := parseAPIHost(host)
+	if err != nil {
+		return apiHost{}, err
+	}
+
+	for _, override := range []struct {
+		name  string
+		value string
+		url   **url.URL
+		dir   bool
+	}{
+		{"REST", overrides.REST, &h.baseRESTURL, true},
+		{"GraphQL", overrides.GraphQL, &h.graphqlURL, false},
+		{"Upload", overrides.Upload, &h.uploadURL, true},
+		{"Raw", overrides.Raw, &h.rawURL, true},
+	} {
+		if override.value == "" {
+			continue
+		}
+		u, err := url.Parse(override.value)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return apiHost{}, fmt.Errorf("%s URL must be an absolute URL: %s", override.name, override.value)
+		}
+		// Base URLs need a trailing slash for paths to be resolved against them
+		if override.dir && !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		*override.url = u
+	}
+	return h, nil
 }
 
-type bearerAuthTransport struct {
+// newAdditionalAPIHosts derives the URLs of the APIs of the additional hosts.
+func newAdditionalAPIHosts(hosts []HostConfig) ([]apiHost, error) {
+	apiHosts := make([]apiHost, 0, len(hosts))
+	for _, host := range hosts {
+		h, err := newAPIHost(host.Host, host.APIURLs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse API host %s: %w", host.Host, err)
+		}
+		apiHosts = append(apiHosts, h)
+	}
+	return apiHosts, nil
+}
+
+// apiOf returns a function telling which of the hosts' APIs a request is made to, the REST API unless it is
+// made to a GraphQL or raw content URL.
+func apiOf(defaultHost apiHost, additionalHosts []apiHost) func(req *http.Request) string {
+	hosts := append([]apiHost{defaultHost}, additionalHosts...)
+	return func(req *http.Request) string {
+		for _, h := range hosts {
+			if req.URL.Host == h.graphqlURL.Host && req.URL.Path == h.graphqlURL.Path {
+				return telemetry.APIGraphQL
+			}
+			if req.URL.Host == h.rawURL.Host && strings.HasPrefix(req.URL.Path, h.rawURL.Path) {
+				return telemetry.APIRaw
+			}
+		}
+		return telemetry.APIREST
+	}
+}
+
+type userAgentTransport struct {
 	transport http.RoundTripper
-	token     string
+	agent     string
 }
 
-func (t *bearerAuthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	req = req.Clone(req.Context())
-	req.Header.Set("Authorization", "Bearer "+t.token)
+	req.Header.Set("User-Agent", t.agent)
 	return t.transport.RoundTrip(req)
 }
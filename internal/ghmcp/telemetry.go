@@ -0,0 +1,103 @@
+package ghmcp
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/telemetry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingConfig configures the export of OpenTelemetry traces.
+type TracingConfig struct {
+	// Endpoint is the URL of the OTLP/HTTP collector traces are sent to, e.g. http://localhost:4318. If empty,
+	// traces are only sent if the standard OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT
+	// environment variable is set.
+	Endpoint string
+}
+
+// metricsPath is where the HTTP server exposes its Prometheus metrics.
+const metricsPath = "/metrics"
+
+// newTelemetry returns the instrumentation of the server, tracing to the collector configured by cfg and
+// registering metrics with registerer, if not nil, along with a function flushing the spans left on shutdown.
+// It returns nil if there is neither a collector nor a registerer.
+func newTelemetry(cfg TracingConfig, version string, registerer prometheus.Registerer, logger *slog.Logger) (*telemetry.Telemetry, func(), error) {
+	tracerProvider, err := newTracerProvider(cfg, version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tracerProvider == nil && registerer == nil {
+		return nil, func() {}, nil
+	}
+
+	// Without a collector, spans are left to the global tracer provider, which drops them unless set
+	var provider trace.TracerProvider
+	shutdown := func() {}
+	if tracerProvider != nil {
+		provider = tracerProvider
+		shutdown = func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := tracerProvider.Shutdown(ctx); err != nil {
+				logger.Error("failed to flush traces", "error", err)
+			}
+		}
+	}
+
+	t, err := telemetry.New(provider, registerer)
+	if err != nil {
+		shutdown()
+		return nil, nil, fmt.Errorf("failed to register metrics: %w", err)
+	}
+	return t, shutdown, nil
+}
+
+// newTracerProvider returns a provider batching spans to the OTLP/HTTP collector configured by cfg or the
+// environment, or nil if there is none.
+func newTracerProvider(cfg TracingConfig, version string) (*sdktrace.TracerProvider, error) {
+	var opts []otlptracehttp.Option
+	switch {
+	case cfg.Endpoint != "":
+		opts = append(opts, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	case os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "":
+		return nil, nil
+	}
+
+	exporter, err := otlptracehttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	// The standard OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES variables take precedence
+	res, err := resource.New(context.Background(),
+		resource.WithSchemaURL(semconv.SchemaURL),
+		resource.WithAttributes(semconv.ServiceName("github-mcp-server"), semconv.ServiceVersion(version)),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe the traced service: %w", err)
+	}
+
+	return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res)), nil
+}
+
+// withMetrics serves the metrics gathered by gatherer on /metrics, which scrapers reach without a GitHub token,
+// and every other request with next.
+func withMetrics(next http.Handler, gatherer prometheus.Gatherer) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	mux.Handle("/", next)
+	return mux
+}
@@ -0,0 +1,105 @@
+package ghmcp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/github/github-mcp-server/pkg/cassette"
+)
+
+// TransportConfig configures how the server connects to GitHub, for every client it uses.
+type TransportConfig struct {
+	// Proxy is the URL of the proxy to send requests through. If empty, the proxy is taken from the
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+	Proxy string
+
+	// CAFile is the path to a PEM encoded bundle of certificate authorities to trust in addition to the
+	// system's, e.g. the internal CA of a GitHub Enterprise Server instance
+	CAFile string
+
+	// ClientCertFile and ClientKeyFile are the paths to the PEM encoded certificate and private key to
+	// present to servers requiring mutual TLS
+	ClientCertFile string
+	ClientKeyFile  string
+
+	// Record is a directory to record every exchange with GitHub into a new cassette in, with its credentials
+	// scrubbed
+	Record string
+
+	// Replay is a directory of cassettes, or a single cassette, to serve the responses to every request to GitHub
+	// from, without network access
+	Replay string
+}
+
+// newBaseTransport builds the transport underlying every client of the server, recording the exchanges it makes or
+// replaying recorded ones if configured.
+func newBaseTransport(cfg TransportConfig) (http.RoundTripper, error) {
+	if cfg.Record != "" && cfg.Replay != "" {
+		return nil, errors.New("cannot both record and replay exchanges with GitHub")
+	}
+	if cfg.Replay != "" {
+		return cassette.NewPlayer(cfg.Replay)
+	}
+
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Record != "" {
+		return cassette.NewRecorder(cfg.Record, transport)
+	}
+	return transport, nil
+}
+
+// newTransport builds the transport connecting to GitHub.
+func newTransport(cfg TransportConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if cfg.Proxy != "" {
+		proxyURL, err := url.Parse(cfg.Proxy)
+		if err != nil || proxyURL.Scheme == "" || proxyURL.Host == "" {
+			return nil, fmt.Errorf("proxy must be an absolute URL: %s", cfg.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	if cfg.CAFile == "" && cfg.ClientCertFile == "" && cfg.ClientKeyFile == "" {
+		return transport, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file: %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.ClientCertFile != "" || cfg.ClientKeyFile != "" {
+		if cfg.ClientCertFile == "" || cfg.ClientKeyFile == "" {
+			return nil, errors.New("both a client certificate and its private key are required for mutual TLS")
+		}
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCertFile, cfg.ClientKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	transport.TLSClientConfig = tlsConfig
+	return transport, nil
+}
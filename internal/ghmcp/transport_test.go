@@ -0,0 +1,126 @@
+package ghmcp
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeClientCertificate generates a self-signed client certificate, writing it and its key to dir.
+func writeClientCertificate(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "github-mcp-server"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return cert, certFile, keyFile
+}
+
+// writeServerCA writes the certificate of a TLS test server to dir, for it to be trusted.
+func writeServerCA(t *testing.T, ts *httptest.Server, dir string) string {
+	t.Helper()
+
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ts.Certificate().Raw}), 0600))
+	return caFile
+}
+
+func Test_NewTransportProxy(t *testing.T) {
+	transport, err := newTransport(TransportConfig{Proxy: "http://proxy.example.com:3128"})
+	require.NoError(t, err)
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+	require.NoError(t, err)
+	proxyURL, err := transport.Proxy(req)
+	require.NoError(t, err)
+	assert.Equal(t, "http://proxy.example.com:3128", proxyURL.String())
+
+	_, err = newTransport(TransportConfig{Proxy: "proxy.example.com"})
+	assert.EqualError(t, err, "proxy must be an absolute URL: proxy.example.com")
+}
+
+func Test_NewTransportCAFile(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer ts.Close()
+	dir := t.TempDir()
+
+	// The test server's certificate is not trusted by default
+	transport, err := newTransport(TransportConfig{})
+	require.NoError(t, err)
+	_, err = (&http.Client{Transport: transport}).Get(ts.URL)
+	require.Error(t, err)
+
+	transport, err = newTransport(TransportConfig{CAFile: writeServerCA(t, ts, dir)})
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Get(ts.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	notPEM := filepath.Join(dir, "not-pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0600))
+	_, err = newTransport(TransportConfig{CAFile: notPEM})
+	assert.ErrorContains(t, err, "no certificates found in CA file")
+}
+
+func Test_NewTransportClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certFile, keyFile := writeClientCertificate(t, dir)
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "github-mcp-server", r.TLS.PeerCertificates[0].Subject.CommonName)
+		w.WriteHeader(http.StatusOK)
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	ts.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs, MinVersion: tls.VersionTLS12}
+	ts.StartTLS()
+	defer ts.Close()
+
+	transport, err := newTransport(TransportConfig{
+		CAFile:         writeServerCA(t, ts, dir),
+		ClientCertFile: certFile,
+		ClientKeyFile:  keyFile,
+	})
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Get(ts.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = newTransport(TransportConfig{ClientCertFile: certFile})
+	assert.EqualError(t, err, "both a client certificate and its private key are required for mutual TLS")
+}
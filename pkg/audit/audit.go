@@ -0,0 +1,269 @@
+// Package audit records the calls to tools that can change data on GitHub as JSON lines, for compliance review.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/github/github-mcp-server/pkg/dryrun"
+	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Redacted replaces the values of redacted arguments, and the GitHub tokens found in the others.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactions are the argument names whose values are always redacted: file contents, which can be large
+// and hold anything, and anything named like a credential.
+var DefaultRedactions = []string{"content", "*token*", "*secret*", "*password*"}
+
+// tokenPattern matches GitHub tokens, wherever they appear in argument values.
+var tokenPattern = regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)
+
+// requestIDHeader is the header GitHub identifies each API request with, for support to trace it.
+const requestIDHeader = "X-GitHub-Request-Id"
+
+// Statuses of tool calls.
+const (
+	StatusSuccess = "success"
+	StatusError   = "error"
+)
+
+// Client identifies the MCP client making a tool call, as it did when initializing its session.
+type Client struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Record is the audit record of a tool call.
+type Record struct {
+	Time       time.Time      `json:"time"`
+	SessionID  string         `json:"session_id,omitempty"`
+	Client     *Client        `json:"client,omitempty"`
+	Tool       string         `json:"tool"`
+	Arguments  map[string]any `json:"arguments,omitempty"`
+	DryRun     bool           `json:"dry_run,omitempty"`
+	Status     string         `json:"status"`
+	Error      string         `json:"error,omitempty"`
+	RequestIDs []string       `json:"github_request_ids,omitempty"`
+	DurationMS int64          `json:"duration_ms"`
+}
+
+// Logger writes a Record for each audited tool call to its sink, one JSON object per line.
+type Logger struct {
+	mu       sync.Mutex
+	w        io.Writer
+	redact   []string
+	errorLog *slog.Logger
+	clients  sync.Map
+
+	// now is overridable for testing
+	now func() time.Time
+}
+
+// NewLogger returns a logger writing to w, redacting the values of the arguments whose names match any of the
+// redact patterns, globs as understood by path.Match, in addition to DefaultRedactions. Records that cannot be
+// written are reported to errorLog, slog.Default() if nil.
+func NewLogger(w io.Writer, redact []string, errorLog *slog.Logger) (*Logger, error) {
+	patterns := make([]string, 0, len(DefaultRedactions)+len(redact))
+	for _, pattern := range append(append([]string(nil), DefaultRedactions...), redact...) {
+		pattern = strings.ToLower(pattern)
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	if errorLog == nil {
+		errorLog = slog.Default()
+	}
+	return &Logger{w: w, redact: patterns, errorLog: errorLog, now: time.Now}, nil
+}
+
+// SetClient remembers the client of a session, as given in its initialize request.
+func (l *Logger) SetClient(sessionID string, info mcp.Implementation) {
+	l.clients.Store(sessionID, &Client{Name: info.Name, Version: info.Version})
+}
+
+// ForgetSession drops what is remembered about a session once it is over.
+func (l *Logger) ForgetSession(sessionID string) {
+	l.clients.Delete(sessionID)
+}
+
+// Log writes the record to the sink.
+func (l *Logger) Log(record Record) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, err = l.w.Write(append(line, '\n'))
+	return err
+}
+
+// ToolHandlerMiddleware returns a middleware recording the calls to the tools for which audited returns true,
+// whether they succeed or not. The GitHub request IDs of a call are those of the responses seen by a Transport,
+// and of the API errors the tool reported in its context.
+func (l *Logger) ToolHandlerMiddleware(audited func(tool string) bool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !audited(request.Params.Name) {
+				return next(ctx, request)
+			}
+
+			start := l.now()
+			r := &recorder{}
+			result, err := next(context.WithValue(ctx, recorderKey{}, r), request)
+
+			record := Record{
+				Time:       start.UTC(),
+				Tool:       request.Params.Name,
+				Arguments:  l.redactArguments(request.GetArguments()),
+				DryRun:     dryrun.IsDryRun(ctx),
+				Status:     StatusSuccess,
+				RequestIDs: r.requestIDs(ctx),
+				DurationMS: l.now().Sub(start).Milliseconds(),
+			}
+			if session := server.ClientSessionFromContext(ctx); session != nil {
+				record.SessionID = session.SessionID()
+				if client, ok := l.clients.Load(record.SessionID); ok {
+					record.Client = client.(*Client)
+				}
+			}
+			switch {
+			case err != nil:
+				record.Status, record.Error = StatusError, err.Error()
+			case result != nil && result.IsError:
+				record.Status, record.Error = StatusError, resultText(result)
+			}
+
+			if logErr := l.Log(record); logErr != nil {
+				l.errorLog.Error("failed to write audit record", "tool", record.Tool, "error", logErr)
+			}
+			return result, err
+		}
+	}
+}
+
+// resultText returns the text of an error result.
+func resultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
+
+// redactArguments returns a copy of args with the values of redacted arguments, at any depth, and GitHub tokens
+// replaced.
+func (l *Logger) redactArguments(args map[string]any) map[string]any {
+	if len(args) == 0 {
+		return nil
+	}
+	return l.redactValue(args).(map[string]any)
+}
+
+func (l *Logger) redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for name, value := range v {
+			if l.isRedacted(name) {
+				redacted[name] = Redacted
+				continue
+			}
+			redacted[name] = l.redactValue(value)
+		}
+		return redacted
+	case []any:
+		redacted := make([]any, len(v))
+		for i, value := range v {
+			redacted[i] = l.redactValue(value)
+		}
+		return redacted
+	case string:
+		return tokenPattern.ReplaceAllString(v, Redacted)
+	default:
+		return v
+	}
+}
+
+func (l *Logger) isRedacted(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range l.redact {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type recorderKey struct{}
+
+// recorder collects the GitHub request IDs of the requests made for a tool call.
+type recorder struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (r *recorder) add(id string) {
+	if id == "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, seen := range r.ids {
+		if seen == id {
+			return
+		}
+	}
+	r.ids = append(r.ids, id)
+}
+
+// requestIDs returns the recorded request IDs, along with those of the API errors in ctx.
+func (r *recorder) requestIDs(ctx context.Context) []string {
+	if apiErrors, err := ghErrors.GetGitHubAPIErrors(ctx); err == nil {
+		for _, apiErr := range apiErrors {
+			if apiErr.Response != nil && apiErr.Response.Response != nil {
+				r.add(apiErr.Response.Header.Get(requestIDHeader))
+			}
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ids
+}
+
+// Transport is an http.RoundTripper recording the GitHub request IDs of the responses to requests made for
+// audited tool calls.
+type Transport struct {
+	// Base is the underlying RoundTripper, http.DefaultTransport if nil.
+	Base http.RoundTripper
+}
+
+// RoundTrip sends req using the base transport, recording the request ID of its response.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if r, ok := req.Context().Value(recorderKey{}).(*recorder); ok {
+		r.add(resp.Header.Get(requestIDHeader))
+	}
+	return resp, nil
+}
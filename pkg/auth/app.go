@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	gogithub "github.com/google/go-github/v74/github"
+)
+
+const (
+	// appJWTLifetime is how long minted JWTs are valid for. GitHub rejects JWTs valid for more than 10 minutes.
+	appJWTLifetime = 9 * time.Minute
+
+	// appJWTClockSkew backdates the JWT issue time to allow for clock drift between us and GitHub.
+	appJWTClockSkew = time.Minute
+
+	// installationTokenRefreshMargin is how long before its expiry an installation token is refreshed,
+	// so that a token handed out is never about to expire mid-request.
+	installationTokenRefreshMargin = 5 * time.Minute
+)
+
+// AppTokenSource is a TokenSource that authenticates as a GitHub App installation. It mints JWTs signed
+// with the App's private key, exchanges them for installation access tokens, and refreshes those tokens
+// before they expire.
+type AppTokenSource struct {
+	appID          int64
+	installationID int64
+	privateKey     *rsa.PrivateKey
+	client         *gogithub.Client
+
+	// now is overridable for testing
+	now func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewAppTokenSource creates a TokenSource for the given App installation, exchanging tokens with the
+// REST API at baseURL through base, or http.DefaultTransport if nil. privateKey is the PEM encoded private
+// key generated for the App.
+func NewAppTokenSource(appID int64, installationID int64, privateKey []byte, baseURL *url.URL, base http.RoundTripper) (*AppTokenSource, error) {
+	if appID == 0 {
+		return nil, errors.New("GitHub App ID is required")
+	}
+	if installationID == 0 {
+		return nil, errors.New("GitHub App installation ID is required")
+	}
+
+	key, err := parseRSAPrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GitHub App private key: %w", err)
+	}
+
+	s := &AppTokenSource{
+		appID:          appID,
+		installationID: installationID,
+		privateKey:     key,
+		now:            time.Now,
+	}
+
+	// The token exchange is authenticated with a JWT rather than an installation token
+	s.client = gogithub.NewClient(&http.Client{
+		Transport: &Transport{Base: base, Source: TokenSourceFunc(s.jwt)},
+	})
+	s.client.BaseURL = baseURL
+
+	return s, nil
+}
+
+// Token returns a valid installation access token, exchanging a new one if the current one is about to expire.
+func (s *AppTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Add(installationTokenRefreshMargin).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	installationToken, _, err := s.client.Apps.CreateInstallationToken(ctx, s.installationID, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create GitHub App installation token: %w", err)
+	}
+
+	s.token = installationToken.GetToken()
+	s.expiresAt = installationToken.GetExpiresAt().Time
+	return s.token, nil
+}
+
+// jwt mints a JWT identifying the App, as described in
+// https://docs.github.com/en/apps/creating-github-apps/authenticating-with-a-github-app/generating-a-json-web-token-jwt-for-a-github-app
+func (s *AppTokenSource) jwt(_ context.Context) (string, error) {
+	now := s.now()
+
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWT header: %w", err)
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal JWT claims: %w", err)
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %w", err)
+	}
+
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parseRSAPrivateKey parses a PEM encoded RSA private key in either PKCS#1 form, as generated by GitHub, or PKCS#8 form.
+func parseRSAPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is of type %T, not RSA", key)
+	}
+	return rsaKey, nil
+}
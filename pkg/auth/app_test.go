@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// verifyJWT checks the signature of an RS256 JWT and returns its claims.
+func verifyJWT(t *testing.T, publicKey *rsa.PublicKey, jwt string) map[string]any {
+	t.Helper()
+
+	parts := strings.Split(jwt, ".")
+	require.Len(t, parts, 3)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest[:], signature))
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims map[string]any
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	return claims
+}
+
+func Test_AppTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	exchanges := 0
+
+	// A stand-in for the GitHub API's installation token exchange
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "/api/v3/app/installations/42/access_tokens", r.URL.Path)
+
+		jwt, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		require.True(t, ok)
+		claims := verifyJWT(t, &key.PublicKey, jwt)
+		assert.Equal(t, "1234", claims["iss"])
+		assert.Equal(t, float64(now.Add(-time.Minute).Unix()), claims["iat"])
+		assert.Equal(t, float64(now.Add(9*time.Minute).Unix()), claims["exp"])
+
+		exchanges++
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"token":      fmt.Sprintf("ghs_%d", exchanges),
+			"expires_at": now.Add(time.Hour).Format(time.RFC3339),
+		})
+	}))
+	defer ts.Close()
+
+	baseURL, err := url.Parse(ts.URL + "/api/v3/")
+	require.NoError(t, err)
+
+	source, err := NewAppTokenSource(1234, 42, privateKey, baseURL, nil)
+	require.NoError(t, err)
+	source.now = func() time.Time { return now }
+
+	token, err := source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_1", token)
+
+	// The token is reused while it is valid
+	now = now.Add(30 * time.Minute)
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_1", token)
+	assert.Equal(t, 1, exchanges)
+
+	// And refreshed shortly before it expires
+	now = now.Add(26 * time.Minute)
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "ghs_2", token)
+	assert.Equal(t, 2, exchanges)
+}
+
+func Test_NewAppTokenSource(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	baseURL, _ := url.Parse("https://api.github.com/")
+
+	tests := []struct {
+		name           string
+		appID          int64
+		installationID int64
+		privateKey     []byte
+		expectedErrMsg string
+	}{
+		{
+			name:           "PKCS#8 private key",
+			appID:          1,
+			installationID: 2,
+			privateKey:     pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8}),
+		},
+		{
+			name:           "missing app ID",
+			installationID: 2,
+			expectedErrMsg: "GitHub App ID is required",
+		},
+		{
+			name:           "missing installation ID",
+			appID:          1,
+			expectedErrMsg: "GitHub App installation ID is required",
+		},
+		{
+			name:           "invalid private key",
+			appID:          1,
+			installationID: 2,
+			privateKey:     []byte("not a key"),
+			expectedErrMsg: "failed to parse GitHub App private key",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewAppTokenSource(tc.appID, tc.installationID, tc.privateKey, baseURL, nil)
+			if tc.expectedErrMsg != "" {
+				require.ErrorContains(t, err, tc.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Package auth provides the credentials the server uses to authenticate with the GitHub API.
+package auth
+
+import (
+	"context"
+	"net/http"
+)
+
+// TokenSource supplies the token used to authenticate a request.
+// Implementations must be safe for concurrent use.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticTokenSource is a TokenSource that always returns the same token, such as a personal access token.
+type StaticTokenSource string
+
+// Token returns the static token.
+func (s StaticTokenSource) Token(_ context.Context) (string, error) {
+	return string(s), nil
+}
+
+// TokenSourceFunc adapts a function to the TokenSource interface.
+type TokenSourceFunc func(ctx context.Context) (string, error)
+
+// Token calls f(ctx).
+func (f TokenSourceFunc) Token(ctx context.Context) (string, error) {
+	return f(ctx)
+}
+
+// Transport is an http.RoundTripper that authenticates each request with a bearer token from its Source.
+type Transport struct {
+	// Base is the underlying RoundTripper, http.DefaultTransport if nil.
+	Base   http.RoundTripper
+	Source TokenSource
+}
+
+// RoundTrip authenticates a copy of req with a fresh token from Source and sends it using the base transport.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	// Like go-github's WithAuthToken, send unauthenticated requests without a token
+	if token != "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
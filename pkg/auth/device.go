@@ -0,0 +1,158 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrDeviceCodeExpired is returned when the user does not authorize the device before its code expires.
+var ErrDeviceCodeExpired = errors.New("device code expired before authorization completed")
+
+// ErrAccessDenied is returned when the user cancels the authorization.
+var ErrAccessDenied = errors.New("authorization was denied by the user")
+
+// DeviceCode is the verification code issued at the start of the device flow.
+type DeviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+// DeviceToken is the access token issued once the user has authorized the device.
+type DeviceToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	Scope       string `json:"scope"`
+}
+
+// DeviceFlow runs the OAuth device authorization flow against a GitHub host, as described in
+// https://docs.github.com/en/apps/oauth-apps/building-oauth-apps/authorizing-oauth-apps#device-flow
+type DeviceFlow struct {
+	// ClientID of the OAuth App to authorize
+	ClientID string
+
+	// Scopes to request
+	Scopes []string
+
+	// WebURL of the GitHub host, e.g. https://github.com/
+	WebURL *url.URL
+
+	// HTTPClient used for requests, http.DefaultClient if nil
+	HTTPClient *http.Client
+
+	// sleep waits between polls, overridable for testing
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// RequestCode starts the flow, returning the code the user must enter at its verification URI.
+func (f *DeviceFlow) RequestCode(ctx context.Context) (*DeviceCode, error) {
+	form := url.Values{
+		"client_id": {f.ClientID},
+		"scope":     {strings.Join(f.Scopes, " ")},
+	}
+
+	var code DeviceCode
+	if err := f.post(ctx, "login/device/code", form, &code); err != nil {
+		return nil, fmt.Errorf("failed to request device code: %w", err)
+	}
+	return &code, nil
+}
+
+// PollToken polls until the user authorizes the device, returning the resulting access token.
+func (f *DeviceFlow) PollToken(ctx context.Context, code *DeviceCode) (*DeviceToken, error) {
+	form := url.Values{
+		"client_id":   {f.ClientID},
+		"device_code": {code.DeviceCode},
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+	}
+
+	interval := time.Duration(code.Interval) * time.Second
+	if interval == 0 {
+		interval = 5 * time.Second
+	}
+
+	sleep := f.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	for {
+		if err := sleep(ctx, interval); err != nil {
+			return nil, err
+		}
+
+		var resp struct {
+			DeviceToken
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+			Interval         int    `json:"interval"`
+		}
+		if err := f.post(ctx, "login/oauth/access_token", form, &resp); err != nil {
+			return nil, fmt.Errorf("failed to poll for access token: %w", err)
+		}
+
+		switch resp.Error {
+		case "":
+			return &resp.DeviceToken, nil
+		case "authorization_pending":
+			continue
+		case "slow_down":
+			// GitHub returns the new minimum interval to use
+			if resp.Interval > 0 {
+				interval = time.Duration(resp.Interval) * time.Second
+			} else {
+				interval += 5 * time.Second
+			}
+		case "expired_token":
+			return nil, ErrDeviceCodeExpired
+		case "access_denied":
+			return nil, ErrAccessDenied
+		default:
+			return nil, fmt.Errorf("failed to obtain access token: %s: %s", resp.Error, resp.ErrorDescription)
+		}
+	}
+}
+
+// post sends a form to the given path of the host's web URL and decodes the JSON response into v.
+func (f *DeviceFlow) post(ctx context.Context, path string, form url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.WebURL.JoinPath(path).String(), strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	client := f.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
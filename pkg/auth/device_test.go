@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DeviceFlow(t *testing.T) {
+	tests := []struct {
+		name              string
+		pollResponses     []map[string]any
+		expectedToken     string
+		expectedErr       error
+		expectedIntervals []time.Duration
+	}{
+		{
+			name: "authorized after pending and slow down",
+			pollResponses: []map[string]any{
+				{"error": "authorization_pending"},
+				{"error": "slow_down", "interval": 10},
+				{"access_token": "gho_abc", "token_type": "bearer", "scope": "repo,read:org"},
+			},
+			expectedToken:     "gho_abc",
+			expectedIntervals: []time.Duration{5 * time.Second, 5 * time.Second, 10 * time.Second},
+		},
+		{
+			name:              "access denied",
+			pollResponses:     []map[string]any{{"error": "access_denied"}},
+			expectedErr:       ErrAccessDenied,
+			expectedIntervals: []time.Duration{5 * time.Second},
+		},
+		{
+			name:              "code expired",
+			pollResponses:     []map[string]any{{"error": "expired_token"}},
+			expectedErr:       ErrDeviceCodeExpired,
+			expectedIntervals: []time.Duration{5 * time.Second},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			polls := 0
+			mux := http.NewServeMux()
+			mux.HandleFunc("POST /login/device/code", func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, r.ParseForm())
+				assert.Equal(t, "client-id", r.Form.Get("client_id"))
+				assert.Equal(t, "repo read:org", r.Form.Get("scope"))
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"device_code":      "device-code",
+					"user_code":        "ABCD-1234",
+					"verification_uri": "https://github.com/login/device",
+					"expires_in":       900,
+					"interval":         5,
+				})
+			})
+			mux.HandleFunc("POST /login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, r.ParseForm())
+				assert.Equal(t, "device-code", r.Form.Get("device_code"))
+				assert.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", r.Form.Get("grant_type"))
+				_ = json.NewEncoder(w).Encode(tc.pollResponses[polls])
+				polls++
+			})
+			ts := httptest.NewServer(mux)
+			defer ts.Close()
+
+			webURL, _ := url.Parse(ts.URL + "/")
+			var intervals []time.Duration
+			flow := &DeviceFlow{
+				ClientID: "client-id",
+				Scopes:   []string{"repo", "read:org"},
+				WebURL:   webURL,
+				sleep: func(_ context.Context, d time.Duration) error {
+					intervals = append(intervals, d)
+					return nil
+				},
+			}
+
+			code, err := flow.RequestCode(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "ABCD-1234", code.UserCode)
+
+			token, err := flow.PollToken(context.Background(), code)
+			assert.Equal(t, tc.expectedIntervals, intervals)
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedToken, token.AccessToken)
+			assert.Equal(t, "repo,read:org", token.Scope)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrCredentialNotFound is returned when no credential is stored for a host.
+var ErrCredentialNotFound = errors.New("no stored credential for host")
+
+// Credential is a token stored for a GitHub host.
+type Credential struct {
+	Host      string    `json:"host"`
+	Token     string    `json:"token"`
+	Scopes    []string  `json:"scopes,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CredentialStore keeps one credential file per GitHub host in a directory only readable by the current user.
+type CredentialStore struct {
+	dir string
+}
+
+// NewCredentialStore creates a store keeping credentials in dir.
+func NewCredentialStore(dir string) *CredentialStore {
+	return &CredentialStore{dir: dir}
+}
+
+// DefaultCredentialStore creates a store in the user's configuration directory,
+// e.g. ~/.config/github-mcp-server/credentials on Linux.
+func DefaultCredentialStore() (*CredentialStore, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user config directory: %w", err)
+	}
+	return NewCredentialStore(filepath.Join(configDir, "github-mcp-server", "credentials")), nil
+}
+
+// Load returns the credential stored for host, or ErrCredentialNotFound.
+func (s *CredentialStore) Load(host string) (*Credential, error) {
+	data, err := os.ReadFile(s.path(host))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrCredentialNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credential: %w", err)
+	}
+
+	var credential Credential
+	if err := json.Unmarshal(data, &credential); err != nil {
+		return nil, fmt.Errorf("failed to parse credential: %w", err)
+	}
+	return &credential, nil
+}
+
+// Save stores credential for its host, replacing any existing one.
+func (s *CredentialStore) Save(credential *Credential) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("failed to create credential directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(credential, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal credential: %w", err)
+	}
+
+	// Write to a temporary file first so that a failed write never leaves a truncated credential behind
+	tmp, err := os.CreateTemp(s.dir, ".credential-*")
+	if err != nil {
+		return fmt.Errorf("failed to create credential file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write credential: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write credential: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path(credential.Host)); err != nil {
+		return fmt.Errorf("failed to write credential: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the credential stored for host, or returns ErrCredentialNotFound.
+func (s *CredentialStore) Delete(host string) error {
+	err := os.Remove(s.path(host))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrCredentialNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete credential: %w", err)
+	}
+	return nil
+}
+
+// path returns the file a host's credential is kept in. Ports are kept apart from the hostname with
+// an underscore, as colons are not allowed in file names on every platform.
+func (s *CredentialStore) path(host string) string {
+	return filepath.Join(s.dir, strings.ReplaceAll(host, ":", "_")+".json")
+}
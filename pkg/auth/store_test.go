@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CredentialStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "credentials")
+	store := NewCredentialStore(dir)
+
+	_, err := store.Load("github.com")
+	require.ErrorIs(t, err, ErrCredentialNotFound)
+
+	credential := &Credential{
+		Host:      "ghes.example.com:8443",
+		Token:     "gho_abc",
+		Scopes:    []string{"repo"},
+		CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	require.NoError(t, store.Save(credential))
+
+	loaded, err := store.Load("ghes.example.com:8443")
+	require.NoError(t, err)
+	assert.Equal(t, credential, loaded)
+
+	// Credentials of other hosts are kept apart
+	_, err = store.Load("github.com")
+	require.ErrorIs(t, err, ErrCredentialNotFound)
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dir, "ghes.example.com_8443.json"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	require.NoError(t, store.Delete("ghes.example.com:8443"))
+	_, err = store.Load("ghes.example.com:8443")
+	require.ErrorIs(t, err, ErrCredentialNotFound)
+	require.ErrorIs(t, store.Delete("ghes.example.com:8443"), ErrCredentialNotFound)
+}
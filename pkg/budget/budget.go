@@ -0,0 +1,241 @@
+// Package budget bounds the size of tool results, so that large diffs, files and search results do not fill the
+// model's context. Results beyond the budget are truncated, and their remainder kept for a short while for the
+// get_more_output tool to page through.
+package budget
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// BytesPerToken is the rough number of bytes of text making up a token, used to express budgets in tokens.
+const BytesPerToken = 4
+
+// DefaultTTL is how long the remainder of a truncated result is kept.
+const DefaultTTL = 10 * time.Minute
+
+// maxRemainders bounds the number of remainders kept at once, the oldest being dropped first.
+const maxRemainders = 256
+
+// MoreOutputTool is the name of the tool paging through the remainder of truncated results.
+const MoreOutputTool = "get_more_output"
+
+// maxBytesKey is the context key of the budget of a tool call's result.
+type maxBytesKey struct{}
+
+// MaxBytes returns the budget of the result of the tool call made with ctx, if results are truncated, for tools
+// to stop gathering what would be truncated anyway.
+func MaxBytes(ctx context.Context) (int, bool) {
+	maxBytes, ok := ctx.Value(maxBytesKey{}).(int)
+	return maxBytes, ok
+}
+
+// Budget truncates the text of tool results beyond a maximum size, keeping the remainder to be paged through by
+// the session the result was returned to.
+type Budget struct {
+	maxBytes int
+	ttl      time.Duration
+	now      func() time.Time
+
+	mu         sync.Mutex
+	remainders map[string]*remainder
+}
+
+// remainder is the text left out of a truncated result.
+type remainder struct {
+	sessionID string
+	text      string
+	expires   time.Time
+}
+
+// New returns a Budget truncating tool results beyond maxBytes bytes of text.
+func New(maxBytes int) *Budget {
+	return &Budget{
+		maxBytes:   maxBytes,
+		ttl:        DefaultTTL,
+		now:        time.Now,
+		remainders: make(map[string]*remainder),
+	}
+}
+
+// ToolHandlerMiddleware truncates the results of tool calls beyond the budget, including those of get_more_output,
+// so that the remainder of a result is paged through one budget at a time.
+func (b *Budget) ToolHandlerMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		result, err := next(context.WithValue(ctx, maxBytesKey{}, b.maxBytes), request)
+		if err != nil || result == nil {
+			return result, err
+		}
+		if err := b.truncate(sessionID(ctx), result); err != nil {
+			return mcp.NewToolResultErrorFromErr("failed to truncate the result", err), nil
+		}
+		return result, nil
+	}
+}
+
+// Remainder returns the remainder of a truncated result given the continuation token at its end, if it was
+// returned to the session and has not expired. Each remainder is only returned once.
+func (b *Budget) Remainder(sessionID, token string) (string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	r, ok := b.remainders[token]
+	if !ok || r.sessionID != sessionID {
+		return "", false
+	}
+	delete(b.remainders, token)
+	if b.now().After(r.expires) {
+		return "", false
+	}
+	return r.text, true
+}
+
+// ForgetSession drops the remainders kept for a session once it is over.
+func (b *Budget) ForgetSession(sessionID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for token, r := range b.remainders {
+		if r.sessionID == sessionID {
+			delete(b.remainders, token)
+		}
+	}
+}
+
+// truncate cuts the text contents of result, including embedded text resources, beyond the budget, replacing
+// what is left out with a note giving the continuation token to get it with. Other contents, such as images, are
+// kept as they are, and so is structured content, which tools declaring an output schema must return.
+func (b *Budget) truncate(sessionID string, result *mcp.CallToolResult) error {
+	var (
+		used     int
+		rest     []string
+		contents = make([]mcp.Content, 0, len(result.Content)+1)
+	)
+	for _, content := range result.Content {
+		text, replace, ok := textOf(content)
+		switch {
+		case !ok:
+			contents = append(contents, content)
+		case rest != nil:
+			rest = append(rest, text)
+		case used+len(text) <= b.maxBytes:
+			used += len(text)
+			contents = append(contents, content)
+		default:
+			at := boundary(text, b.maxBytes-used)
+			used += at
+			rest = append(rest, text[at:])
+			if at > 0 {
+				contents = append(contents, replace(text[:at]))
+			}
+		}
+	}
+	if rest == nil {
+		return nil
+	}
+
+	remaining := strings.Join(rest, "\n")
+	token, err := b.keep(sessionID, remaining)
+	if err != nil {
+		return err
+	}
+	result.Content = append(contents, mcp.NewTextContent(fmt.Sprintf(
+		"[Output truncated after %d of %d bytes. Call %s with continuation_token %q within %.0f minutes to get the rest.]",
+		used, used+len(remaining), MoreOutputTool, token, b.ttl.Minutes(),
+	)))
+	return nil
+}
+
+// textOf returns the text of content, if it is text or an embedded text resource, along with a function making
+// the same content with another text.
+func textOf(content mcp.Content) (string, func(string) mcp.Content, bool) {
+	switch content := content.(type) {
+	case mcp.TextContent:
+		return content.Text, func(text string) mcp.Content {
+			content.Text = text
+			return content
+		}, true
+	case mcp.EmbeddedResource:
+		var resource mcp.TextResourceContents
+		switch r := content.Resource.(type) {
+		case mcp.TextResourceContents:
+			resource = r
+		case *mcp.TextResourceContents:
+			resource = *r
+		default:
+			return "", nil, false
+		}
+		return resource.Text, func(text string) mcp.Content {
+			resource.Text = text
+			content.Resource = resource
+			return content
+		}, true
+	}
+	return "", nil, false
+}
+
+// keep stores the remainder of a result for a session, returning its continuation token.
+func (b *Budget) keep(sessionID, text string) (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	var oldest string
+	for t, r := range b.remainders {
+		if now.After(r.expires) {
+			delete(b.remainders, t)
+			continue
+		}
+		if oldest == "" || r.expires.Before(b.remainders[oldest].expires) {
+			oldest = t
+		}
+	}
+	if len(b.remainders) >= maxRemainders {
+		delete(b.remainders, oldest)
+	}
+
+	id := hex.EncodeToString(token)
+	b.remainders[id] = &remainder{sessionID: sessionID, text: text, expires: now.Add(b.ttl)}
+	return id, nil
+}
+
+// boundary returns where to cut text to keep at most maxBytes of it: after the last line break, or failing that
+// the last space, in the second half of the budget, or else at the last whole character.
+func boundary(text string, maxBytes int) int {
+	if maxBytes <= 0 {
+		return 0
+	}
+	if i := strings.LastIndexByte(text[:maxBytes], '\n'); i >= maxBytes/2 {
+		return i + 1
+	}
+	if i := strings.LastIndexAny(text[:maxBytes], " \t"); i >= maxBytes/2 {
+		return i + 1
+	}
+	at := maxBytes
+	for at > 0 && !utf8.RuneStart(text[at]) {
+		at--
+	}
+	return at
+}
+
+// sessionID returns the ID of the session a call is made in, if any.
+func sessionID(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
@@ -0,0 +1,198 @@
+package budget
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSession is a client session identified by its ID alone.
+type testSession struct{ id string }
+
+func (s testSession) SessionID() string                                   { return s.id }
+func (s testSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s testSession) Initialize()                                         {}
+func (s testSession) Initialized() bool                                   { return true }
+
+var tokenPattern = regexp.MustCompile(`continuation_token "([0-9a-f]+)"`)
+
+// call calls a handler returning result through the middleware of b, in the given session.
+func call(t *testing.T, b *Budget, sessionID string, result *mcp.CallToolResult) *mcp.CallToolResult {
+	t.Helper()
+
+	ctx := server.NewMCPServer("test", "1.0").WithContext(context.Background(), testSession{id: sessionID})
+	handler := b.ToolHandlerMiddleware(func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return result, nil
+	})
+	result, err := handler(ctx, mcp.CallToolRequest{})
+	require.NoError(t, err)
+	return result
+}
+
+// continuationToken returns the continuation token given in the note ending a truncated result.
+func continuationToken(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+
+	note := result.Content[len(result.Content)-1].(mcp.TextContent).Text
+	match := tokenPattern.FindStringSubmatch(note)
+	require.NotNil(t, match, note)
+	return match[1]
+}
+
+func Test_ToolHandlerMiddleware(t *testing.T) {
+	image := mcp.NewImageContent("aW1hZ2U=", "image/png")
+
+	tests := []struct {
+		name              string
+		maxBytes          int
+		content           []mcp.Content
+		expectedContent   []mcp.Content
+		expectedRemainder string
+	}{
+		{
+			name:            "within budget",
+			maxBytes:        16,
+			content:         []mcp.Content{mcp.NewTextContent("line 1\nline 2\n")},
+			expectedContent: []mcp.Content{mcp.NewTextContent("line 1\nline 2\n")},
+		},
+		{
+			name:              "cut after a line",
+			maxBytes:          16,
+			content:           []mcp.Content{mcp.NewTextContent("line 1\nline 2\nline 3\n")},
+			expectedContent:   []mcp.Content{mcp.NewTextContent("line 1\nline 2\n")},
+			expectedRemainder: "line 3\n",
+		},
+		{
+			name:              "cut after a word",
+			maxBytes:          16,
+			content:           []mcp.Content{mcp.NewTextContent("a sentence much longer than the budget")},
+			expectedContent:   []mcp.Content{mcp.NewTextContent("a sentence much ")},
+			expectedRemainder: "longer than the budget",
+		},
+		{
+			name:              "cut between characters",
+			maxBytes:          4,
+			content:           []mcp.Content{mcp.NewTextContent("ééé")},
+			expectedContent:   []mcp.Content{mcp.NewTextContent("éé")},
+			expectedRemainder: "é",
+		},
+		{
+			name:              "embedded text resource",
+			maxBytes:          16,
+			content:           []mcp.Content{mcp.NewEmbeddedResource(mcp.TextResourceContents{URI: "repo://octocat/hello-world/contents/README.md", Text: "line 1\nline 2\nline 3\n"})},
+			expectedContent:   []mcp.Content{mcp.NewEmbeddedResource(mcp.TextResourceContents{URI: "repo://octocat/hello-world/contents/README.md", Text: "line 1\nline 2\n"})},
+			expectedRemainder: "line 3\n",
+		},
+		{
+			name:              "several contents",
+			maxBytes:          16,
+			content:           []mcp.Content{mcp.NewTextContent("line 1\n"), image, mcp.NewTextContent("line 2\nline 3\n"), mcp.NewTextContent("line 4\n")},
+			expectedContent:   []mcp.Content{mcp.NewTextContent("line 1\n"), image, mcp.NewTextContent("line 2\n")},
+			expectedRemainder: "line 3\n\nline 4\n",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			b := New(tc.maxBytes)
+
+			result := call(t, b, "session", &mcp.CallToolResult{Content: tc.content})
+			if tc.expectedRemainder == "" {
+				assert.Equal(t, tc.expectedContent, result.Content)
+				assert.Empty(t, b.remainders)
+				return
+			}
+
+			require.Len(t, result.Content, len(tc.expectedContent)+1)
+			assert.Equal(t, tc.expectedContent, result.Content[:len(tc.expectedContent)])
+			token := continuationToken(t, result)
+
+			// The remainder is only given to the session, and only once
+			_, ok := b.Remainder("other", token)
+			assert.False(t, ok)
+			remainder, ok := b.Remainder("session", token)
+			require.True(t, ok)
+			assert.Equal(t, tc.expectedRemainder, remainder)
+			_, ok = b.Remainder("session", token)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func Test_StructuredContentKept(t *testing.T) {
+	b := New(8)
+	structured := map[string]any{"items": []string{"line 1", "line 2", "line 3"}}
+
+	// Only the text is truncated, as structured content must match the output schema of the tool
+	result := call(t, b, "session", mcp.NewToolResultStructured(structured, "line 1\nline 2\nline 3\n"))
+	require.Len(t, result.Content, 2)
+	assert.Equal(t, mcp.NewTextContent("line 1\n"), result.Content[0])
+	assert.Equal(t, structured, result.StructuredContent)
+}
+
+func Test_PagingThroughRemainder(t *testing.T) {
+	b := New(8)
+	text := strings.Repeat("line\n", 5)
+
+	// The remainder returned by get_more_output is itself truncated to the budget
+	var pages []string
+	result := call(t, b, "session", mcp.NewToolResultText(text))
+	for len(result.Content) > 1 {
+		pages = append(pages, result.Content[0].(mcp.TextContent).Text)
+		remainder, ok := b.Remainder("session", continuationToken(t, result))
+		require.True(t, ok)
+		result = call(t, b, "session", mcp.NewToolResultText(remainder))
+	}
+	pages = append(pages, result.Content[0].(mcp.TextContent).Text)
+
+	assert.Equal(t, []string{"line\n", "line\n", "line\n", "line\n", "line\n"}, pages)
+	assert.Empty(t, b.remainders)
+}
+
+func Test_RemainderExpiry(t *testing.T) {
+	now := time.Now()
+	b := New(4)
+	b.now = func() time.Time { return now }
+
+	token := continuationToken(t, call(t, b, "session", mcp.NewToolResultText("expired soon")))
+	now = now.Add(DefaultTTL + time.Second)
+	_, ok := b.Remainder("session", token)
+	assert.False(t, ok)
+
+	// Only so many remainders are kept, the oldest being dropped first
+	first := continuationToken(t, call(t, b, "session", mcp.NewToolResultText("first result")))
+	for range maxRemainders {
+		now = now.Add(time.Millisecond)
+		call(t, b, "session", mcp.NewToolResultText("later result"))
+	}
+	assert.Len(t, b.remainders, maxRemainders)
+	_, ok = b.Remainder("session", first)
+	assert.False(t, ok)
+
+	// Sessions are forgotten once over
+	b.ForgetSession("session")
+	assert.Empty(t, b.remainders)
+}
+
+func Test_MaxBytes(t *testing.T) {
+	_, ok := MaxBytes(context.Background())
+	assert.False(t, ok)
+
+	// Tools called through the middleware are told their budget
+	var maxBytes int
+	handler := New(100).ToolHandlerMiddleware(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		maxBytes, ok = MaxBytes(ctx)
+		return mcp.NewToolResultText("done"), nil
+	})
+	_, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 100, maxBytes)
+}
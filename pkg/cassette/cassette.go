@@ -0,0 +1,311 @@
+// Package cassette records the HTTP exchanges of the server with GitHub into cassette files, and replays them
+// without network access, to reproduce a session offline or to test tools against real responses.
+//
+// A cassette is a file of JSON lines, one Interaction per line, in the order the requests were made.
+package cassette
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Extension is the extension of cassette files.
+const Extension = ".jsonl"
+
+// Redacted replaces the credentials scrubbed from recorded interactions.
+const Redacted = "[REDACTED]"
+
+// scrubbedHeaders are the headers whose values are redacted from recorded interactions.
+var scrubbedHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// tokenPattern matches GitHub tokens, such as the installation tokens of GitHub Apps in the responses creating them.
+var tokenPattern = regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)
+
+// Request is a recorded HTTP request.
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitempty"`
+}
+
+// Interaction is a request and the response it got.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Body is the body of a request or response, kept as text if it is valid UTF-8 and base64 encoded otherwise, such
+// as the zip archives of workflow logs.
+type Body []byte
+
+// MarshalJSON encodes the body as a string, prefixed with "base64:" if it is binary.
+func (b Body) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) && !strings.HasPrefix(string(b), "base64:") {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal("base64:" + base64.StdEncoding.EncodeToString(b))
+}
+
+// UnmarshalJSON decodes a body encoded by MarshalJSON.
+func (b *Body) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	if encoded, ok := strings.CutPrefix(s, "base64:"); ok {
+		decoded, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("invalid base64 body: %w", err)
+		}
+		*b = decoded
+		return nil
+	}
+	*b = Body(s)
+	return nil
+}
+
+// Recorder is a transport recording the exchanges made through Base into a cassette, with their credentials
+// scrubbed.
+type Recorder struct {
+	Base http.RoundTripper
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewRecorder returns a Recorder writing to a new cassette in dir, named after the current time. The directory is
+// created if needed.
+func NewRecorder(dir string, base http.RoundTripper) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	name := filepath.Join(dir, time.Now().UTC().Format("20060102T150405.000000000Z")+Extension)
+	file, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cassette: %w", err)
+	}
+	return &Recorder{Base: base, file: file}, nil
+}
+
+// Path returns the path of the cassette recorded to.
+func (r *Recorder) Path() string {
+	return r.file.Name()
+}
+
+// RoundTrip sends the request through Base and records it along with its response. Requests that get no response,
+// such as those failing to connect, are not recorded.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The body is read on a copy of the request, round trippers being required to leave theirs unchanged
+	req = req.Clone(req.Context())
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	resp, err := r.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	interaction := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Header: scrubHeader(req.Header),
+			Body:   scrubBody(reqBody),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     scrubHeader(resp.Header),
+			Body:       scrubBody(respBody),
+		},
+	}
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode interaction: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.file.Write(append(line, '\n')); err != nil {
+		return nil, fmt.Errorf("failed to record interaction: %w", err)
+	}
+	return resp, nil
+}
+
+// Close closes the cassette.
+func (r *Recorder) Close() error {
+	return r.file.Close()
+}
+
+// readBody reads all of the body, replacing it with a reader of what was read.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(*body)
+	_ = (*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// scrubHeader returns a copy of header with the values of credential headers redacted.
+func scrubHeader(header http.Header) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+	scrubbed := header.Clone()
+	for _, name := range scrubbedHeaders {
+		if _, ok := scrubbed[name]; ok {
+			scrubbed.Set(name, Redacted)
+		}
+	}
+	return scrubbed
+}
+
+// scrubBody returns body with the GitHub tokens in it redacted.
+func scrubBody(body []byte) Body {
+	return tokenPattern.ReplaceAll(body, []byte(Redacted))
+}
+
+// Player is a transport serving the responses recorded in cassettes, without network access. Each request is
+// answered with the first response recorded for the same method, URL and body that was not served yet, in the order
+// they were recorded, or the last of them once all were served. Requests nothing was recorded for fail.
+type Player struct {
+	mu sync.Mutex
+	// interactions holds the recorded responses not served yet, keyed by request
+	interactions map[string][]Response
+	// served holds the last response served, keyed by request
+	served map[string]Response
+}
+
+// NewPlayer returns a Player serving the interactions of the cassettes in dir, in the lexical order of their names,
+// or of a single cassette if dir is the path of one.
+func NewPlayer(dir string) (*Player, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassettes: %w", err)
+	}
+	paths := []string{dir}
+	if info.IsDir() {
+		paths, err = filepath.Glob(filepath.Join(dir, "*"+Extension))
+		if err != nil {
+			return nil, err
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("no cassettes found in %s", dir)
+		}
+		sort.Strings(paths)
+	}
+
+	p := &Player{interactions: make(map[string][]Response), served: make(map[string]Response)}
+	for _, path := range paths {
+		interactions, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, interaction := range interactions {
+			key := requestKey(interaction.Request.Method, interaction.Request.URL, interaction.Request.Body)
+			p.interactions[key] = append(p.interactions[key], interaction.Response)
+		}
+	}
+	return p, nil
+}
+
+// Load returns the interactions recorded in the cassette at path.
+func Load(path string) ([]Interaction, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cassette: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var interactions []Interaction
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1<<30)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return nil, fmt.Errorf("invalid interaction on line %d of cassette %s: %w", line, path, err)
+		}
+		interactions = append(interactions, interaction)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read cassette %s: %w", path, err)
+	}
+	return interactions, nil
+}
+
+// RoundTrip answers the request with a recorded response.
+func (p *Player) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	key := requestKey(req.Method, req.URL.String(), scrubBody(body))
+
+	p.mu.Lock()
+	recorded, ok := p.served[key]
+	if queue := p.interactions[key]; len(queue) > 0 {
+		recorded, ok = queue[0], true
+		p.interactions[key] = queue[1:]
+		p.served[key] = recorded
+	}
+	p.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, req.URL)
+	}
+
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// requestKey identifies the requests a recorded response answers.
+func requestKey(method, url string, body []byte) string {
+	return method + " " + url + "\n" + string(body)
+}
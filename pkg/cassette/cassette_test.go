@@ -0,0 +1,130 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exchange is a request made through a transport, and the response expected for it.
+type exchange struct {
+	method         string
+	path           string
+	body           string
+	expectedStatus int
+	expectedBody   string
+}
+
+// roundTrip makes the request of e through transport, returning the status and body of the response.
+func roundTrip(t *testing.T, transport http.RoundTripper, baseURL string, e exchange) (int, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(e.method, baseURL+e.path, strings.NewReader(e.body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer ghp_"+strings.Repeat("a", 36))
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func Test_RecordAndReplay(t *testing.T) {
+	installationToken := "ghs_" + strings.Repeat("b", 36)
+	zip := string([]byte{'P', 'K', 3, 4, 0xff, 0xfe})
+
+	// The issue is closed between the first and second time it is requested
+	issueRequests := 0
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/octocat/hello-world/issues/42":
+			issueRequests++
+			if issueRequests == 1 {
+				_, _ = w.Write([]byte(`{"number":42,"state":"open"}`))
+			} else {
+				_, _ = w.Write([]byte(`{"number":42,"state":"closed"}`))
+			}
+		case "/graphql":
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), "viewer") {
+				_, _ = w.Write([]byte(`{"data":"viewer"}`))
+			} else {
+				_, _ = w.Write([]byte(`{"data":"repository"}`))
+			}
+		case "/app/installations/1/access_tokens":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"token":"` + installationToken + `"}`))
+		case "/logs":
+			w.Header().Set("Set-Cookie", "session=secret")
+			_, _ = w.Write([]byte(zip))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(api.Close)
+
+	exchanges := []exchange{
+		{method: http.MethodGet, path: "/repos/octocat/hello-world/issues/42", expectedStatus: http.StatusOK, expectedBody: `{"number":42,"state":"open"}`},
+		{method: http.MethodPost, path: "/graphql", body: `{"query":"{viewer{login}}"}`, expectedStatus: http.StatusOK, expectedBody: `{"data":"viewer"}`},
+		{method: http.MethodPost, path: "/graphql", body: `{"query":"{repository{name}}"}`, expectedStatus: http.StatusOK, expectedBody: `{"data":"repository"}`},
+		{method: http.MethodGet, path: "/repos/octocat/hello-world/issues/42", expectedStatus: http.StatusOK, expectedBody: `{"number":42,"state":"closed"}`},
+		{method: http.MethodPost, path: "/app/installations/1/access_tokens", expectedStatus: http.StatusCreated, expectedBody: `{"token":"[REDACTED]"}`},
+		{method: http.MethodGet, path: "/logs", expectedStatus: http.StatusOK, expectedBody: zip},
+		{method: http.MethodGet, path: "/missing", expectedStatus: http.StatusNotFound},
+	}
+
+	dir := filepath.Join(t.TempDir(), "cassettes")
+	recorder, err := NewRecorder(dir, http.DefaultTransport)
+	require.NoError(t, err)
+	for _, e := range exchanges {
+		status, body := roundTrip(t, recorder, api.URL, e)
+		assert.Equal(t, e.expectedStatus, status)
+		if e.path != "/app/installations/1/access_tokens" {
+			assert.Equal(t, e.expectedBody, body)
+		}
+	}
+	require.NoError(t, recorder.Close())
+
+	// Credentials are scrubbed from the cassette
+	data, err := os.ReadFile(recorder.Path())
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "ghp_")
+	assert.NotContains(t, string(data), installationToken)
+	assert.NotContains(t, string(data), "session=secret")
+	interactions, err := Load(recorder.Path())
+	require.NoError(t, err)
+	require.Len(t, interactions, len(exchanges))
+	assert.Equal(t, []string{Redacted}, interactions[0].Request.Header.Values("Authorization"))
+
+	// Responses are replayed in the order they were recorded, without the API
+	api.Close()
+	player, err := NewPlayer(dir)
+	require.NoError(t, err)
+	for _, e := range exchanges {
+		status, body := roundTrip(t, player, api.URL, e)
+		assert.Equal(t, e.expectedStatus, status)
+		assert.Equal(t, e.expectedBody, body)
+	}
+
+	// Once every recorded response was served, the last one is served again
+	status, body := roundTrip(t, player, api.URL, exchanges[0])
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, `{"number":42,"state":"closed"}`, body)
+
+	// Requests nothing was recorded for fail
+	req, err := http.NewRequest(http.MethodGet, api.URL+"/user", nil)
+	require.NoError(t, err)
+	_, err = player.RoundTrip(req)
+	assert.ErrorContains(t, err, "no recorded response for GET "+api.URL+"/user")
+
+	_, err = NewPlayer(t.TempDir())
+	assert.ErrorContains(t, err, "no cassettes found in")
+}
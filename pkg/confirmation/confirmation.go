@@ -0,0 +1,97 @@
+// Package confirmation asks the user to confirm tool calls through MCP elicitation before they are made, as not
+// every client acts on the tools' destructive annotations.
+package confirmation
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/github/github-mcp-server/pkg/dryrun"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// confirmField is the boolean field of the form shown to the user.
+const confirmField = "confirm"
+
+// Confirmer asks the user to confirm the calls to some tools, in the sessions of clients supporting elicitation.
+// Calls made in other sessions go ahead unconfirmed.
+type Confirmer struct {
+	confirmed func(tool string) bool
+	summarize func(tool string, args map[string]any) string
+
+	// sessions holds the IDs of the sessions whose client supports elicitation
+	sessions sync.Map
+}
+
+// New returns a Confirmer asking for confirmation of the calls to the tools for which confirmed returns true,
+// describing their effect to the user with summarize.
+func New(confirmed func(tool string) bool, summarize func(tool string, args map[string]any) string) *Confirmer {
+	return &Confirmer{confirmed: confirmed, summarize: summarize}
+}
+
+// SetClientCapabilities remembers whether the client of a session supports elicitation, as declared in its
+// initialize request.
+func (c *Confirmer) SetClientCapabilities(sessionID string, capabilities mcp.ClientCapabilities) {
+	if capabilities.Elicitation != nil {
+		c.sessions.Store(sessionID, true)
+	}
+}
+
+// ForgetSession drops what is remembered about a session once it is over.
+func (c *Confirmer) ForgetSession(sessionID string) {
+	c.sessions.Delete(sessionID)
+}
+
+// ToolHandlerMiddleware asks the user to confirm the calls to the confirmed tools, rejecting those they do not
+// confirm. Dry runs change nothing, so they need no confirmation.
+func (c *Confirmer) ToolHandlerMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if !c.confirmed(request.Params.Name) || dryrun.IsDryRun(ctx) {
+			return next(ctx, request)
+		}
+		session, ok := server.ClientSessionFromContext(ctx).(server.SessionWithElicitation)
+		if !ok {
+			return next(ctx, request)
+		}
+		if _, ok := c.sessions.Load(session.SessionID()); !ok {
+			return next(ctx, request)
+		}
+
+		summary := c.summarize(request.Params.Name, request.GetArguments())
+		result, err := session.RequestElicitation(ctx, mcp.ElicitationRequest{
+			Params: mcp.ElicitationParams{
+				Message: summary,
+				RequestedSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						confirmField: map[string]any{
+							"type":        "boolean",
+							"title":       "Confirm",
+							"description": summary,
+						},
+					},
+					"required": []string{confirmField},
+				},
+			},
+		})
+		if err != nil {
+			return mcp.NewToolResultErrorFromErr(fmt.Sprintf("failed to ask the user to confirm %s", request.Params.Name), err), nil
+		}
+		if !accepted(result) {
+			return mcp.NewToolResultError(fmt.Sprintf("the user did not confirm the call to %s, so it was not made", request.Params.Name)), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// accepted reports whether the user accepted the elicitation and checked the confirmation.
+func accepted(result *mcp.ElicitationResult) bool {
+	if result.Action != mcp.ElicitationResponseActionAccept {
+		return false
+	}
+	content, _ := result.Content.(map[string]any)
+	confirmed, _ := content[confirmField].(bool)
+	return confirmed
+}
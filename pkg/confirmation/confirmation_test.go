@@ -0,0 +1,146 @@
+package confirmation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/dryrun"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// elicitingSession is a client session answering elicitation requests with a canned response.
+type elicitingSession struct {
+	id       string
+	result   *mcp.ElicitationResult
+	err      error
+	requests []mcp.ElicitationRequest
+}
+
+func (s *elicitingSession) SessionID() string                                   { return s.id }
+func (s *elicitingSession) NotificationChannel() chan<- mcp.JSONRPCNotification { return nil }
+func (s *elicitingSession) Initialize()                                         {}
+func (s *elicitingSession) Initialized() bool                                   { return true }
+
+func (s *elicitingSession) RequestElicitation(_ context.Context, request mcp.ElicitationRequest) (*mcp.ElicitationResult, error) {
+	s.requests = append(s.requests, request)
+	return s.result, s.err
+}
+
+func elicitationResult(action mcp.ElicitationResponseAction, content any) *mcp.ElicitationResult {
+	return &mcp.ElicitationResult{ElicitationResponse: mcp.ElicitationResponse{Action: action, Content: content}}
+}
+
+func Test_ToolHandlerMiddleware(t *testing.T) {
+	confirmer := New(
+		func(tool string) bool { return strings.HasPrefix(tool, "delete_") },
+		func(tool string, args map[string]any) string { return "Delete " + args["path"].(string) },
+	)
+	confirmer.SetClientCapabilities("eliciting", mcp.ClientCapabilities{Elicitation: &struct{}{}})
+	confirmer.SetClientCapabilities("other", mcp.ClientCapabilities{})
+
+	tests := []struct {
+		name           string
+		sessionID      string
+		tool           string
+		dryRun         bool
+		result         *mcp.ElicitationResult
+		err            error
+		expectedAsked  bool
+		expectedCalled bool
+		expectedErrMsg string
+	}{
+		{
+			name:           "confirmed",
+			sessionID:      "eliciting",
+			tool:           "delete_file",
+			result:         elicitationResult(mcp.ElicitationResponseActionAccept, map[string]any{"confirm": true}),
+			expectedAsked:  true,
+			expectedCalled: true,
+		},
+		{
+			name:           "declined",
+			sessionID:      "eliciting",
+			tool:           "delete_file",
+			result:         elicitationResult(mcp.ElicitationResponseActionDecline, nil),
+			expectedAsked:  true,
+			expectedErrMsg: "the user did not confirm the call to delete_file, so it was not made",
+		},
+		{
+			name:           "accepted without confirming",
+			sessionID:      "eliciting",
+			tool:           "delete_file",
+			result:         elicitationResult(mcp.ElicitationResponseActionAccept, map[string]any{"confirm": false}),
+			expectedAsked:  true,
+			expectedErrMsg: "the user did not confirm the call to delete_file, so it was not made",
+		},
+		{
+			name:           "elicitation failed",
+			sessionID:      "eliciting",
+			tool:           "delete_file",
+			err:            errors.New("timed out"),
+			expectedAsked:  true,
+			expectedErrMsg: "failed to ask the user to confirm delete_file: timed out",
+		},
+		{
+			name:           "tool not confirmed",
+			sessionID:      "eliciting",
+			tool:           "create_issue",
+			expectedCalled: true,
+		},
+		{
+			name:           "client without elicitation",
+			sessionID:      "other",
+			tool:           "delete_file",
+			expectedCalled: true,
+		},
+		{
+			name:           "dry run",
+			sessionID:      "eliciting",
+			tool:           "delete_file",
+			dryRun:         true,
+			expectedCalled: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			session := &elicitingSession{id: tc.sessionID, result: tc.result, err: tc.err}
+			ctx := server.NewMCPServer("test", "1.0").WithContext(context.Background(), session)
+
+			var called bool
+			handler := confirmer.ToolHandlerMiddleware(func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				called = true
+				return mcp.NewToolResultText("deleted"), nil
+			})
+			handler = dryrun.ToolHandlerMiddleware(tc.dryRun, func(string) bool { return true })(handler)
+
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tc.tool
+			request.Params.Arguments = map[string]any{"path": "README.md"}
+			result, err := handler(ctx, request)
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.expectedCalled, called)
+			if tc.expectedAsked {
+				require.Len(t, session.requests, 1)
+				assert.Equal(t, "Delete README.md", session.requests[0].Params.Message)
+			} else {
+				assert.Empty(t, session.requests)
+			}
+			if tc.expectedErrMsg != "" {
+				require.True(t, result.IsError)
+				assert.Equal(t, tc.expectedErrMsg, result.Content[0].(mcp.TextContent).Text)
+			}
+		})
+	}
+
+	// Sessions are forgotten once over
+	confirmer.ForgetSession("eliciting")
+	_, ok := confirmer.sessions.Load("eliciting")
+	assert.False(t, ok)
+}
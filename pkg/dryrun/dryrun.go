@@ -0,0 +1,223 @@
+// Package dryrun lets write tools show the changes they would make on GitHub without making them. Their reads
+// are sent as usual, so that inputs are validated and refs resolved, while their writes are recorded and answered
+// with stand-in responses instead.
+package dryrun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Parameter is the name of the argument asking a write tool for a dry run.
+const Parameter = "dry_run"
+
+// PlaceholderSHA stands for the SHA of the objects, such as trees and commits, a dry run does not create.
+const PlaceholderSHA = "0000000000000000000000000000000000000000"
+
+// Mutation is a request a dry run did not send.
+type Mutation struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   any    `json:"body,omitempty"`
+}
+
+// Plan is the result of a dry run: the requests the tool would have sent to change data on GitHub, in order.
+type Plan struct {
+	DryRun    bool       `json:"dry_run"`
+	Mutations []Mutation `json:"mutations"`
+
+	// Error is the error the tool reported after planning its first mutation, which may be due to the stand-in
+	// responses it was given
+	Error string `json:"error,omitempty"`
+}
+
+// WithParameter adds the dry run parameter to a write tool.
+func WithParameter() mcp.ToolOption {
+	return mcp.WithBoolean(Parameter,
+		mcp.Description("Validate the call and return the changes it would make, without making them"),
+	)
+}
+
+type recorderKey struct{}
+
+// recorder collects the mutations planned by a tool call.
+type recorder struct {
+	mu        sync.Mutex
+	mutations []Mutation
+}
+
+func (r *recorder) add(mutation Mutation) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mutations = append(r.mutations, mutation)
+}
+
+// IsDryRun reports whether ctx belongs to a dry run.
+func IsDryRun(ctx context.Context) bool {
+	_, ok := ctx.Value(recorderKey{}).(*recorder)
+	return ok
+}
+
+// ToolHandlerMiddleware returns a middleware making dry runs of the calls to the tools for which isWrite returns
+// true, either all of them if always is set, or those with a true dry run argument. A dry run returns the Plan
+// of the call, or the tool's error if it failed before planning any mutation.
+func ToolHandlerMiddleware(always bool, isWrite func(tool string) bool) server.ToolHandlerMiddleware {
+	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			if !isWrite(request.Params.Name) {
+				return next(ctx, request)
+			}
+			if requested, _ := request.GetArguments()[Parameter].(bool); !always && !requested {
+				return next(ctx, request)
+			}
+
+			r := &recorder{}
+			result, err := next(context.WithValue(ctx, recorderKey{}, r), request)
+
+			r.mu.Lock()
+			defer r.mu.Unlock()
+			plan := Plan{DryRun: true, Mutations: append([]Mutation{}, r.mutations...)}
+			switch {
+			case err != nil:
+				if len(plan.Mutations) == 0 {
+					return nil, err
+				}
+				plan.Error = err.Error()
+			case result != nil && result.IsError:
+				if len(plan.Mutations) == 0 {
+					return result, nil
+				}
+				plan.Error = resultText(result)
+			}
+
+			text, err := json.Marshal(plan)
+			if err != nil {
+				return nil, err
+			}
+			return mcp.NewToolResultText(string(text)), nil
+		}
+	}
+}
+
+// resultText returns the text of an error result.
+func resultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
+
+// Transport is an http.RoundTripper that, for requests made by dry runs, records the requests that would change
+// data on GitHub and answers them with stand-in responses rather than sending them. Other requests are sent as is.
+type Transport struct {
+	// Base is the underlying RoundTripper, http.DefaultTransport if nil.
+	Base http.RoundTripper
+}
+
+// RoundTrip sends req using the base transport, unless it is a mutation made by a dry run.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	r, ok := req.Context().Value(recorderKey{}).(*recorder)
+	if !ok {
+		return base.RoundTrip(req)
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if !isMutation(req, body) {
+		return base.RoundTrip(req)
+	}
+
+	mutation := Mutation{Method: req.Method, URL: req.URL.String()}
+	if len(body) > 0 {
+		var decoded any
+		if err := json.Unmarshal(body, &decoded); err == nil {
+			mutation.Body = decoded
+		} else {
+			mutation.Body = string(body)
+		}
+	}
+	r.add(mutation)
+
+	return standInResponse(req), nil
+}
+
+// readBody returns the body of req, leaving it intact to be sent.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// isMutation reports whether req, with the given body, could change data on GitHub.
+func isMutation(req *http.Request, body []byte) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return false
+	case http.MethodPost:
+		// GraphQL queries are sent as POST requests, but unlike mutations have no side effects
+		if !strings.HasSuffix(req.URL.Path, "/graphql") {
+			return true
+		}
+		var graphQLRequest struct {
+			Query string `json:"query"`
+		}
+		if err := json.Unmarshal(body, &graphQLRequest); err != nil {
+			return true
+		}
+		query := strings.TrimSpace(graphQLRequest.Query)
+		return !strings.HasPrefix(query, "query") && !strings.HasPrefix(query, "{")
+	default:
+		return true
+	}
+}
+
+// standInResponse returns a successful response to a mutation, with the status code GitHub most commonly uses for
+// its method. REST responses only carry the placeholder SHA, letting tools chain mutations such as creating a
+// tree, then a commit and updating a ref. GraphQL responses carry no data.
+func standInResponse(req *http.Request) *http.Response {
+	status, body := http.StatusOK, `{"sha":"`+PlaceholderSHA+`"}`
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/graphql"):
+		body = `{"data":null}`
+	case req.Method == http.MethodPost:
+		status = http.StatusCreated
+	case req.Method == http.MethodDelete:
+		status, body = http.StatusNoContent, ""
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
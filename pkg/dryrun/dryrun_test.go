@@ -0,0 +1,244 @@
+package dryrun
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPI records the requests it receives.
+type fakeAPI struct {
+	server *httptest.Server
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func newFakeAPI(t *testing.T) *fakeAPI {
+	t.Helper()
+
+	api := &fakeAPI{}
+	api.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		api.requests = append(api.requests, r.Method+" "+r.URL.Path)
+		api.mu.Unlock()
+		_, _ = w.Write([]byte(`{"data": {}}`))
+	}))
+	t.Cleanup(api.server.Close)
+	return api
+}
+
+func (api *fakeAPI) received() []string {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return append([]string(nil), api.requests...)
+}
+
+func Test_Transport(t *testing.T) {
+	api := newFakeAPI(t)
+	client := &http.Client{Transport: &Transport{}}
+	ctx := context.WithValue(context.Background(), recorderKey{}, &recorder{})
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedSent   bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "read", method: http.MethodGet, path: "/repos/octocat/hello-world", expectedSent: true, expectedStatus: http.StatusOK},
+		{name: "graphql query", method: http.MethodPost, path: "/graphql", body: `{"query":"query{viewer{login}}"}`, expectedSent: true, expectedStatus: http.StatusOK},
+		{name: "create", method: http.MethodPost, path: "/repos/octocat/hello-world/git/trees", body: `{"tree":[]}`, expectedStatus: http.StatusCreated, expectedBody: `{"sha":"` + PlaceholderSHA + `"}`},
+		{name: "update", method: http.MethodPatch, path: "/repos/octocat/hello-world/git/refs/heads/main", body: `{"sha":"abc"}`, expectedStatus: http.StatusOK, expectedBody: `{"sha":"` + PlaceholderSHA + `"}`},
+		{name: "delete", method: http.MethodDelete, path: "/repos/octocat/hello-world/git/refs/heads/old", expectedStatus: http.StatusNoContent},
+		{name: "graphql mutation", method: http.MethodPost, path: "/graphql", body: `{"query":"mutation($input:MergePullRequestInput!){mergePullRequest(input:$input){clientMutationId}}"}`, expectedStatus: http.StatusOK, expectedBody: `{"data":null}`},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sent := len(api.received())
+
+			var body io.Reader
+			if tc.body != "" {
+				body = strings.NewReader(tc.body)
+			}
+			req, err := http.NewRequestWithContext(ctx, tc.method, api.server.URL+tc.path, body)
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer func() { _ = resp.Body.Close() }()
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			if tc.expectedSent {
+				assert.Equal(t, []string{tc.method + " " + tc.path}, api.received()[sent:])
+				return
+			}
+			assert.Len(t, api.received(), sent)
+			respBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedBody, string(respBody))
+		})
+	}
+
+	r := ctx.Value(recorderKey{}).(*recorder)
+	require.Len(t, r.mutations, 4)
+	assert.Equal(t, Mutation{
+		Method: http.MethodPost,
+		URL:    api.server.URL + "/repos/octocat/hello-world/git/trees",
+		Body:   map[string]any{"tree": []any{}},
+	}, r.mutations[0])
+	assert.Equal(t, http.MethodDelete, r.mutations[2].Method)
+	assert.Nil(t, r.mutations[2].Body)
+
+	// Requests outside dry runs are sent as is
+	req, err := http.NewRequest(http.MethodDelete, api.server.URL+"/repos/octocat/hello-world", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Contains(t, api.received(), "DELETE /repos/octocat/hello-world")
+}
+
+func Test_ToolHandlerMiddleware(t *testing.T) {
+	api := newFakeAPI(t)
+	client := &http.Client{Transport: &Transport{}}
+
+	post := func(ctx context.Context) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, api.server.URL+"/repos/octocat/hello-world/issues", strings.NewReader(`{"title":"Bug"}`))
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+
+	tests := []struct {
+		name           string
+		always         bool
+		tool           string
+		args           map[string]any
+		handler        server.ToolHandlerFunc
+		expectedPlan   *Plan
+		expectedResult string
+		expectedErrMsg string
+	}{
+		{
+			name: "dry run requested",
+			tool: "create_issue",
+			args: map[string]any{"title": "Bug", "dry_run": true},
+			handler: func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				post(ctx)
+				return mcp.NewToolResultText("created"), nil
+			},
+			expectedPlan: &Plan{DryRun: true, Mutations: []Mutation{{
+				Method: http.MethodPost,
+				URL:    api.server.URL + "/repos/octocat/hello-world/issues",
+				Body:   map[string]any{"title": "Bug"},
+			}}},
+		},
+		{
+			name:   "dry run mode",
+			always: true,
+			tool:   "create_issue",
+			args:   map[string]any{"title": "Bug"},
+			handler: func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				post(ctx)
+				return mcp.NewToolResultError("unexpected response"), nil
+			},
+			expectedPlan: &Plan{DryRun: true, Error: "unexpected response", Mutations: []Mutation{{
+				Method: http.MethodPost,
+				URL:    api.server.URL + "/repos/octocat/hello-world/issues",
+				Body:   map[string]any{"title": "Bug"},
+			}}},
+		},
+		{
+			name:   "nothing to change",
+			always: true,
+			tool:   "create_issue",
+			handler: func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("nothing to do"), nil
+			},
+			expectedPlan: &Plan{DryRun: true, Mutations: []Mutation{}},
+		},
+		{
+			name:   "invalid input",
+			always: true,
+			tool:   "create_issue",
+			handler: func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultError("missing required parameter: title"), nil
+			},
+			expectedResult: "missing required parameter: title",
+		},
+		{
+			name:   "handler error",
+			always: true,
+			tool:   "create_issue",
+			handler: func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return nil, errors.New("failed to get GitHub client")
+			},
+			expectedErrMsg: "failed to get GitHub client",
+		},
+		{
+			name: "dry run not requested",
+			tool: "create_issue",
+			args: map[string]any{"title": "Bug", "dry_run": false},
+			handler: func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				assert.False(t, IsDryRun(ctx))
+				return mcp.NewToolResultText("created"), nil
+			},
+			expectedResult: "created",
+		},
+		{
+			name:   "read tool",
+			always: true,
+			tool:   "get_issue",
+			handler: func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				assert.False(t, IsDryRun(ctx))
+				return mcp.NewToolResultText("issue"), nil
+			},
+			expectedResult: "issue",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sent := len(api.received())
+			handler := ToolHandlerMiddleware(tc.always, func(tool string) bool {
+				return strings.HasPrefix(tool, "create_")
+			})(tc.handler)
+
+			request := mcp.CallToolRequest{}
+			request.Params.Name = tc.tool
+			request.Params.Arguments = tc.args
+			result, err := handler(context.Background(), request)
+
+			assert.Len(t, api.received(), sent)
+			if tc.expectedErrMsg != "" {
+				require.EqualError(t, err, tc.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			text := result.Content[0].(mcp.TextContent).Text
+			if tc.expectedPlan == nil {
+				assert.Equal(t, tc.expectedResult, text)
+				return
+			}
+			assert.False(t, result.IsError)
+			var plan Plan
+			require.NoError(t, json.Unmarshal([]byte(text), &plan))
+			assert.Equal(t, *tc.expectedPlan, plan)
+		})
+	}
+}
@@ -150,6 +150,7 @@ func ListWorkflowRuns(getClient GetClientFn, t translations.TranslationHelperFun
 				mcp.Enum("queued", "in_progress", "completed", "requested", "waiting"),
 			),
 			WithPagination(),
+			WithMaxItems(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -188,6 +189,10 @@ func ListWorkflowRuns(getClient GetClientFn, t translations.TranslationHelperFun
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
+			maxItems, err := OptionalMaxItemsParam(request, &pagination.PerPage)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
 			client, err := getClient(ctx)
 			if err != nil {
@@ -206,6 +211,23 @@ func ListWorkflowRuns(getClient GetClientFn, t translations.TranslationHelperFun
 				},
 			}
 
+			if maxItems > 0 {
+				result, _, err := collectPages(ctx, &opts.ListOptions, maxItems,
+					func(run *github.WorkflowRun) string { return strconv.FormatInt(run.GetID(), 10) },
+					func() ([]*github.WorkflowRun, *github.Response, error) {
+						workflowRuns, resp, err := client.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, workflowID, opts)
+						if err != nil {
+							return nil, resp, err
+						}
+						return workflowRuns.WorkflowRuns, resp, nil
+					},
+				)
+				if err != nil {
+					return nil, fmt.Errorf("failed to list workflow runs: %w", err)
+				}
+				return MarshalledTextResult(result), nil
+			}
+
 			workflowRuns, resp, err := client.Actions.ListWorkflowRunsByFileName(ctx, owner, repo, workflowID, opts)
 			if err != nil {
 				return nil, fmt.Errorf("failed to list workflow runs: %w", err)
@@ -229,6 +251,7 @@ func RunWorkflow(getClient GetClientFn, t translations.TranslationHelperFunc) (t
 				Title:        t("TOOL_RUN_WORKFLOW_USER_TITLE", "Run workflow"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description(DescriptionRepositoryOwner),
@@ -531,7 +554,7 @@ func ListWorkflowJobs(getClient GetClientFn, t translations.TranslationHelperFun
 }
 
 // GetJobLogs creates a tool to download logs for a specific workflow job or efficiently get all failed job logs for a workflow run
-func GetJobLogs(getClient GetClientFn, t translations.TranslationHelperFunc, contentWindowSize int) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func GetJobLogs(getClient GetClientFn, getDownloadClient GetDownloadClientFn, t translations.TranslationHelperFunc, contentWindowSize int) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_job_logs",
 			mcp.WithDescription(t("TOOL_GET_JOB_LOGS_DESCRIPTION", "Download logs for a specific workflow job or efficiently get all failed job logs for a workflow run")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -603,6 +626,10 @@ func GetJobLogs(getClient GetClientFn, t translations.TranslationHelperFunc, con
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
+			downloadClient, err := getDownloadClient(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get download client: %w", err)
+			}
 
 			// Validate parameters
 			if failedOnly && runID == 0 {
@@ -614,10 +641,10 @@ func GetJobLogs(getClient GetClientFn, t translations.TranslationHelperFunc, con
 
 			if failedOnly && runID > 0 {
 				// Handle failed-only mode: get logs for all failed jobs in the workflow run
-				return handleFailedJobLogs(ctx, client, owner, repo, int64(runID), returnContent, tailLines, contentWindowSize)
+				return handleFailedJobLogs(ctx, client, downloadClient, owner, repo, int64(runID), returnContent, tailLines, contentWindowSize)
 			} else if jobID > 0 {
 				// Handle single job mode
-				return handleSingleJobLogs(ctx, client, owner, repo, int64(jobID), returnContent, tailLines, contentWindowSize)
+				return handleSingleJobLogs(ctx, client, downloadClient, owner, repo, int64(jobID), returnContent, tailLines, contentWindowSize)
 			}
 
 			return mcp.NewToolResultError("Either job_id must be provided for single job logs, or run_id with failed_only=true for failed job logs"), nil
@@ -625,7 +652,7 @@ func GetJobLogs(getClient GetClientFn, t translations.TranslationHelperFunc, con
 }
 
 // handleFailedJobLogs gets logs for all failed jobs in a workflow run
-func handleFailedJobLogs(ctx context.Context, client *github.Client, owner, repo string, runID int64, returnContent bool, tailLines int, contentWindowSize int) (*mcp.CallToolResult, error) {
+func handleFailedJobLogs(ctx context.Context, client *github.Client, downloadClient *http.Client, owner, repo string, runID int64, returnContent bool, tailLines int, contentWindowSize int) (*mcp.CallToolResult, error) {
 	// First, get all jobs for the workflow run
 	jobs, resp, err := client.Actions.ListWorkflowJobs(ctx, owner, repo, runID, &github.ListWorkflowJobsOptions{
 		Filter: "latest",
@@ -657,7 +684,7 @@ func handleFailedJobLogs(ctx context.Context, client *github.Client, owner, repo
 	// Collect logs for all failed jobs
 	var logResults []map[string]any
 	for _, job := range failedJobs {
-		jobResult, resp, err := getJobLogData(ctx, client, owner, repo, job.GetID(), job.GetName(), returnContent, tailLines, contentWindowSize)
+		jobResult, resp, err := getJobLogData(ctx, client, downloadClient, owner, repo, job.GetID(), job.GetName(), returnContent, tailLines, contentWindowSize)
 		if err != nil {
 			// Continue with other jobs even if one fails
 			jobResult = map[string]any{
@@ -690,8 +717,8 @@ func handleFailedJobLogs(ctx context.Context, client *github.Client, owner, repo
 }
 
 // handleSingleJobLogs gets logs for a single job
-func handleSingleJobLogs(ctx context.Context, client *github.Client, owner, repo string, jobID int64, returnContent bool, tailLines int, contentWindowSize int) (*mcp.CallToolResult, error) {
-	jobResult, resp, err := getJobLogData(ctx, client, owner, repo, jobID, "", returnContent, tailLines, contentWindowSize)
+func handleSingleJobLogs(ctx context.Context, client *github.Client, downloadClient *http.Client, owner, repo string, jobID int64, returnContent bool, tailLines int, contentWindowSize int) (*mcp.CallToolResult, error) {
+	jobResult, resp, err := getJobLogData(ctx, client, downloadClient, owner, repo, jobID, "", returnContent, tailLines, contentWindowSize)
 	if err != nil {
 		return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to get job logs", resp, err), nil
 	}
@@ -705,7 +732,7 @@ func handleSingleJobLogs(ctx context.Context, client *github.Client, owner, repo
 }
 
 // getJobLogData retrieves log data for a single job, either as URL or content
-func getJobLogData(ctx context.Context, client *github.Client, owner, repo string, jobID int64, jobName string, returnContent bool, tailLines int, contentWindowSize int) (map[string]any, *github.Response, error) {
+func getJobLogData(ctx context.Context, client *github.Client, downloadClient *http.Client, owner, repo string, jobID int64, jobName string, returnContent bool, tailLines int, contentWindowSize int) (map[string]any, *github.Response, error) {
 	// Get the download URL for the job logs
 	url, resp, err := client.Actions.GetWorkflowJobLogs(ctx, owner, repo, jobID, 1)
 	if err != nil {
@@ -722,7 +749,7 @@ func getJobLogData(ctx context.Context, client *github.Client, owner, repo strin
 
 	if returnContent {
 		// Download and return the actual log content
-		content, originalLength, httpResp, err := downloadLogContent(ctx, url.String(), tailLines, contentWindowSize) //nolint:bodyclose // Response body is closed in downloadLogContent, but we need to return httpResp
+		content, originalLength, httpResp, err := downloadLogContent(ctx, downloadClient, url.String(), tailLines, contentWindowSize) //nolint:bodyclose // Response body is closed in downloadLogContent, but we need to return httpResp
 		if err != nil {
 			// To keep the return value consistent wrap the response as a GitHub Response
 			ghRes := &github.Response{
@@ -743,11 +770,15 @@ func getJobLogData(ctx context.Context, client *github.Client, owner, repo strin
 	return result, resp, nil
 }
 
-func downloadLogContent(ctx context.Context, logURL string, tailLines int, maxLines int) (string, int, *http.Response, error) {
+func downloadLogContent(ctx context.Context, downloadClient *http.Client, logURL string, tailLines int, maxLines int) (string, int, *http.Response, error) {
 	prof := profiler.New(nil, profiler.IsProfilingEnabled())
 	finish := prof.Start(ctx, "log_buffer_processing")
 
-	httpResp, err := http.Get(logURL) //nolint:gosec
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, logURL, nil)
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("failed to download logs: %w", err)
+	}
+	httpResp, err := downloadClient.Do(req)
 	if err != nil {
 		return "", 0, httpResp, fmt.Errorf("failed to download logs: %w", err)
 	}
@@ -786,6 +817,7 @@ func RerunWorkflowRun(getClient GetClientFn, t translations.TranslationHelperFun
 				Title:        t("TOOL_RERUN_WORKFLOW_RUN_USER_TITLE", "Rerun workflow run"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description(DescriptionRepositoryOwner),
@@ -849,6 +881,7 @@ func RerunFailedJobs(getClient GetClientFn, t translations.TranslationHelperFunc
 				Title:        t("TOOL_RERUN_FAILED_JOBS_USER_TITLE", "Rerun failed jobs"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description(DescriptionRepositoryOwner),
@@ -912,6 +945,7 @@ func CancelWorkflowRun(getClient GetClientFn, t translations.TranslationHelperFu
 				Title:        t("TOOL_CANCEL_WORKFLOW_RUN_USER_TITLE", "Cancel workflow run"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description(DescriptionRepositoryOwner),
@@ -1112,6 +1146,7 @@ func DeleteWorkflowRunLogs(getClient GetClientFn, t translations.TranslationHelp
 				ReadOnlyHint:    ToBoolPtr(false),
 				DestructiveHint: ToBoolPtr(true),
 			}),
+			withRequiredScopes(ScopeRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description(DescriptionRepositoryOwner),
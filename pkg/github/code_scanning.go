@@ -21,6 +21,7 @@ func GetCodeScanningAlert(getClient GetClientFn, t translations.TranslationHelpe
 				Title:        t("TOOL_GET_CODE_SCANNING_ALERT_USER_TITLE", "Get code scanning alert"),
 				ReadOnlyHint: ToBoolPtr(true),
 			}),
+			withRequiredScopes(ScopeSecurityEvents, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("The owner of the repository."),
@@ -87,6 +88,7 @@ func ListCodeScanningAlerts(getClient GetClientFn, t translations.TranslationHel
 				Title:        t("TOOL_LIST_CODE_SCANNING_ALERTS_USER_TITLE", "List code scanning alerts"),
 				ReadOnlyHint: ToBoolPtr(true),
 			}),
+			withRequiredScopes(ScopeSecurityEvents, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("The owner of the repository."),
@@ -19,6 +19,7 @@ func ListCodespaces(getClient GetClientFn, t translations.TranslationHelperFunc)
 				Title:        t("TOOL_LIST_CODESPACES_USER_TITLE", "List codespaces"),
 				ReadOnlyHint: ToBoolPtr(true),
 			}),
+			withRequiredScopes(ScopeCodespace),
 		), func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			client, err := getClient(ctx)
 			if err != nil {
@@ -47,6 +48,7 @@ func CreateCodespace(getClient GetClientFn, t translations.TranslationHelperFunc
 			Title:        t("TOOL_CREATE_CODESPACE_USER_TITLE", "Create codespace"),
 			ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeCodespace),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description(DescriptionRepositoryOwner),
@@ -109,6 +111,7 @@ func StopCodespace(getClient GetClientFn, t translations.TranslationHelperFunc)
 			Title:        t("TOOL_STOP_CODESPACE_USER_TITLE", "Stop codespace"),
 			ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeCodespace),
 			mcp.WithString("name",
 				mcp.Required(),
 				mcp.Description("The name of the codespace to stop"),
@@ -141,6 +144,7 @@ func DeleteCodespace(getClient GetClientFn, t translations.TranslationHelperFunc
 			Title:        t("TOOL_DELETE_CODESPACE_USER_TITLE", "Delete codespace"),
 			ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeCodespace),
 			mcp.WithString("name",
 				mcp.Required(),
 				mcp.Description("The name of the codespace to delete"),
@@ -1,13 +1,10 @@
 package github
 
 import (
-	"context"
-	"net/http"
 	"testing"
 
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/google/go-github/v74/github"
-	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,7 +14,7 @@ func Test_ListCodespaces(t *testing.T) {
 
 	assert.Equal(t, "list_codespaces", tool.Name)
 	assert.NotEmpty(t, tool.Description)
-	assert.True(t, *tool.Annotation.ReadOnlyHint)
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
 }
 
 func Test_CreateCodespace(t *testing.T) {
@@ -51,4 +48,4 @@ func Test_DeleteCodespace(t *testing.T) {
 	assert.NotEmpty(t, tool.Description)
 	assert.Contains(t, tool.InputSchema.Properties, "name")
 	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"name"})
-}
\ No newline at end of file
+}
@@ -0,0 +1,93 @@
+package github
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultConfirmTools are the tools asking the user for confirmation by default, those whose effect is the
+// hardest to undo.
+var DefaultConfirmTools = []string{
+	"delete_file",
+	"delete_codespace",
+	"delete_workflow_run_logs",
+	"merge_pull_request",
+	"delete_project_item",
+	"mark_all_notifications_read",
+}
+
+// confirmationSummaries describe the effect of a call to a tool, for the user to confirm it.
+var confirmationSummaries = map[string]func(args map[string]any) string{
+	"delete_file": func(args map[string]any) string {
+		return fmt.Sprintf("Delete %s from the %s branch of %s", argString(args, "path"), argString(args, "branch"), repoName(args))
+	},
+	"delete_codespace": func(args map[string]any) string {
+		return fmt.Sprintf("Delete codespace %s, along with any changes not pushed from it", argString(args, "name"))
+	},
+	"delete_workflow_run_logs": func(args map[string]any) string {
+		return fmt.Sprintf("Delete the logs of workflow run %s in %s", argString(args, "run_id"), repoName(args))
+	},
+	"merge_pull_request": func(args map[string]any) string {
+		method := argString(args, "merge_method")
+		if method == "" {
+			method = "merge"
+		}
+		return fmt.Sprintf("Merge pull request #%s into %s, using the %s method", argString(args, "pullNumber"), repoName(args), method)
+	},
+	"delete_project_item": func(args map[string]any) string {
+		return fmt.Sprintf("Delete item %s from project %s of %s", argString(args, "item_id"), argString(args, "project_number"), argString(args, "owner"))
+	},
+	"mark_all_notifications_read": func(args map[string]any) string {
+		summary := "Mark all notifications as read"
+		if owner, repo := argString(args, "owner"), argString(args, "repo"); owner != "" && repo != "" {
+			summary = fmt.Sprintf("Mark all notifications in %s/%s as read", owner, repo)
+		}
+		if lastReadAt := argString(args, "lastReadAt"); lastReadAt != "" {
+			summary += ", up to " + lastReadAt
+		}
+		return summary
+	},
+}
+
+// ConfirmationSummary returns a human-readable summary of the effect of calling the tool with args, for the user
+// to confirm it. Tools without a dedicated summary are described by their arguments.
+func ConfirmationSummary(tool string, args map[string]any) string {
+	if summarize, ok := confirmationSummaries[tool]; ok {
+		return summarize(args)
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	described := make([]string, 0, len(names))
+	for _, name := range names {
+		described = append(described, fmt.Sprintf("%s: %s", name, argString(args, name)))
+	}
+	if len(described) == 0 {
+		return "Call " + tool
+	}
+	return fmt.Sprintf("Call %s with %s", tool, strings.Join(described, ", "))
+}
+
+// argString formats an argument for a summary, without exponents for numbers.
+func argString(args map[string]any, name string) string {
+	switch v := args[name].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// repoName formats the owner and repo arguments as owner/repo.
+func repoName(args map[string]any) string {
+	return argString(args, "owner") + "/" + argString(args, "repo")
+}
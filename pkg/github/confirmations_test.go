@@ -0,0 +1,61 @@
+package github
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ConfirmationSummary(t *testing.T) {
+	tests := []struct {
+		tool     string
+		args     map[string]any
+		expected string
+	}{
+		{
+			tool:     "delete_file",
+			args:     map[string]any{"owner": "octocat", "repo": "hello-world", "branch": "main", "path": "docs/README.md"},
+			expected: "Delete docs/README.md from the main branch of octocat/hello-world",
+		},
+		{
+			tool:     "merge_pull_request",
+			args:     map[string]any{"owner": "octocat", "repo": "hello-world", "pullNumber": float64(42)},
+			expected: "Merge pull request #42 into octocat/hello-world, using the merge method",
+		},
+		{
+			tool:     "delete_workflow_run_logs",
+			args:     map[string]any{"owner": "octocat", "repo": "hello-world", "run_id": float64(12345678901)},
+			expected: "Delete the logs of workflow run 12345678901 in octocat/hello-world",
+		},
+		{
+			tool:     "mark_all_notifications_read",
+			args:     map[string]any{},
+			expected: "Mark all notifications as read",
+		},
+		{
+			tool:     "mark_all_notifications_read",
+			args:     map[string]any{"owner": "octocat", "repo": "hello-world", "lastReadAt": "2025-01-01T00:00:00Z"},
+			expected: "Mark all notifications in octocat/hello-world as read, up to 2025-01-01T00:00:00Z",
+		},
+		{
+			tool:     "delete_branch",
+			args:     map[string]any{"repo": "hello-world", "owner": "octocat", "force": true},
+			expected: "Call delete_branch with force: true, owner: octocat, repo: hello-world",
+		},
+		{
+			tool:     "delete_everything",
+			expected: "Call delete_everything",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.tool, func(t *testing.T) {
+			assert.Equal(t, tc.expected, ConfirmationSummary(tc.tool, tc.args))
+		})
+	}
+
+	// Every tool confirmed by default has a dedicated summary
+	for _, tool := range DefaultConfirmTools {
+		assert.Contains(t, confirmationSummaries, tool)
+	}
+}
@@ -125,6 +125,7 @@ func Test_GetMe(t *testing.T) {
 			var returnedUser MinimalUser
 			err = json.Unmarshal([]byte(textContent.Text), &returnedUser)
 			require.NoError(t, err)
+			assert.JSONEq(t, textContent.Text, getStructuredResult(t, result))
 
 			// Verify minimal user details
 			assert.Equal(t, *tc.expectedUser.Login, returnedUser.Login)
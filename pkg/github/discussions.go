@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/github/github-mcp-server/pkg/sanitize"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/google/go-github/v74/github"
@@ -230,7 +232,9 @@ func ListDiscussions(getGQLClient GetGQLClientFn, t translations.TranslationHelp
 			if queryResult, ok := discussionQuery.(DiscussionQueryResult); ok {
 				fragment := queryResult.GetDiscussionFragment()
 				for _, node := range fragment.Nodes {
-					discussions = append(discussions, fragmentToDiscussion(node))
+					discussion := fragmentToDiscussion(node)
+					discussion.Title = github.Ptr(lockdown.Withhold(ctx, owner, repo, discussion.GetUser().GetLogin(), discussion.GetTitle()))
+					discussions = append(discussions, discussion)
 				}
 				pageInfo = fragment.PageInfo
 				totalCount = fragment.TotalCount
@@ -248,6 +252,7 @@ func ListDiscussions(getGQLClient GetGQLClientFn, t translations.TranslationHelp
 				"totalCount": totalCount,
 			}
 
+			sanitize.Value(ctx, response)
 			out, err := json.Marshal(response)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal discussions: %w", err)
@@ -294,9 +299,12 @@ func GetDiscussion(getGQLClient GetGQLClientFn, t translations.TranslationHelper
 			var q struct {
 				Repository struct {
 					Discussion struct {
-						Number    githubv4.Int
-						Title     githubv4.String
-						Body      githubv4.String
+						Number githubv4.Int
+						Title  githubv4.String
+						Body   githubv4.String
+						Author struct {
+							Login githubv4.String
+						}
 						CreatedAt githubv4.DateTime
 						URL       githubv4.String `graphql:"url"`
 						Category  struct {
@@ -314,16 +322,18 @@ func GetDiscussion(getGQLClient GetGQLClientFn, t translations.TranslationHelper
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 			d := q.Repository.Discussion
+			author := string(d.Author.Login)
 			discussion := &github.Discussion{
 				Number:    github.Ptr(int(d.Number)),
-				Title:     github.Ptr(string(d.Title)),
-				Body:      github.Ptr(string(d.Body)),
+				Title:     github.Ptr(lockdown.Withhold(ctx, params.Owner, params.Repo, author, string(d.Title))),
+				Body:      github.Ptr(lockdown.Withhold(ctx, params.Owner, params.Repo, author, string(d.Body))),
 				HTMLURL:   github.Ptr(string(d.URL)),
 				CreatedAt: &github.Timestamp{Time: d.CreatedAt.Time},
 				DiscussionCategory: &github.DiscussionCategory{
 					Name: github.Ptr(string(d.Category.Name)),
 				},
 			}
+			sanitize.Value(ctx, discussion)
 			out, err := json.Marshal(discussion)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal discussion: %w", err)
@@ -387,7 +397,10 @@ func GetDiscussionComments(getGQLClient GetGQLClientFn, t translations.Translati
 					Discussion struct {
 						Comments struct {
 							Nodes []struct {
-								Body githubv4.String
+								Body   githubv4.String
+								Author struct {
+									Login githubv4.String
+								}
 							}
 							PageInfo struct {
 								HasNextPage     githubv4.Boolean
@@ -417,7 +430,8 @@ func GetDiscussionComments(getGQLClient GetGQLClientFn, t translations.Translati
 
 			var comments []*github.IssueComment
 			for _, c := range q.Repository.Discussion.Comments.Nodes {
-				comments = append(comments, &github.IssueComment{Body: github.Ptr(string(c.Body))})
+				body := lockdown.Withhold(ctx, params.Owner, params.Repo, string(c.Author.Login), string(c.Body))
+				comments = append(comments, &github.IssueComment{Body: github.Ptr(body)})
 			}
 
 			// Create response with pagination info
@@ -432,6 +446,7 @@ func GetDiscussionComments(getGQLClient GetGQLClientFn, t translations.Translati
 				"totalCount": q.Repository.Discussion.Comments.TotalCount,
 			}
 
+			sanitize.Value(ctx, response)
 			out, err := json.Marshal(response)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal comments: %w", err)
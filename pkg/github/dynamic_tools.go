@@ -19,7 +19,7 @@ func ToolsetEnum(toolsetGroup *toolsets.ToolsetGroup) mcp.PropertyOption {
 	return mcp.Enum(toolsetNames...)
 }
 
-func EnableToolset(s *server.MCPServer, toolsetGroup *toolsets.ToolsetGroup, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+func EnableToolset(toolsetGroup *toolsets.ToolsetGroup, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("enable_toolset",
 			mcp.WithDescription(t("TOOL_ENABLE_TOOLSET_DESCRIPTION", "Enable one of the sets of tools the GitHub MCP server provides, use get_toolset_tools and list_available_toolsets first to see what this will enable")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{
@@ -33,7 +33,7 @@ func EnableToolset(s *server.MCPServer, toolsetGroup *toolsets.ToolsetGroup, t t
 				ToolsetEnum(toolsetGroup),
 			),
 		),
-		func(_ context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			// We need to convert the toolsets back to a map for JSON serialization
 			toolsetName, err := RequiredParam[string](request, "toolset")
 			if err != nil {
@@ -43,17 +43,22 @@ func EnableToolset(s *server.MCPServer, toolsetGroup *toolsets.ToolsetGroup, t t
 			if toolset == nil {
 				return mcp.NewToolResultError(fmt.Sprintf("Toolset %s not found", toolsetName)), nil
 			}
-			if toolset.Enabled {
+
+			sessionID := toolsets.SessionIDFromContext(ctx)
+			if toolsetGroup.IsEnabledForSession(sessionID, toolsetName) {
 				return mcp.NewToolResultText(fmt.Sprintf("Toolset %s is already enabled", toolsetName)), nil
 			}
 
-			toolset.Enabled = true
+			// Only enable the toolset for the calling session, whose tools were registered up front
+			// and are hidden from other sessions.
+			if err := toolsetGroup.EnableSessionToolset(sessionID, toolsetName); err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
-			// caution: this currently affects the global tools and notifies all clients:
-			//
-			// Send notification to all initialized sessions
-			// s.sendNotificationToAllClients("notifications/tools/list_changed", nil)
-			s.AddTools(toolset.GetActiveTools()...)
+			// Only notify the calling session, as no other session's tools have changed.
+			if s := server.ServerFromContext(ctx); s != nil {
+				_ = s.SendNotificationToClient(ctx, mcp.MethodNotificationToolsListChanged, nil)
+			}
 
 			return mcp.NewToolResultText(fmt.Sprintf("Toolset %s enabled", toolsetName)), nil
 		}
@@ -67,9 +72,10 @@ func ListAvailableToolsets(toolsetGroup *toolsets.ToolsetGroup, t translations.T
 				ReadOnlyHint: ToBoolPtr(true),
 			}),
 		),
-		func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			// We need to convert the toolsetGroup back to a map for JSON serialization
 
+			sessionID := toolsets.SessionIDFromContext(ctx)
 			payload := []map[string]string{}
 
 			for name, ts := range toolsetGroup.Toolsets {
@@ -78,7 +84,7 @@ func ListAvailableToolsets(toolsetGroup *toolsets.ToolsetGroup, t translations.T
 						"name":              name,
 						"description":       ts.Description,
 						"can_enable":        "true",
-						"currently_enabled": fmt.Sprintf("%t", ts.Enabled),
+						"currently_enabled": fmt.Sprintf("%t", toolsetGroup.IsEnabledForSession(sessionID, name)),
 					}
 					payload = append(payload, t)
 				}
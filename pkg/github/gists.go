@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/github/github-mcp-server/pkg/sanitize"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/google/go-github/v74/github"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -80,6 +81,7 @@ func ListGists(getClient GetClientFn, t translations.TranslationHelperFunc) (too
 				return mcp.NewToolResultError(fmt.Sprintf("failed to list gists: %s", string(body))), nil
 			}
 
+			sanitize.Value(ctx, gists)
 			r, err := json.Marshal(gists)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -97,6 +99,7 @@ func CreateGist(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 				Title:        t("TOOL_CREATE_GIST", "Create Gist"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeGist),
 			mcp.WithString("description",
 				mcp.Description("Description of the gist"),
 			),
@@ -187,6 +190,7 @@ func UpdateGist(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 				Title:        t("TOOL_UPDATE_GIST", "Update Gist"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeGist),
 			mcp.WithString("gist_id",
 				mcp.Required(),
 				mcp.Description("ID of the gist to update"),
@@ -132,6 +132,15 @@ func getTextResult(t *testing.T, result *mcp.CallToolResult) mcp.TextContent {
 	return textContent
 }
 
+// getStructuredResult is a helper function that returns the structured content of a tool call result as JSON.
+func getStructuredResult(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+	require.NotNil(t, result.StructuredContent)
+	data, err := json.Marshal(result.StructuredContent)
+	require.NoError(t, err)
+	return string(data)
+}
+
 func getErrorResult(t *testing.T, result *mcp.CallToolResult) mcp.TextContent {
 	res := getTextResult(t, result)
 	require.True(t, result.IsError, "expected tool call result to be an error")
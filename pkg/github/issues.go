@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/github/github-mcp-server/pkg/sanitize"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/go-viper/mapstructure/v2"
 	"github.com/google/go-github/v74/github"
@@ -198,30 +201,39 @@ func getIssueQueryType(hasLabels bool, hasSince bool) any {
 	}
 }
 
-func fragmentToIssue(fragment IssueFragment) *github.Issue {
-	// Convert GraphQL labels to GitHub API labels format
-	var foundLabels []*github.Label
+func fragmentToMinimalIssue(fragment IssueFragment) MinimalIssue {
+	// Convert GraphQL labels to minimal labels
+	var foundLabels []MinimalLabel
 	for _, labelNode := range fragment.Labels.Nodes {
-		foundLabels = append(foundLabels, &github.Label{
-			Name:        github.Ptr(string(labelNode.Name)),
-			NodeID:      github.Ptr(string(labelNode.ID)),
-			Description: github.Ptr(string(labelNode.Description)),
+		foundLabels = append(foundLabels, MinimalLabel{
+			Name:        string(labelNode.Name),
+			NodeID:      string(labelNode.ID),
+			Description: string(labelNode.Description),
 		})
 	}
 
-	return &github.Issue{
-		Number:    github.Ptr(int(fragment.Number)),
-		Title:     github.Ptr(string(fragment.Title)),
-		CreatedAt: &github.Timestamp{Time: fragment.CreatedAt.Time},
-		UpdatedAt: &github.Timestamp{Time: fragment.UpdatedAt.Time},
-		User: &github.User{
-			Login: github.Ptr(string(fragment.Author.Login)),
-		},
-		State:    github.Ptr(string(fragment.State)),
-		ID:       github.Ptr(fragment.DatabaseID),
-		Body:     github.Ptr(string(fragment.Body)),
-		Labels:   foundLabels,
-		Comments: github.Ptr(int(fragment.Comments.TotalCount)),
+	return MinimalIssue{
+		ID:        fragment.DatabaseID,
+		Number:    int(fragment.Number),
+		Title:     string(fragment.Title),
+		Body:      string(fragment.Body),
+		State:     string(fragment.State),
+		User:      &MinimalUser{Login: string(fragment.Author.Login)},
+		Labels:    foundLabels,
+		Comments:  int(fragment.Comments.TotalCount),
+		CreatedAt: fragment.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		UpdatedAt: fragment.UpdatedAt.Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// withholdIssue withholds the title and body of issue in lockdown mode, if its author cannot push to owner/repo.
+func withholdIssue(ctx context.Context, owner, repo string, issue *github.Issue) {
+	author := issue.GetUser().GetLogin()
+	if issue.Title != nil {
+		issue.Title = github.Ptr(lockdown.Withhold(ctx, owner, repo, author, issue.GetTitle()))
+	}
+	if issue.Body != nil {
+		issue.Body = github.Ptr(lockdown.Withhold(ctx, owner, repo, author, issue.GetBody()))
 	}
 }
 
@@ -278,6 +290,9 @@ func GetIssue(getClient GetClientFn, t translations.TranslationHelperFunc) (tool
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get issue: %s", string(body))), nil
 			}
 
+			withholdIssue(ctx, owner, repo, issue)
+
+			sanitize.Value(ctx, issue)
 			r, err := json.Marshal(issue)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal issue: %w", err)
@@ -296,6 +311,7 @@ func ListIssueTypes(getClient GetClientFn, t translations.TranslationHelperFunc)
 				Title:        t("TOOL_LIST_ISSUE_TYPES_USER_TITLE", "List available issue types"),
 				ReadOnlyHint: ToBoolPtr(true),
 			}),
+			withRequiredScopes(ScopeReadOrg),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("The organization owner of the repository"),
@@ -342,6 +358,7 @@ func AddIssueComment(getClient GetClientFn, t translations.TranslationHelperFunc
 				Title:        t("TOOL_ADD_ISSUE_COMMENT_USER_TITLE", "Add comment to issue"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -416,6 +433,7 @@ func AddSubIssue(getClient GetClientFn, t translations.TranslationHelperFunc) (t
 				Title:        t("TOOL_ADD_SUB_ISSUE_USER_TITLE", "Add sub-issue"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -576,6 +594,7 @@ func ListSubIssues(getClient GetClientFn, t translations.TranslationHelperFunc)
 				return mcp.NewToolResultError(fmt.Sprintf("failed to list sub-issues: %s", string(body))), nil
 			}
 
+			sanitize.Value(ctx, subIssues)
 			r, err := json.Marshal(subIssues)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -598,6 +617,7 @@ func RemoveSubIssue(getClient GetClientFn, t translations.TranslationHelperFunc)
 				Title:        t("TOOL_REMOVE_SUB_ISSUE_USER_TITLE", "Remove sub-issue"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -677,6 +697,7 @@ func ReprioritizeSubIssue(getClient GetClientFn, t translations.TranslationHelpe
 				Title:        t("TOOL_REPRIORITIZE_SUB_ISSUE_USER_TITLE", "Reprioritize sub-issue"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -835,6 +856,7 @@ func CreateIssue(getClient GetClientFn, t translations.TranslationHelperFunc) (t
 				Title:        t("TOOL_CREATE_ISSUE_USER_TITLE", "Open new issue"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -1008,6 +1030,8 @@ func ListIssues(getGQLClient GetGQLClientFn, t translations.TranslationHelperFun
 				mcp.Description("Filter by date (ISO 8601 timestamp)"),
 			),
 			WithCursorPagination(),
+			WithMaxItems(),
+			mcp.WithOutputSchema[MinimalListIssuesResult](),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -1102,6 +1126,15 @@ func ListIssues(getGQLClient GetGQLClientFn, t translations.TranslationHelperFun
 				paginationParams.First = &defaultFirst
 			}
 
+			perPage := int(*paginationParams.First)
+			maxItems, err := OptionalMaxItemsParam(request, &perPage)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+			if maxItems > 0 {
+				perPage = min(perPage, maxItems)
+			}
+
 			client, err := getGQLClient(ctx)
 			if err != nil {
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get GitHub GQL client: %v", err)), nil
@@ -1113,7 +1146,7 @@ func ListIssues(getGQLClient GetGQLClientFn, t translations.TranslationHelperFun
 				"states":    states,
 				"orderBy":   githubv4.IssueOrderField(orderBy),
 				"direction": githubv4.OrderDirection(direction),
-				"first":     githubv4.Int(*paginationParams.First),
+				"first":     githubv4.Int(perPage),
 			}
 
 			if paginationParams.After != nil {
@@ -1137,46 +1170,49 @@ func ListIssues(getGQLClient GetGQLClientFn, t translations.TranslationHelperFun
 				vars["since"] = githubv4.DateTime{Time: sinceTime}
 			}
 
-			issueQuery := getIssueQueryType(hasLabels, hasSince)
-			if err := client.Query(ctx, issueQuery, vars); err != nil {
-				return mcp.NewToolResultError(err.Error()), nil
-			}
+			// With max_items, follow the endCursor of each page until enough issues are collected, asking for no
+			// more than are missing so that the last endCursor is where the issues collected end.
+			response := MinimalListIssuesResult{Issues: []MinimalIssue{}}
+			seen := make(map[int64]bool)
+			for page := 1; ; page++ {
+				issueQuery := getIssueQueryType(hasLabels, hasSince)
+				if err := client.Query(ctx, issueQuery, vars); err != nil {
+					return mcp.NewToolResultError(err.Error()), nil
+				}
 
-			// Extract and convert all issue nodes using the common interface
-			var issues []*github.Issue
-			var pageInfo struct {
-				HasNextPage     githubv4.Boolean
-				HasPreviousPage githubv4.Boolean
-				StartCursor     githubv4.String
-				EndCursor       githubv4.String
-			}
-			var totalCount int
+				// Extract and convert all issue nodes using the common interface
+				if queryResult, ok := issueQuery.(IssueQueryResult); ok {
+					fragment := queryResult.GetIssueFragment()
+					for _, issue := range fragment.Nodes {
+						if !seen[issue.DatabaseID] {
+							seen[issue.DatabaseID] = true
+							response.Issues = append(response.Issues, fragmentToMinimalIssue(issue))
+						}
+					}
+					response.PageInfo.HasNextPage = bool(fragment.PageInfo.HasNextPage)
+					response.PageInfo.EndCursor = string(fragment.PageInfo.EndCursor)
+					if page == 1 {
+						response.PageInfo.HasPreviousPage = bool(fragment.PageInfo.HasPreviousPage)
+						response.PageInfo.StartCursor = string(fragment.PageInfo.StartCursor)
+					}
+					response.TotalCount = fragment.TotalCount
+				}
 
-			if queryResult, ok := issueQuery.(IssueQueryResult); ok {
-				fragment := queryResult.GetIssueFragment()
-				for _, issue := range fragment.Nodes {
-					issues = append(issues, fragmentToIssue(issue))
+				if maxItems == 0 || !response.PageInfo.HasNextPage || len(response.Issues) >= maxItems || exceedsBudget(ctx, response) {
+					break
 				}
-				pageInfo = fragment.PageInfo
-				totalCount = fragment.TotalCount
-			}
-
-			// Create response with issues
-			response := map[string]interface{}{
-				"issues": issues,
-				"pageInfo": map[string]interface{}{
-					"hasNextPage":     pageInfo.HasNextPage,
-					"hasPreviousPage": pageInfo.HasPreviousPage,
-					"startCursor":     string(pageInfo.StartCursor),
-					"endCursor":       string(pageInfo.EndCursor),
-				},
-				"totalCount": totalCount,
+				vars["after"] = githubv4.String(response.PageInfo.EndCursor)
+				vars["first"] = githubv4.Int(min(perPage, maxItems-len(response.Issues)))
 			}
-			out, err := json.Marshal(response)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal issues: %w", err)
+
+			for i := range response.Issues {
+				issue := &response.Issues[i]
+				issue.Title = lockdown.Withhold(ctx, owner, repo, issue.User.Login, issue.Title)
+				issue.Body = lockdown.Withhold(ctx, owner, repo, issue.User.Login, issue.Body)
 			}
-			return mcp.NewToolResultText(string(out)), nil
+
+			sanitize.Value(ctx, &response)
+			return MarshalledStructuredResult(response), nil
 		}
 }
 
@@ -1188,6 +1224,7 @@ func UpdateIssue(getClient GetClientFn, getGQLClient GetGQLClientFn, t translati
 				Title:        t("TOOL_UPDATE_ISSUE_USER_TITLE", "Edit issue"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -1458,6 +1495,7 @@ func GetIssueComments(getClient GetClientFn, t translations.TranslationHelperFun
 				mcp.Description("Issue number"),
 			),
 			WithPagination(),
+			WithMaxItems(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -1476,6 +1514,10 @@ func GetIssueComments(getClient GetClientFn, t translations.TranslationHelperFun
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
+			maxItems, err := OptionalMaxItemsParam(request, &pagination.PerPage)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
 			opts := &github.IssueListCommentsOptions{
 				ListOptions: github.ListOptions{
@@ -1488,6 +1530,21 @@ func GetIssueComments(getClient GetClientFn, t translations.TranslationHelperFun
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
+
+			if maxItems > 0 {
+				result, _, err := collectPages(ctx, &opts.ListOptions, maxItems,
+					func(comment *github.IssueComment) string { return strconv.FormatInt(comment.GetID(), 10) },
+					func() ([]*github.IssueComment, *github.Response, error) {
+						return client.Issues.ListComments(ctx, owner, repo, issueNumber, opts)
+					},
+				)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get issue comments: %w", err)
+				}
+				withholdIssueComments(ctx, owner, repo, result.Items)
+				sanitize.Value(ctx, &result)
+				return MarshalledTextResult(result), nil
+			}
 			comments, resp, err := client.Issues.ListComments(ctx, owner, repo, issueNumber, opts)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get issue comments: %w", err)
@@ -1502,6 +1559,9 @@ func GetIssueComments(getClient GetClientFn, t translations.TranslationHelperFun
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get issue comments: %s", string(body))), nil
 			}
 
+			withholdIssueComments(ctx, owner, repo, comments)
+
+			sanitize.Value(ctx, comments)
 			r, err := json.Marshal(comments)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -1511,6 +1571,16 @@ func GetIssueComments(getClient GetClientFn, t translations.TranslationHelperFun
 		}
 }
 
+// withholdIssueComments withholds the bodies of the comments in owner/repo written by users who cannot push to
+// it, in lockdown mode.
+func withholdIssueComments(ctx context.Context, owner, repo string, comments []*github.IssueComment) {
+	for _, comment := range comments {
+		if comment.Body != nil {
+			comment.Body = github.Ptr(lockdown.Withhold(ctx, owner, repo, comment.GetUser().GetLogin(), comment.GetBody()))
+		}
+	}
+}
+
 // mvpDescription is an MVP idea for generating tool descriptions from structured data in a shared format.
 // It is not intended for widespread usage and is not a complete implementation.
 type mvpDescription struct {
@@ -1559,6 +1629,7 @@ func AssignCopilotToIssue(getGQLClient GetGQLClientFn, t translations.Translatio
 				ReadOnlyHint:   ToBoolPtr(false),
 				IdempotentHint: ToBoolPtr(true),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/github/github-mcp-server/internal/githubv4mock"
 	"github.com/github/github-mcp-server/internal/toolsnaps"
+	"github.com/github/github-mcp-server/pkg/cassette"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/google/go-github/v74/github"
 	"github.com/migueleliasweb/go-github-mock/src/mock"
@@ -122,6 +124,35 @@ func Test_GetIssue(t *testing.T) {
 	}
 }
 
+func Test_GetIssueFromCassette(t *testing.T) {
+	// The responses recorded with --record stand in for the API
+	player, err := cassette.NewPlayer("testdata/cassettes/get_issue.jsonl")
+	require.NoError(t, err)
+	client := github.NewClient(&http.Client{Transport: player})
+	_, handler := GetIssue(stubGetClientFn(client), translations.NullTranslationHelper)
+
+	result, err := handler(context.Background(), createMCPRequest(map[string]any{
+		"owner":        "octocat",
+		"repo":         "hello-world",
+		"issue_number": float64(42),
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var issue github.Issue
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &issue))
+	assert.Equal(t, "Login fails with two-factor authentication", issue.GetTitle())
+	assert.Equal(t, "octocat", issue.GetUser().GetLogin())
+
+	// Requests that were not recorded fail
+	_, err = handler(context.Background(), createMCPRequest(map[string]any{
+		"owner":        "octocat",
+		"repo":         "hello-world",
+		"issue_number": float64(43),
+	}))
+	assert.ErrorContains(t, err, "no recorded response for GET https://api.github.com/repos/octocat/hello-world/issues/43")
+}
+
 func Test_AddIssueComment(t *testing.T) {
 	// Verify tool definition once
 	mockClient := github.NewClient(nil)
@@ -1012,6 +1043,7 @@ func Test_ListIssues(t *testing.T) {
 			}
 			err = json.Unmarshal([]byte(text), &response)
 			require.NoError(t, err)
+			assert.JSONEq(t, text, getStructuredResult(t, res))
 
 			assert.Len(t, response.Issues, tc.expectedCount, "Expected %d issues, got %d", tc.expectedCount, len(response.Issues))
 
@@ -1030,6 +1062,76 @@ func Test_ListIssues(t *testing.T) {
 	}
 }
 
+func Test_ListIssuesMaxItems(t *testing.T) {
+	issue := func(number int) map[string]any {
+		return map[string]any{
+			"number":     number,
+			"title":      fmt.Sprintf("Issue %d", number),
+			"body":       "",
+			"state":      "OPEN",
+			"databaseId": 1000 + number,
+			"createdAt":  "2023-01-01T00:00:00Z",
+			"updatedAt":  "2023-01-01T00:00:00Z",
+			"author":     map[string]any{"login": "user1"},
+			"labels":     map[string]any{"nodes": []map[string]any{}},
+			"comments":   map[string]any{"totalCount": 0},
+		}
+	}
+
+	// Each page is answered according to its cursor, recording how many issues it asked for
+	var firsts []float64
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Variables map[string]any `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		firsts = append(firsts, request.Variables["first"].(float64))
+
+		nodes, startCursor, endCursor := []map[string]any{issue(1), issue(2)}, "c1", "c2"
+		if request.Variables["after"] == "c2" {
+			// The list changed in between, so the second issue is listed again
+			nodes, startCursor, endCursor = []map[string]any{issue(2), issue(3)}, "c2", "c3"
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+			"repository": map[string]any{
+				"issues": map[string]any{
+					"nodes": nodes,
+					"pageInfo": map[string]any{
+						"hasNextPage":     true,
+						"hasPreviousPage": false,
+						"startCursor":     startCursor,
+						"endCursor":       endCursor,
+					},
+					"totalCount": 10,
+				},
+			},
+		}})
+	}))
+	t.Cleanup(api.Close)
+
+	_, handler := ListIssues(stubGetGQLClientFn(githubv4.NewEnterpriseClient(api.URL, api.Client())), translations.NullTranslationHelper)
+	result, err := handler(context.Background(), createMCPRequest(map[string]any{
+		"owner":     "owner",
+		"repo":      "repo",
+		"perPage":   float64(2),
+		"max_items": float64(3),
+	}))
+	require.NoError(t, err)
+	require.False(t, result.IsError, getTextResult(t, result).Text)
+
+	var response MinimalListIssuesResult
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &response))
+	require.Len(t, response.Issues, 3)
+	for i, issue := range response.Issues {
+		assert.Equal(t, i+1, issue.Number)
+	}
+	assert.Equal(t, MinimalPageInfo{HasNextPage: true, StartCursor: "c1", EndCursor: "c3"}, response.PageInfo)
+	assert.Equal(t, 10, response.TotalCount)
+
+	// The second page only asks for the issue still missing
+	assert.Equal(t, []float64{2, 1}, firsts)
+}
+
 func Test_UpdateIssue(t *testing.T) {
 	// Verify tool definition
 	mockClient := github.NewClient(nil)
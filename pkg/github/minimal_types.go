@@ -40,7 +40,7 @@ type MinimalRepository struct {
 
 // MinimalSearchRepositoriesResult is the trimmed output type for repository search results.
 type MinimalSearchRepositoriesResult struct {
-	TotalCount        int                 `json:"total_count"`
+	TotalCount        *int                `json:"total_count,omitempty"`
 	IncompleteResults bool                `json:"incomplete_results"`
 	Items             []MinimalRepository `json:"items"`
 }
@@ -99,6 +99,95 @@ type MinimalRelease struct {
 	Author      *MinimalUser `json:"author,omitempty"`
 }
 
+// MinimalCommitsResult is the structured output type for commit lists, as tool outputs must be objects.
+type MinimalCommitsResult struct {
+	Commits []MinimalCommit `json:"commits"`
+	// HasMore and NextPage are only set when commits are collected across pages, see PagedResult
+	HasMore  bool `json:"hasMore,omitempty"`
+	NextPage int  `json:"nextPage,omitempty"`
+}
+
+// MinimalLabel is the trimmed output type for label objects.
+type MinimalLabel struct {
+	Name        string `json:"name"`
+	NodeID      string `json:"node_id,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// MinimalIssue is the trimmed output type for issue objects.
+type MinimalIssue struct {
+	ID        int64          `json:"id"`
+	Number    int            `json:"number"`
+	Title     string         `json:"title"`
+	Body      string         `json:"body,omitempty"`
+	State     string         `json:"state"`
+	User      *MinimalUser   `json:"user,omitempty"`
+	Labels    []MinimalLabel `json:"labels,omitempty"`
+	Comments  int            `json:"comments"`
+	CreatedAt string         `json:"created_at,omitempty"`
+	UpdatedAt string         `json:"updated_at,omitempty"`
+}
+
+// MinimalPageInfo is the cursor-based pagination information of GraphQL lists.
+type MinimalPageInfo struct {
+	HasNextPage     bool   `json:"hasNextPage"`
+	HasPreviousPage bool   `json:"hasPreviousPage"`
+	StartCursor     string `json:"startCursor"`
+	EndCursor       string `json:"endCursor"`
+}
+
+// MinimalListIssuesResult is the trimmed output type for issue lists.
+type MinimalListIssuesResult struct {
+	Issues     []MinimalIssue  `json:"issues"`
+	PageInfo   MinimalPageInfo `json:"pageInfo"`
+	TotalCount int             `json:"totalCount"`
+}
+
+// MinimalPullRequestRepo identifies the repository of a pull request branch.
+type MinimalPullRequestRepo struct {
+	FullName string `json:"full_name"`
+}
+
+// MinimalPullRequestBranch is the head or base branch of a pull request.
+type MinimalPullRequestBranch struct {
+	Label string                  `json:"label,omitempty"`
+	Ref   string                  `json:"ref"`
+	SHA   string                  `json:"sha"`
+	Repo  *MinimalPullRequestRepo `json:"repo,omitempty"`
+}
+
+// MinimalPullRequest is the trimmed output type for pull request objects.
+type MinimalPullRequest struct {
+	ID                  int64                     `json:"id"`
+	Number              int                       `json:"number"`
+	Title               string                    `json:"title"`
+	Body                string                    `json:"body,omitempty"`
+	State               string                    `json:"state"`
+	Draft               bool                      `json:"draft"`
+	Merged              bool                      `json:"merged"`
+	Mergeable           *bool                     `json:"mergeable,omitempty"`
+	MergeableState      string                    `json:"mergeable_state,omitempty"`
+	HTMLURL             string                    `json:"html_url"`
+	User                *MinimalUser              `json:"user,omitempty"`
+	Labels              []MinimalLabel            `json:"labels,omitempty"`
+	Assignees           []MinimalUser             `json:"assignees,omitempty"`
+	RequestedReviewers  []MinimalUser             `json:"requested_reviewers,omitempty"`
+	Head                *MinimalPullRequestBranch `json:"head,omitempty"`
+	Base                *MinimalPullRequestBranch `json:"base,omitempty"`
+	Commits             int                       `json:"commits,omitempty"`
+	Additions           int                       `json:"additions,omitempty"`
+	Deletions           int                       `json:"deletions,omitempty"`
+	ChangedFiles        int                       `json:"changed_files,omitempty"`
+	Comments            int                       `json:"comments,omitempty"`
+	ReviewComments      int                       `json:"review_comments,omitempty"`
+	MaintainerCanModify bool                      `json:"maintainer_can_modify"`
+	CreatedAt           string                    `json:"created_at,omitempty"`
+	UpdatedAt           string                    `json:"updated_at,omitempty"`
+	ClosedAt            string                    `json:"closed_at,omitempty"`
+	MergedAt            string                    `json:"merged_at,omitempty"`
+	MergedBy            *MinimalUser              `json:"merged_by,omitempty"`
+}
+
 // MinimalBranch is the trimmed output type for branch objects.
 type MinimalBranch struct {
 	Name      string `json:"name"`
@@ -292,3 +381,75 @@ func convertToMinimalBranch(branch *github.Branch) MinimalBranch {
 		Protected: branch.GetProtected(),
 	}
 }
+
+// convertToMinimalPullRequest converts a GitHub API PullRequest to MinimalPullRequest
+func convertToMinimalPullRequest(pr *github.PullRequest) MinimalPullRequest {
+	minimalPR := MinimalPullRequest{
+		ID:                  pr.GetID(),
+		Number:              pr.GetNumber(),
+		Title:               pr.GetTitle(),
+		Body:                pr.GetBody(),
+		State:               pr.GetState(),
+		Draft:               pr.GetDraft(),
+		Merged:              pr.GetMerged(),
+		Mergeable:           pr.Mergeable,
+		MergeableState:      pr.GetMergeableState(),
+		HTMLURL:             pr.GetHTMLURL(),
+		User:                convertToMinimalUser(pr.User),
+		Head:                convertToMinimalPullRequestBranch(pr.Head),
+		Base:                convertToMinimalPullRequestBranch(pr.Base),
+		Commits:             pr.GetCommits(),
+		Additions:           pr.GetAdditions(),
+		Deletions:           pr.GetDeletions(),
+		ChangedFiles:        pr.GetChangedFiles(),
+		Comments:            pr.GetComments(),
+		ReviewComments:      pr.GetReviewComments(),
+		MaintainerCanModify: pr.GetMaintainerCanModify(),
+		MergedBy:            convertToMinimalUser(pr.MergedBy),
+	}
+
+	for _, label := range pr.Labels {
+		minimalPR.Labels = append(minimalPR.Labels, MinimalLabel{
+			Name:        label.GetName(),
+			NodeID:      label.GetNodeID(),
+			Description: label.GetDescription(),
+		})
+	}
+	for _, assignee := range pr.Assignees {
+		minimalPR.Assignees = append(minimalPR.Assignees, *convertToMinimalUser(assignee))
+	}
+	for _, reviewer := range pr.RequestedReviewers {
+		minimalPR.RequestedReviewers = append(minimalPR.RequestedReviewers, *convertToMinimalUser(reviewer))
+	}
+
+	if pr.CreatedAt != nil {
+		minimalPR.CreatedAt = pr.CreatedAt.Format("2006-01-02T15:04:05Z")
+	}
+	if pr.UpdatedAt != nil {
+		minimalPR.UpdatedAt = pr.UpdatedAt.Format("2006-01-02T15:04:05Z")
+	}
+	if pr.ClosedAt != nil {
+		minimalPR.ClosedAt = pr.ClosedAt.Format("2006-01-02T15:04:05Z")
+	}
+	if pr.MergedAt != nil {
+		minimalPR.MergedAt = pr.MergedAt.Format("2006-01-02T15:04:05Z")
+	}
+
+	return minimalPR
+}
+
+func convertToMinimalPullRequestBranch(branch *github.PullRequestBranch) *MinimalPullRequestBranch {
+	if branch == nil {
+		return nil
+	}
+
+	minimalBranch := &MinimalPullRequestBranch{
+		Label: branch.GetLabel(),
+		Ref:   branch.GetRef(),
+		SHA:   branch.GetSHA(),
+	}
+	if branch.Repo != nil {
+		minimalBranch.Repo = &MinimalPullRequestRepo{FullName: branch.Repo.GetFullName()}
+	}
+	return minimalBranch
+}
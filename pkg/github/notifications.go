@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/policy"
+	"github.com/github/github-mcp-server/pkg/sanitize"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/google/go-github/v74/github"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -30,6 +33,7 @@ func ListNotifications(getClient GetClientFn, t translations.TranslationHelperFu
 				Title:        t("TOOL_LIST_NOTIFICATIONS_USER_TITLE", "List notifications"),
 				ReadOnlyHint: ToBoolPtr(true),
 			}),
+			withRequiredScopes(ScopeNotifications, ScopeRepo),
 			mcp.WithString("filter",
 				mcp.Description("Filter notifications to, use default unless specified. Read notifications are ones that have already been acknowledged by the user. Participating notifications are those that the user is directly involved in, such as issues or pull requests they have commented on or created."),
 				mcp.Enum(FilterDefault, FilterIncludeRead, FilterOnlyParticipating),
@@ -47,6 +51,7 @@ func ListNotifications(getClient GetClientFn, t translations.TranslationHelperFu
 				mcp.Description("Optional repository name. If provided with owner, only notifications for this repository are listed."),
 			),
 			WithPagination(),
+			WithMaxItems(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			client, err := getClient(ctx)
@@ -82,6 +87,10 @@ func ListNotifications(getClient GetClientFn, t translations.TranslationHelperFu
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
+			maxItems, err := OptionalMaxItemsParam(request, &paginationParams.PerPage)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
 			// Build options
 			opts := &github.NotificationListOptions{
@@ -110,14 +119,37 @@ func ListNotifications(getClient GetClientFn, t translations.TranslationHelperFu
 				opts.Before = beforeTime
 			}
 
-			var notifications []*github.Notification
-			var resp *github.Response
+			listNotifications := func() ([]*github.Notification, *github.Response, error) {
+				var notifications []*github.Notification
+				var resp *github.Response
+				var err error
+				if owner != "" && repo != "" {
+					notifications, resp, err = client.Activity.ListRepositoryNotifications(ctx, owner, repo, opts)
+				} else {
+					notifications, resp, err = client.Activity.ListNotifications(ctx, opts)
+				}
+				// Leave out the notifications from repositories the access policy denies, if any
+				if p := policy.FromContext(ctx); p != nil {
+					notifications = slices.DeleteFunc(notifications, func(notification *github.Notification) bool {
+						return !p.AllowsFullName(notification.GetRepository().GetFullName())
+					})
+				}
+				return notifications, resp, err
+			}
 
-			if owner != "" && repo != "" {
-				notifications, resp, err = client.Activity.ListRepositoryNotifications(ctx, owner, repo, opts)
-			} else {
-				notifications, resp, err = client.Activity.ListNotifications(ctx, opts)
+			if maxItems > 0 {
+				result, resp, err := collectPages(ctx, &opts.ListOptions, maxItems,
+					func(notification *github.Notification) string { return notification.GetID() },
+					listNotifications,
+				)
+				if err != nil {
+					return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to list notifications", resp, err), nil
+				}
+				sanitize.Value(ctx, &result)
+				return MarshalledTextResult(result), nil
 			}
+
+			notifications, resp, err := listNotifications()
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
 					"failed to list notifications",
@@ -135,6 +167,7 @@ func ListNotifications(getClient GetClientFn, t translations.TranslationHelperFu
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get notifications: %s", string(body))), nil
 			}
 
+			sanitize.Value(ctx, notifications)
 			// Marshal response to JSON
 			r, err := json.Marshal(notifications)
 			if err != nil {
@@ -153,6 +186,7 @@ func DismissNotification(getclient GetClientFn, t translations.TranslationHelper
 				Title:        t("TOOL_DISMISS_NOTIFICATION_USER_TITLE", "Dismiss notification"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeNotifications, ScopeRepo),
 			mcp.WithString("threadID",
 				mcp.Required(),
 				mcp.Description("The ID of the notification thread"),
@@ -175,6 +209,10 @@ func DismissNotification(getclient GetClientFn, t translations.TranslationHelper
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
+			if result := checkNotificationThread(ctx, client, threadID); result != nil {
+				return result, nil
+			}
+
 			var resp *github.Response
 			switch state {
 			case "done":
@@ -220,6 +258,7 @@ func MarkAllNotificationsRead(getClient GetClientFn, t translations.TranslationH
 				Title:        t("TOOL_MARK_ALL_NOTIFICATIONS_READ_USER_TITLE", "Mark all notifications as read"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeNotifications, ScopeRepo),
 			mcp.WithString("lastReadAt",
 				mcp.Description("Describes the last point that notifications were checked (optional). Default: Now"),
 			),
@@ -265,9 +304,12 @@ func MarkAllNotificationsRead(getClient GetClientFn, t translations.TranslationH
 			}
 
 			var resp *github.Response
-			if owner != "" && repo != "" {
+			switch {
+			case owner != "" && repo != "":
 				resp, err = client.Activity.MarkRepositoryNotificationsRead(ctx, owner, repo, markReadOptions)
-			} else {
+			case policy.FromContext(ctx) != nil:
+				return markAllowedNotificationsRead(ctx, client, markReadOptions)
+			default:
 				resp, err = client.Activity.MarkNotificationsRead(ctx, markReadOptions)
 			}
 			if err != nil {
@@ -291,6 +333,85 @@ func MarkAllNotificationsRead(getClient GetClientFn, t translations.TranslationH
 		}
 }
 
+// markAllowedNotificationsRead marks the notifications from the repositories the access policy of the call made
+// with ctx allows as read, one repository at a time, leaving those from denied repositories unread.
+func markAllowedNotificationsRead(ctx context.Context, client *github.Client, lastReadAt github.Timestamp) (*mcp.CallToolResult, error) {
+	p := policy.FromContext(ctx)
+
+	var repos []*github.Repository
+	opts := &github.NotificationListOptions{
+		Before:      lastReadAt.Time,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	for {
+		notifications, resp, err := client.Activity.ListNotifications(ctx, opts)
+		if err != nil {
+			return ghErrors.NewGitHubAPIErrorResponse(ctx,
+				"failed to list notifications to mark as read",
+				resp,
+				err,
+			), nil
+		}
+		_ = resp.Body.Close()
+
+		for _, notification := range notifications {
+			repo := notification.GetRepository()
+			if p.AllowsFullName(repo.GetFullName()) && !slices.ContainsFunc(repos, func(r *github.Repository) bool {
+				return r.GetFullName() == repo.GetFullName()
+			}) {
+				repos = append(repos, repo)
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	for _, repo := range repos {
+		resp, err := client.Activity.MarkRepositoryNotificationsRead(ctx, repo.GetOwner().GetLogin(), repo.GetName(), lastReadAt)
+		if err != nil {
+			return ghErrors.NewGitHubAPIErrorResponse(ctx,
+				fmt.Sprintf("failed to mark notifications from %s as read", repo.GetFullName()),
+				resp,
+				err,
+			), nil
+		}
+		_ = resp.Body.Close()
+	}
+
+	return mcp.NewToolResultText("All notifications from the repositories allowed by the repository access policy marked as read"), nil
+}
+
+// checkNotificationThread rejects acting on a notification thread from a repository the access policy of the call
+// made with ctx denies, if any. The thread is fetched to find its repository, which its ID alone does not tell.
+func checkNotificationThread(ctx context.Context, client *github.Client, threadID string) *mcp.CallToolResult {
+	if policy.FromContext(ctx) == nil {
+		return nil
+	}
+
+	thread, resp, err := client.Activity.GetThread(ctx, threadID)
+	if err != nil {
+		return ghErrors.NewGitHubAPIErrorResponse(ctx,
+			fmt.Sprintf("failed to get notification thread '%s'", threadID),
+			resp,
+			err,
+		)
+	}
+	_ = resp.Body.Close()
+
+	return checkThreadRepository(ctx, thread)
+}
+
+// checkThreadRepository rejects a notification thread from a repository the access policy of the call made with ctx
+// denies, if any.
+func checkThreadRepository(ctx context.Context, thread *github.Notification) *mcp.CallToolResult {
+	if fullName := thread.GetRepository().GetFullName(); !policy.FromContext(ctx).AllowsFullName(fullName) {
+		return mcp.NewToolResultError(fmt.Sprintf("repository %s is not allowed by the repository access policy", fullName))
+	}
+	return nil
+}
+
 // GetNotificationDetails creates a tool to get details for a specific notification.
 func GetNotificationDetails(getClient GetClientFn, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
 	return mcp.NewTool("get_notification_details",
@@ -299,6 +420,7 @@ func GetNotificationDetails(getClient GetClientFn, t translations.TranslationHel
 				Title:        t("TOOL_GET_NOTIFICATION_DETAILS_USER_TITLE", "Get notification details"),
 				ReadOnlyHint: ToBoolPtr(true),
 			}),
+			withRequiredScopes(ScopeNotifications, ScopeRepo),
 			mcp.WithString("notificationID",
 				mcp.Required(),
 				mcp.Description("The ID of the notification"),
@@ -333,6 +455,11 @@ func GetNotificationDetails(getClient GetClientFn, t translations.TranslationHel
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get notification details: %s", string(body))), nil
 			}
 
+			if result := checkThreadRepository(ctx, thread); result != nil {
+				return result, nil
+			}
+
+			sanitize.Value(ctx, thread)
 			r, err := json.Marshal(thread)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -357,6 +484,7 @@ func ManageNotificationSubscription(getClient GetClientFn, t translations.Transl
 				Title:        t("TOOL_MANAGE_NOTIFICATION_SUBSCRIPTION_USER_TITLE", "Manage notification subscription"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeNotifications, ScopeRepo),
 			mcp.WithString("notificationID",
 				mcp.Required(),
 				mcp.Description("The ID of the notification thread."),
@@ -382,6 +510,10 @@ func ManageNotificationSubscription(getClient GetClientFn, t translations.Transl
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
+			if result := checkNotificationThread(ctx, client, notificationID); result != nil {
+				return result, nil
+			}
+
 			var (
 				resp   *github.Response
 				result any
@@ -442,6 +574,7 @@ func ManageRepositoryNotificationSubscription(getClient GetClientFn, t translati
 				Title:        t("TOOL_MANAGE_REPOSITORY_NOTIFICATION_SUBSCRIPTION_USER_TITLE", "Manage repository notification subscription"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeNotifications, ScopeRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("The account owner of the repository."),
@@ -7,8 +7,10 @@ import (
 	"testing"
 
 	"github.com/github/github-mcp-server/internal/toolsnaps"
+	"github.com/github/github-mcp-server/pkg/policy"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/google/go-github/v74/github"
+	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/migueleliasweb/go-github-mock/src/mock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -149,6 +151,129 @@ func Test_ListNotifications(t *testing.T) {
 	}
 }
 
+func Test_ListNotificationsWithPolicy(t *testing.T) {
+	repoPolicy, err := policy.New([]string{"myorg/*"}, []string{"myorg/secrets-*"})
+	require.NoError(t, err)
+
+	notifications := []*github.Notification{
+		{ID: github.Ptr("1"), Repository: &github.Repository{FullName: github.Ptr("myorg/service")}},
+		{ID: github.Ptr("2"), Repository: &github.Repository{FullName: github.Ptr("myorg/secrets-prod")}},
+		{ID: github.Ptr("3"), Repository: &github.Repository{FullName: github.Ptr("other/service")}},
+	}
+	mockedClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatch(mock.GetNotifications, notifications),
+	)
+	_, handler := ListNotifications(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+	ctx := policy.ContextWithPolicy(context.Background(), repoPolicy)
+
+	// Without owner and repo, the notifications from denied repositories are filtered out
+	result, err := handler(ctx, createMCPRequest(map[string]interface{}{}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var returned []*github.Notification
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &returned))
+	require.Len(t, returned, 1)
+	assert.Equal(t, "1", returned[0].GetID())
+}
+
+func Test_NotificationThreadsWithPolicy(t *testing.T) {
+	repoPolicy, err := policy.New([]string{"myorg/*"}, []string{"myorg/secrets-*"})
+	require.NoError(t, err)
+	ctx := policy.ContextWithPolicy(context.Background(), repoPolicy)
+
+	// Thread 1 is from an allowed repository, thread 2 from a denied one
+	newRepo := func(owner, name string) *github.Repository {
+		return &github.Repository{Name: github.Ptr(name), FullName: github.Ptr(owner + "/" + name), Owner: &github.User{Login: github.Ptr(owner)}}
+	}
+	threads := map[string]*github.Notification{
+		"1": {ID: github.Ptr("1"), Repository: newRepo("myorg", "service")},
+		"2": {ID: github.Ptr("2"), Repository: newRepo("myorg", "secrets-prod")},
+	}
+	var changed []string
+	mockedClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetNotificationsThreadsByThreadId,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mockResponse(t, http.StatusOK, threads[r.URL.Path[len("/notifications/threads/"):]])(w, r)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PatchNotificationsThreadsByThreadId,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				changed = append(changed, r.Method+" "+r.URL.Path)
+				w.WriteHeader(http.StatusResetContent)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PutNotificationsThreadsSubscriptionByThreadId,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				changed = append(changed, r.Method+" "+r.URL.Path)
+				mockResponse(t, http.StatusOK, &github.Subscription{Ignored: github.Ptr(true)})(w, r)
+			}),
+		),
+		mock.WithRequestMatch(
+			mock.GetNotifications,
+			[]*github.Notification{threads["1"], threads["2"], {ID: github.Ptr("3"), Repository: newRepo("myorg", "service")}},
+		),
+		mock.WithRequestMatchHandler(
+			mock.PutReposNotificationsByOwnerByRepo,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				changed = append(changed, r.Method+" "+r.URL.Path)
+				w.WriteHeader(http.StatusResetContent)
+			}),
+		),
+		mock.WithRequestMatchHandler(
+			mock.PutNotifications,
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				changed = append(changed, r.Method+" "+r.URL.Path)
+				w.WriteHeader(http.StatusResetContent)
+			}),
+		),
+	)
+	getClient := stubGetClientFn(github.NewClient(mockedClient))
+
+	_, getDetails := GetNotificationDetails(getClient, translations.NullTranslationHelper)
+	_, dismiss := DismissNotification(getClient, translations.NullTranslationHelper)
+	_, manageSubscription := ManageNotificationSubscription(getClient, translations.NullTranslationHelper)
+	_, markAllRead := MarkAllNotificationsRead(getClient, translations.NullTranslationHelper)
+
+	calls := []struct {
+		handler func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error)
+		args    func(threadID string) map[string]interface{}
+	}{
+		{getDetails, func(threadID string) map[string]interface{} {
+			return map[string]interface{}{"notificationID": threadID}
+		}},
+		{dismiss, func(threadID string) map[string]interface{} {
+			return map[string]interface{}{"threadID": threadID, "state": "read"}
+		}},
+		{manageSubscription, func(threadID string) map[string]interface{} {
+			return map[string]interface{}{"notificationID": threadID, "action": "ignore"}
+		}},
+	}
+	for _, call := range calls {
+		result, err := call.handler(ctx, createMCPRequest(call.args("1")))
+		require.NoError(t, err)
+		assert.False(t, result.IsError, getTextResult(t, result).Text)
+
+		result, err = call.handler(ctx, createMCPRequest(call.args("2")))
+		require.NoError(t, err)
+		assert.Equal(t, "repository myorg/secrets-prod is not allowed by the repository access policy", getErrorResult(t, result).Text)
+	}
+
+	// Marking all notifications as read only marks those from allowed repositories, once for each repository
+	result, err := markAllRead(ctx, createMCPRequest(map[string]interface{}{}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	assert.Equal(t, []string{
+		"PATCH /notifications/threads/1",
+		"PUT /notifications/threads/1/subscription",
+		"PUT /repos/myorg/service/notifications",
+	}, changed)
+}
+
 func Test_ManageNotificationSubscription(t *testing.T) {
 	// Verify tool definition and schema
 	mockClient := github.NewClient(nil)
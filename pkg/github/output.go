@@ -0,0 +1,41 @@
+package github
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/github/github-mcp-server/pkg/budget"
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// GetMoreOutput creates a tool to get the rest of a tool result truncated by outputBudget. The remainder is
+// truncated in turn, so that large results are paged through one budget at a time.
+func GetMoreOutput(outputBudget *budget.Budget, t translations.TranslationHelperFunc) (tool mcp.Tool, handler server.ToolHandlerFunc) {
+	return mcp.NewTool(budget.MoreOutputTool,
+			mcp.WithDescription(t("TOOL_GET_MORE_OUTPUT_DESCRIPTION", "Get the rest of a tool result that was truncated for its size, using the continuation token given at its end. Each token can only be used once, and expires after a few minutes.")),
+			mcp.WithToolAnnotation(mcp.ToolAnnotation{
+				Title: t("TOOL_GET_MORE_OUTPUT_USER_TITLE", "Get more output"),
+				// Not reading GitHub data, only what was already read
+				ReadOnlyHint: ToBoolPtr(true),
+			}),
+			mcp.WithString("continuation_token",
+				mcp.Required(),
+				mcp.Description("The continuation token given at the end of the truncated result"),
+			),
+		),
+		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			token, err := RequiredParam[string](request, "continuation_token")
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
+			remainder, ok := outputBudget.Remainder(toolsets.SessionIDFromContext(ctx), token)
+			if !ok {
+				return mcp.NewToolResultError(fmt.Sprintf("continuation token %s is unknown or has expired, call the tool that gave it again", token)), nil
+			}
+			return mcp.NewToolResultText(remainder), nil
+		}
+}
@@ -0,0 +1,75 @@
+package github
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/github/github-mcp-server/internal/toolsnaps"
+	"github.com/github/github-mcp-server/pkg/budget"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GetMoreOutput(t *testing.T) {
+	outputBudget := budget.New(8)
+	tool, handler := GetMoreOutput(outputBudget, translations.NullTranslationHelper)
+	require.NoError(t, toolsnaps.Test(tool.Name, tool))
+
+	assert.Equal(t, "get_more_output", tool.Name)
+	assert.True(t, *tool.Annotations.ReadOnlyHint, "get_more_output tool should be read-only")
+	assert.Contains(t, tool.InputSchema.Properties, "continuation_token")
+	assert.ElementsMatch(t, tool.InputSchema.Required, []string{"continuation_token"})
+
+	// Truncate a result to get a continuation token
+	truncated, err := outputBudget.ToolHandlerMiddleware(func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultText("line 1\nline 2\n"), nil
+	})(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.Len(t, truncated.Content, 2)
+	token := regexp.MustCompile(`continuation_token "([0-9a-f]+)"`).FindStringSubmatch(truncated.Content[1].(mcp.TextContent).Text)[1]
+
+	tests := []struct {
+		name           string
+		requestArgs    map[string]any
+		expectError    bool
+		expectedText   string
+		expectedErrMsg string
+	}{
+		{
+			name:         "remainder",
+			requestArgs:  map[string]any{"continuation_token": token},
+			expectedText: "line 2\n",
+		},
+		{
+			name:           "token already used",
+			requestArgs:    map[string]any{"continuation_token": token},
+			expectError:    true,
+			expectedErrMsg: "continuation token " + token + " is unknown or has expired",
+		},
+		{
+			name:           "missing token",
+			requestArgs:    map[string]any{},
+			expectError:    true,
+			expectedErrMsg: "missing required parameter: continuation_token",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := handler(context.Background(), createMCPRequest(tc.requestArgs))
+			require.NoError(t, err)
+
+			if tc.expectError {
+				errorContent := getErrorResult(t, result)
+				assert.Contains(t, errorContent.Text, tc.expectedErrMsg)
+				return
+			}
+
+			textContent := getTextResult(t, result)
+			assert.Equal(t, tc.expectedText, textContent.Text)
+		})
+	}
+}
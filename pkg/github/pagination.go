@@ -0,0 +1,96 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/github/github-mcp-server/pkg/budget"
+	"github.com/google/go-github/v74/github"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// MaxItemsLimit bounds the number of items a single call can collect across pages.
+const MaxItemsLimit = 1000
+
+// WithMaxItems adds the max_items parameter to a paginated tool, to collect the items of several pages at once.
+func WithMaxItems() mcp.ToolOption {
+	return mcp.WithNumber("max_items",
+		mcp.Description(fmt.Sprintf("Collect up to this many items across pages, starting from the given page, instead of returning a single page. Collection stops early once the result is too large to be returned whole. The result tells whether more items remain (max %d)", MaxItemsLimit)),
+		mcp.Min(1),
+		mcp.Max(MaxItemsLimit),
+	)
+}
+
+// OptionalMaxItemsParam returns the "max_items" parameter from the request, or 0 if not present. Unless the
+// "perPage" parameter is present, perPage is set to collect the items in as few pages as possible.
+func OptionalMaxItemsParam(r mcp.CallToolRequest, perPage *int) (int, error) {
+	maxItems, err := OptionalIntParam(r, "max_items")
+	if err != nil {
+		return 0, err
+	}
+	if maxItems < 0 || maxItems > MaxItemsLimit {
+		return 0, fmt.Errorf("max_items must be between 1 and %d", MaxItemsLimit)
+	}
+	if _, ok := r.GetArguments()["perPage"]; maxItems > 0 && !ok {
+		*perPage = min(maxItems, 100)
+	}
+	return maxItems, nil
+}
+
+// PagedResult holds the items collected across the pages of a list, when max_items is given.
+type PagedResult[T any] struct {
+	Items []T `json:"items"`
+	// HasMore tells whether more items remain after those collected
+	HasMore bool `json:"hasMore"`
+	// NextPage is the page to continue from, which may repeat some of the items collected
+	NextPage int `json:"nextPage,omitempty"`
+}
+
+// collectPages calls list for one page after the other, following the Link headers of its responses from the
+// page opts starts at, until maxItems items are collected, no page is left, or the items collected fill the
+// result budget of the call. Items already collected from an earlier page, as happens when the list changes in
+// between, are skipped. The response to the last call is returned along with its error, if any.
+func collectPages[T any](ctx context.Context, opts *github.ListOptions, maxItems int, key func(T) string, list func() ([]T, *github.Response, error)) (PagedResult[T], *github.Response, error) {
+	result := PagedResult[T]{Items: []T{}}
+	seen := make(map[string]bool)
+	if opts.Page == 0 {
+		opts.Page = 1
+	}
+	for {
+		items, resp, err := list()
+		if err != nil {
+			return result, resp, err
+		}
+		for _, item := range items {
+			if len(result.Items) == maxItems {
+				result.HasMore, result.NextPage = true, opts.Page
+				return result, resp, nil
+			}
+			if k := key(item); !seen[k] {
+				seen[k] = true
+				result.Items = append(result.Items, item)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			return result, resp, nil
+		}
+		if len(result.Items) == maxItems || exceedsBudget(ctx, result.Items) {
+			result.HasMore, result.NextPage = true, resp.NextPage
+			return result, resp, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// exceedsBudget tells whether v takes up the budget of the result of the call made with ctx, if results are
+// truncated.
+func exceedsBudget(ctx context.Context, v any) bool {
+	maxBytes, ok := budget.MaxBytes(ctx)
+	if !ok {
+		return false
+	}
+	data, err := json.Marshal(v)
+	return err == nil && len(data) >= maxBytes
+}
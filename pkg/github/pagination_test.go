@@ -0,0 +1,152 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/budget"
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v74/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_MaxItems(t *testing.T) {
+	// Three pages of branches, the second listing the last branch of the first again as if it had changed
+	pages := map[string][]string{
+		"1": {"main", "feature-a"},
+		"2": {"feature-a", "feature-b"},
+		"3": {"feature-c"},
+	}
+	var requested []url.Values
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		requested = append(requested, query)
+
+		page := query.Get("page")
+		if next := map[string]string{"1": "2", "2": "3"}[page]; next != "" {
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s?page=%s>; rel="next"`, "http://"+r.Host, r.URL.Path, next))
+		}
+		branches := []*github.Branch{}
+		for _, name := range pages[page] {
+			branches = append(branches, &github.Branch{Name: github.Ptr(name), Commit: &github.RepositoryCommit{SHA: github.Ptr("abc123")}})
+		}
+		_ = json.NewEncoder(w).Encode(branches)
+	}))
+	t.Cleanup(api.Close)
+
+	client := github.NewClient(api.Client())
+	client.BaseURL, _ = url.Parse(api.URL + "/")
+	_, handler := ListBranches(stubGetClientFn(client), translations.NullTranslationHelper)
+
+	tests := []struct {
+		name              string
+		requestArgs       map[string]any
+		maxBytes          int
+		expectedBranches  []string
+		expectedHasMore   bool
+		expectedNextPage  int
+		expectedPerPages  []string
+		expectedErrMsg    string
+		expectedResultErr bool
+	}{
+		{
+			name:             "every page",
+			requestArgs:      map[string]any{"owner": "owner", "repo": "repo", "perPage": float64(2), "max_items": float64(10)},
+			expectedBranches: []string{"main", "feature-a", "feature-b", "feature-c"},
+			expectedPerPages: []string{"2", "2", "2"},
+		},
+		{
+			name:             "up to max_items",
+			requestArgs:      map[string]any{"owner": "owner", "repo": "repo", "perPage": float64(2), "max_items": float64(3)},
+			expectedBranches: []string{"main", "feature-a", "feature-b"},
+			expectedHasMore:  true,
+			expectedNextPage: 3,
+			expectedPerPages: []string{"2", "2"},
+		},
+		{
+			name:             "within a page",
+			requestArgs:      map[string]any{"owner": "owner", "repo": "repo", "page": float64(2), "perPage": float64(2), "max_items": float64(1)},
+			expectedBranches: []string{"feature-a"},
+			expectedHasMore:  true,
+			expectedNextPage: 2,
+			expectedPerPages: []string{"2"},
+		},
+		{
+			name:             "pages as large as max_items",
+			requestArgs:      map[string]any{"owner": "owner", "repo": "repo", "max_items": float64(2)},
+			expectedBranches: []string{"main", "feature-a"},
+			expectedHasMore:  true,
+			expectedNextPage: 2,
+			expectedPerPages: []string{"2"},
+		},
+		{
+			name:             "up to the result budget",
+			requestArgs:      map[string]any{"owner": "owner", "repo": "repo", "perPage": float64(2), "max_items": float64(10)},
+			maxBytes:         140,
+			expectedBranches: []string{"main", "feature-a", "feature-b"},
+			expectedHasMore:  true,
+			expectedNextPage: 3,
+			expectedPerPages: []string{"2", "2"},
+		},
+		{
+			name:              "max_items out of range",
+			requestArgs:       map[string]any{"owner": "owner", "repo": "repo", "max_items": float64(MaxItemsLimit + 1)},
+			expectedResultErr: true,
+			expectedErrMsg:    "max_items must be between 1 and 1000",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			requested = nil
+
+			h := handler
+			outputBudget := budget.New(tc.maxBytes)
+			if tc.maxBytes > 0 {
+				h = outputBudget.ToolHandlerMiddleware(handler)
+			}
+			result, err := h(context.Background(), createMCPRequest(tc.requestArgs))
+			require.NoError(t, err)
+
+			if tc.expectedResultErr {
+				errorContent := getErrorResult(t, result)
+				assert.Equal(t, tc.expectedErrMsg, errorContent.Text)
+				return
+			}
+			require.False(t, result.IsError)
+
+			// The result is still truncated to the budget, only the items past it are not collected
+			text := result.Content[0].(mcp.TextContent).Text
+			if len(result.Content) > 1 {
+				token := regexp.MustCompile(`continuation_token "([0-9a-f]+)"`).FindStringSubmatch(result.Content[1].(mcp.TextContent).Text)[1]
+				remainder, ok := outputBudget.Remainder("", token)
+				require.True(t, ok)
+				text += remainder
+			}
+
+			var paged PagedResult[MinimalBranch]
+			require.NoError(t, json.Unmarshal([]byte(text), &paged))
+			names := make([]string, 0, len(paged.Items))
+			for _, branch := range paged.Items {
+				names = append(names, branch.Name)
+			}
+			assert.Equal(t, tc.expectedBranches, names)
+			assert.Equal(t, tc.expectedHasMore, paged.HasMore)
+			assert.Equal(t, tc.expectedNextPage, paged.NextPage)
+
+			perPages := make([]string, 0, len(requested))
+			for _, query := range requested {
+				perPages = append(perPages, query.Get("per_page"))
+			}
+			assert.Equal(t, tc.expectedPerPages, perPages)
+		})
+	}
+}
@@ -22,6 +22,7 @@ func ListProjects(getClient GetClientFn, t translations.TranslationHelperFunc) (
 	return mcp.NewTool("list_projects",
 			mcp.WithDescription(t("TOOL_LIST_PROJECTS_DESCRIPTION", "List Projects for a user or org")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{Title: t("TOOL_LIST_PROJECTS_USER_TITLE", "List projects"), ReadOnlyHint: ToBoolPtr(true)}),
+			withRequiredScopes(ScopeReadProject),
 			mcp.WithString("owner_type", mcp.Required(), mcp.Description("Owner type"), mcp.Enum("user", "org")),
 			mcp.WithString("owner", mcp.Required(), mcp.Description("If owner_type == user it is the handle for the GitHub user account. If owner_type == org it is the name of the organization. The name is not case sensitive.")),
 			mcp.WithString("query", mcp.Description("Filter projects by a search query (matches title and description)")),
@@ -106,6 +107,7 @@ func GetProject(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 	return mcp.NewTool("get_project",
 			mcp.WithDescription(t("TOOL_GET_PROJECT_DESCRIPTION", "Get Project for a user or org")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{Title: t("TOOL_GET_PROJECT_USER_TITLE", "Get project"), ReadOnlyHint: ToBoolPtr(true)}),
+			withRequiredScopes(ScopeReadProject),
 			mcp.WithNumber("project_number", mcp.Required(), mcp.Description("The project's number")),
 			mcp.WithString("owner_type", mcp.Required(), mcp.Description("Owner type"), mcp.Enum("user", "org")),
 			mcp.WithString("owner", mcp.Required(), mcp.Description("If owner_type == user it is the handle for the GitHub user account. If owner_type == org it is the name of the organization. The name is not case sensitive.")),
@@ -177,6 +179,7 @@ func ListProjectFields(getClient GetClientFn, t translations.TranslationHelperFu
 	return mcp.NewTool("list_project_fields",
 			mcp.WithDescription(t("TOOL_LIST_PROJECT_FIELDS_DESCRIPTION", "List Project fields for a user or org")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{Title: t("TOOL_LIST_PROJECT_FIELDS_USER_TITLE", "List project fields"), ReadOnlyHint: ToBoolPtr(true)}),
+			withRequiredScopes(ScopeReadProject),
 			mcp.WithString("owner_type", mcp.Required(), mcp.Description("Owner type"), mcp.Enum("user", "org")),
 			mcp.WithString("owner", mcp.Required(), mcp.Description("If owner_type == user it is the handle for the GitHub user account. If owner_type == org it is the name of the organization. The name is not case sensitive.")),
 			mcp.WithNumber("project_number", mcp.Required(), mcp.Description("The project's number.")),
@@ -252,6 +255,7 @@ func GetProjectField(getClient GetClientFn, t translations.TranslationHelperFunc
 	return mcp.NewTool("get_project_field",
 			mcp.WithDescription(t("TOOL_GET_PROJECT_FIELD_DESCRIPTION", "Get Project field for a user or org")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{Title: t("TOOL_GET_PROJECT_FIELD_USER_TITLE", "Get project field"), ReadOnlyHint: ToBoolPtr(true)}),
+			withRequiredScopes(ScopeReadProject),
 			mcp.WithString("owner_type", mcp.Required(), mcp.Description("Owner type"), mcp.Enum("user", "org")),
 			mcp.WithString("owner", mcp.Required(), mcp.Description("If owner_type == user it is the handle for the GitHub user account. If owner_type == org it is the name of the organization. The name is not case sensitive.")),
 			mcp.WithNumber("project_number", mcp.Required(), mcp.Description("The project's number.")),
@@ -322,6 +326,7 @@ func ListProjectItems(getClient GetClientFn, t translations.TranslationHelperFun
 	return mcp.NewTool("list_project_items",
 			mcp.WithDescription(t("TOOL_LIST_PROJECT_ITEMS_DESCRIPTION", "List Project items for a user or org")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{Title: t("TOOL_LIST_PROJECT_ITEMS_USER_TITLE", "List project items"), ReadOnlyHint: ToBoolPtr(true)}),
+			withRequiredScopes(ScopeReadProject),
 			mcp.WithString("owner_type", mcp.Required(), mcp.Description("Owner type"), mcp.Enum("user", "org")),
 			mcp.WithString("owner", mcp.Required(), mcp.Description("If owner_type == user it is the handle for the GitHub user account. If owner_type == org it is the name of the organization. The name is not case sensitive.")),
 			mcp.WithNumber("project_number", mcp.Required(), mcp.Description("The project's number.")),
@@ -409,6 +414,7 @@ func GetProjectItem(getClient GetClientFn, t translations.TranslationHelperFunc)
 	return mcp.NewTool("get_project_item",
 			mcp.WithDescription(t("TOOL_GET_PROJECT_ITEM_DESCRIPTION", "Get a specific Project item for a user or org")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{Title: t("TOOL_GET_PROJECT_ITEM_USER_TITLE", "Get project item"), ReadOnlyHint: ToBoolPtr(true)}),
+			withRequiredScopes(ScopeReadProject),
 			mcp.WithString("owner_type", mcp.Required(), mcp.Description("Owner type"), mcp.Enum("user", "org")),
 			mcp.WithString("owner", mcp.Required(), mcp.Description("If owner_type == user it is the handle for the GitHub user account. If owner_type == org it is the name of the organization. The name is not case sensitive.")),
 			mcp.WithNumber("project_number", mcp.Required(), mcp.Description("The project's number.")),
@@ -479,6 +485,7 @@ func AddProjectItem(getClient GetClientFn, t translations.TranslationHelperFunc)
 	return mcp.NewTool("add_project_item",
 			mcp.WithDescription(t("TOOL_ADD_PROJECT_ITEM_DESCRIPTION", "Add a specific Project item for a user or org")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{Title: t("TOOL_ADD_PROJECT_ITEM_USER_TITLE", "Add project item"), ReadOnlyHint: ToBoolPtr(false)}),
+			withRequiredScopes(ScopeProject),
 			mcp.WithString("owner_type", mcp.Required(), mcp.Description("Owner type"), mcp.Enum("user", "org")),
 			mcp.WithString("owner", mcp.Required(), mcp.Description("If owner_type == user it is the handle for the GitHub user account. If owner_type == org it is the name of the organization. The name is not case sensitive.")),
 			mcp.WithNumber("project_number", mcp.Required(), mcp.Description("The project's number.")),
@@ -562,6 +569,7 @@ func DeleteProjectItem(getClient GetClientFn, t translations.TranslationHelperFu
 	return mcp.NewTool("delete_project_item",
 			mcp.WithDescription(t("TOOL_DELETE_PROJECT_ITEM_DESCRIPTION", "Delete a specific Project item for a user or org")),
 			mcp.WithToolAnnotation(mcp.ToolAnnotation{Title: t("TOOL_DELETE_PROJECT_ITEM_USER_TITLE", "Delete project item"), ReadOnlyHint: ToBoolPtr(false)}),
+			withRequiredScopes(ScopeProject),
 			mcp.WithString("owner_type", mcp.Required(), mcp.Description("Owner type"), mcp.Enum("user", "org")),
 			mcp.WithString("owner", mcp.Required(), mcp.Description("If owner_type == user it is the handle for the GitHub user account. If owner_type == org it is the name of the organization. The name is not case sensitive.")),
 			mcp.WithNumber("project_number", mcp.Required(), mcp.Description("The project's number.")),
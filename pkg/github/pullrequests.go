@@ -14,6 +14,8 @@ import (
 	"github.com/shurcooL/githubv4"
 
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/lockdown"
+	"github.com/github/github-mcp-server/pkg/sanitize"
 	"github.com/github/github-mcp-server/pkg/translations"
 )
 
@@ -37,6 +39,7 @@ func GetPullRequest(getClient GetClientFn, t translations.TranslationHelperFunc)
 				mcp.Required(),
 				mcp.Description("Pull request number"),
 			),
+			mcp.WithOutputSchema[MinimalPullRequest](),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -74,12 +77,21 @@ func GetPullRequest(getClient GetClientFn, t translations.TranslationHelperFunc)
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get pull request: %s", string(body))), nil
 			}
 
+			if pr.Title != nil {
+				pr.Title = github.Ptr(lockdown.Withhold(ctx, owner, repo, pr.GetUser().GetLogin(), pr.GetTitle()))
+			}
+			if pr.Body != nil {
+				pr.Body = github.Ptr(lockdown.Withhold(ctx, owner, repo, pr.GetUser().GetLogin(), pr.GetBody()))
+			}
+			sanitize.Value(ctx, pr)
+
+			// The text is the full pull request, while the structured content is minimal to match the output schema
 			r, err := json.Marshal(pr)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			return mcp.NewToolResultStructured(convertToMinimalPullRequest(pr), string(r)), nil
 		}
 }
 
@@ -91,6 +103,7 @@ func CreatePullRequest(getClient GetClientFn, t translations.TranslationHelperFu
 				Title:        t("TOOL_CREATE_PULL_REQUEST_USER_TITLE", "Open new pull request"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -216,6 +229,7 @@ func UpdatePullRequest(getClient GetClientFn, getGQLClient GetGQLClientFn, t tra
 				Title:        t("TOOL_UPDATE_PULL_REQUEST_USER_TITLE", "Edit pull request"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -588,6 +602,16 @@ func ListPullRequests(getClient GetClientFn, t translations.TranslationHelperFun
 				return mcp.NewToolResultError(fmt.Sprintf("failed to list pull requests: %s", string(body))), nil
 			}
 
+			for _, pr := range prs {
+				if pr.Title != nil {
+					pr.Title = github.Ptr(lockdown.Withhold(ctx, owner, repo, pr.GetUser().GetLogin(), pr.GetTitle()))
+				}
+				if pr.Body != nil {
+					pr.Body = github.Ptr(lockdown.Withhold(ctx, owner, repo, pr.GetUser().GetLogin(), pr.GetBody()))
+				}
+			}
+
+			sanitize.Value(ctx, prs)
 			r, err := json.Marshal(prs)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -605,6 +629,7 @@ func MergePullRequest(getClient GetClientFn, t translations.TranslationHelperFun
 				Title:        t("TOOL_MERGE_PULL_REQUEST_USER_TITLE", "Merge pull request"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -903,6 +928,7 @@ func UpdatePullRequestBranch(getClient GetClientFn, t translations.TranslationHe
 				Title:        t("TOOL_UPDATE_PULL_REQUEST_BRANCH_USER_TITLE", "Update pull request branch"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -1040,6 +1066,13 @@ func GetPullRequestReviewComments(getClient GetClientFn, t translations.Translat
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get pull request review comments: %s", string(body))), nil
 			}
 
+			for _, comment := range comments {
+				if comment.Body != nil {
+					comment.Body = github.Ptr(lockdown.Withhold(ctx, owner, repo, comment.GetUser().GetLogin(), comment.GetBody()))
+				}
+			}
+
+			sanitize.Value(ctx, comments)
 			r, err := json.Marshal(comments)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -1106,6 +1139,13 @@ func GetPullRequestReviews(getClient GetClientFn, t translations.TranslationHelp
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get pull request reviews: %s", string(body))), nil
 			}
 
+			for _, review := range reviews {
+				if review.Body != nil {
+					review.Body = github.Ptr(lockdown.Withhold(ctx, owner, repo, review.GetUser().GetLogin(), review.GetBody()))
+				}
+			}
+
+			sanitize.Value(ctx, reviews)
 			r, err := json.Marshal(reviews)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -1122,6 +1162,7 @@ func CreateAndSubmitPullRequestReview(getGQLClient GetGQLClientFn, t translation
 				Title:        t("TOOL_CREATE_AND_SUBMIT_PULL_REQUEST_REVIEW_USER_TITLE", "Create and submit a pull request review without comments"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			// Either we need the PR GQL Id directly, or we need owner, repo and PR number to look it up.
 			// Since our other Pull Request tools are working with the REST Client, will handle the lookup
 			// internally for now.
@@ -1225,6 +1266,7 @@ func CreatePendingPullRequestReview(getGQLClient GetGQLClientFn, t translations.
 				Title:        t("TOOL_CREATE_PENDING_PULL_REQUEST_REVIEW_USER_TITLE", "Create pending pull request review"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			// Either we need the PR GQL Id directly, or we need owner, repo and PR number to look it up.
 			// Since our other Pull Request tools are working with the REST Client, will handle the lookup
 			// internally for now.
@@ -1317,6 +1359,7 @@ func AddCommentToPendingReview(getGQLClient GetGQLClientFn, t translations.Trans
 				Title:        t("TOOL_ADD_COMMENT_TO_PENDING_REVIEW_USER_TITLE", "Add review comment to the requester's latest pending pull request review"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			// Ideally, for performance sake this would just accept the pullRequestReviewID. However, we would need to
 			// add a new tool to get that ID for clients that aren't in the same context as the original pending review
 			// creation. So for now, we'll just accept the owner, repo and pull number and assume this is adding a comment
@@ -1483,6 +1526,7 @@ func SubmitPendingPullRequestReview(getGQLClient GetGQLClientFn, t translations.
 				Title:        t("TOOL_SUBMIT_PENDING_PULL_REQUEST_REVIEW_USER_TITLE", "Submit the requester's latest pending pull request review"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			// Ideally, for performance sake this would just accept the pullRequestReviewID. However, we would need to
 			// add a new tool to get that ID for clients that aren't in the same context as the original pending review
 			// creation. So for now, we'll just accept the owner, repo and pull number and assume this is submitting
@@ -1617,6 +1661,7 @@ func DeletePendingPullRequestReview(getGQLClient GetGQLClientFn, t translations.
 				Title:        t("TOOL_DELETE_PENDING_PULL_REQUEST_REVIEW_USER_TITLE", "Delete the requester's latest pending pull request review"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			// Ideally, for performance sake this would just accept the pullRequestReviewID. However, we would need to
 			// add a new tool to get that ID for clients that aren't in the same context as the original pending review
 			// creation. So for now, we'll just accept the owner, repo and pull number and assume this is deleting
@@ -1804,6 +1849,7 @@ func RequestCopilotReview(getClient GetClientFn, t translations.TranslationHelpe
 				Title:        t("TOOL_REQUEST_COPILOT_REVIEW_USER_TITLE", "Request Copilot review"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
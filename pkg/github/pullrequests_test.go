@@ -37,6 +37,7 @@ func Test_GetPullRequest(t *testing.T) {
 		Title:   github.Ptr("Test PR"),
 		State:   github.Ptr("open"),
 		HTMLURL: github.Ptr("https://github.com/owner/repo/pull/42"),
+		DiffURL: github.Ptr("https://github.com/owner/repo/pull/42.diff"),
 		Head: &github.PullRequestBranch{
 			SHA: github.Ptr("abcd1234"),
 			Ref: github.Ptr("feature-branch"),
@@ -130,6 +131,14 @@ func Test_GetPullRequest(t *testing.T) {
 			assert.Equal(t, *tc.expectedPR.Title, *returnedPR.Title)
 			assert.Equal(t, *tc.expectedPR.State, *returnedPR.State)
 			assert.Equal(t, *tc.expectedPR.HTMLURL, *returnedPR.HTMLURL)
+			assert.Equal(t, tc.expectedPR.GetHead().GetRef(), returnedPR.GetHead().GetRef())
+			assert.Equal(t, tc.expectedPR.GetUser().GetLogin(), returnedPR.GetUser().GetLogin())
+			assert.Equal(t, tc.expectedPR.GetDiffURL(), returnedPR.GetDiffURL())
+
+			// The structured content is the minimal pull request, matching the output schema
+			expectedStructured, err := json.Marshal(convertToMinimalPullRequest(tc.expectedPR))
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expectedStructured), getStructuredResult(t, result))
 		})
 	}
 }
@@ -8,10 +8,12 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
 	"github.com/github/github-mcp-server/pkg/raw"
+	"github.com/github/github-mcp-server/pkg/sanitize"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/google/go-github/v74/github"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -92,6 +94,7 @@ func GetCommit(getClient GetClientFn, t translations.TranslationHelperFunc) (too
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get commit: %s", string(body))), nil
 			}
 
+			sanitize.Value(ctx, commit)
 			// Convert to minimal commit
 			minimalCommit := convertToMinimalCommit(commit, includeDiff)
 
@@ -127,6 +130,8 @@ func ListCommits(getClient GetClientFn, t translations.TranslationHelperFunc) (t
 				mcp.Description("Author username or email address to filter commits by"),
 			),
 			WithPagination(),
+			WithMaxItems(),
+			mcp.WithOutputSchema[MinimalCommitsResult](),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -149,6 +154,10 @@ func ListCommits(getClient GetClientFn, t translations.TranslationHelperFunc) (t
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
+			maxItems, err := OptionalMaxItemsParam(request, &pagination.PerPage)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 			// Set default perPage to 30 if not provided
 			perPage := pagination.PerPage
 			if perPage == 0 {
@@ -167,6 +176,29 @@ func ListCommits(getClient GetClientFn, t translations.TranslationHelperFunc) (t
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
+
+			if maxItems > 0 {
+				result, resp, err := collectPages(ctx, &opts.ListOptions, maxItems,
+					func(commit MinimalCommit) string { return commit.SHA },
+					func() ([]MinimalCommit, *github.Response, error) {
+						commits, resp, err := client.Repositories.ListCommits(ctx, owner, repo, opts)
+						minimalCommits := make([]MinimalCommit, len(commits))
+						for i, commit := range commits {
+							minimalCommits[i] = convertToMinimalCommit(commit, false)
+						}
+						return minimalCommits, resp, err
+					},
+				)
+				if err != nil {
+					return ghErrors.NewGitHubAPIErrorResponse(ctx, fmt.Sprintf("failed to list commits: %s", sha), resp, err), nil
+				}
+				sanitize.Value(ctx, &result)
+				return MarshalledStructuredResult(MinimalCommitsResult{
+					Commits:  result.Items,
+					HasMore:  result.HasMore,
+					NextPage: result.NextPage,
+				}), nil
+			}
 			commits, resp, err := client.Repositories.ListCommits(ctx, owner, repo, opts)
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -191,12 +223,14 @@ func ListCommits(getClient GetClientFn, t translations.TranslationHelperFunc) (t
 				minimalCommits[i] = convertToMinimalCommit(commit, false)
 			}
 
+			sanitize.Value(ctx, minimalCommits)
 			r, err := json.Marshal(minimalCommits)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			// Tool outputs must be objects, so the structured content wraps the list the text holds
+			return mcp.NewToolResultStructured(MinimalCommitsResult{Commits: minimalCommits}, string(r)), nil
 		}
 }
 
@@ -217,6 +251,7 @@ func ListBranches(getClient GetClientFn, t translations.TranslationHelperFunc) (
 				mcp.Description("Repository name"),
 			),
 			WithPagination(),
+			WithMaxItems(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -231,6 +266,10 @@ func ListBranches(getClient GetClientFn, t translations.TranslationHelperFunc) (
 			if err != nil {
 				return mcp.NewToolResultError(err.Error()), nil
 			}
+			maxItems, err := OptionalMaxItemsParam(request, &pagination.PerPage)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
 
 			opts := &github.BranchListOptions{
 				ListOptions: github.ListOptions{
@@ -244,6 +283,24 @@ func ListBranches(getClient GetClientFn, t translations.TranslationHelperFunc) (
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
 
+			if maxItems > 0 {
+				result, resp, err := collectPages(ctx, &opts.ListOptions, maxItems,
+					func(branch MinimalBranch) string { return branch.Name },
+					func() ([]MinimalBranch, *github.Response, error) {
+						branches, resp, err := client.Repositories.ListBranches(ctx, owner, repo, opts)
+						minimalBranches := make([]MinimalBranch, 0, len(branches))
+						for _, branch := range branches {
+							minimalBranches = append(minimalBranches, convertToMinimalBranch(branch))
+						}
+						return minimalBranches, resp, err
+					},
+				)
+				if err != nil {
+					return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to list branches", resp, err), nil
+				}
+				return MarshalledTextResult(result), nil
+			}
+
 			branches, resp, err := client.Repositories.ListBranches(ctx, owner, repo, opts)
 			if err != nil {
 				return ghErrors.NewGitHubAPIErrorResponse(ctx,
@@ -285,6 +342,7 @@ func CreateOrUpdateFile(getClient GetClientFn, t translations.TranslationHelperF
 				Title:        t("TOOL_CREATE_OR_UPDATE_FILE_USER_TITLE", "Create or update file"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner (username or organization)"),
@@ -398,6 +456,7 @@ func CreateRepository(getClient GetClientFn, t translations.TranslationHelperFun
 				Title:        t("TOOL_CREATE_REPOSITORY_USER_TITLE", "Create repository"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("name",
 				mcp.Required(),
 				mcp.Description("Repository name"),
@@ -685,6 +744,7 @@ func ForkRepository(getClient GetClientFn, t translations.TranslationHelperFunc)
 				Title:        t("TOOL_FORK_REPOSITORY_USER_TITLE", "Fork repository"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -772,6 +832,7 @@ func DeleteFile(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 				ReadOnlyHint:    ToBoolPtr(false),
 				DestructiveHint: ToBoolPtr(true),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner (username or organization)"),
@@ -942,6 +1003,7 @@ func CreateBranch(getClient GetClientFn, t translations.TranslationHelperFunc) (
 				Title:        t("TOOL_CREATE_BRANCH_USER_TITLE", "Create branch"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -1043,6 +1105,7 @@ func PushFiles(getClient GetClientFn, t translations.TranslationHelperFunc) (too
 				Title:        t("TOOL_PUSH_FILES_USER_TITLE", "Push files to repository"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -1354,6 +1417,7 @@ func GetTag(getClient GetClientFn, t translations.TranslationHelperFunc) (tool m
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get tag object: %s", string(body))), nil
 			}
 
+			sanitize.Value(ctx, tagObj)
 			r, err := json.Marshal(tagObj)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -1380,6 +1444,7 @@ func ListReleases(getClient GetClientFn, t translations.TranslationHelperFunc) (
 				mcp.Description("Repository name"),
 			),
 			WithPagination(),
+			WithMaxItems(),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			owner, err := RequiredParam[string](request, "owner")
@@ -1395,6 +1460,11 @@ func ListReleases(getClient GetClientFn, t translations.TranslationHelperFunc) (
 				return mcp.NewToolResultError(err.Error()), nil
 			}
 
+			maxItems, err := OptionalMaxItemsParam(request, &pagination.PerPage)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			opts := &github.ListOptions{
 				Page:    pagination.Page,
 				PerPage: pagination.PerPage,
@@ -1405,6 +1475,20 @@ func ListReleases(getClient GetClientFn, t translations.TranslationHelperFunc) (
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
 			}
 
+			if maxItems > 0 {
+				result, _, err := collectPages(ctx, opts, maxItems,
+					func(release *github.RepositoryRelease) string { return strconv.FormatInt(release.GetID(), 10) },
+					func() ([]*github.RepositoryRelease, *github.Response, error) {
+						return client.Repositories.ListReleases(ctx, owner, repo, opts)
+					},
+				)
+				if err != nil {
+					return nil, fmt.Errorf("failed to list releases: %w", err)
+				}
+				sanitize.Value(ctx, &result)
+				return MarshalledTextResult(result), nil
+			}
+
 			releases, resp, err := client.Repositories.ListReleases(ctx, owner, repo, opts)
 			if err != nil {
 				return nil, fmt.Errorf("failed to list releases: %w", err)
@@ -1419,6 +1503,7 @@ func ListReleases(getClient GetClientFn, t translations.TranslationHelperFunc) (
 				return mcp.NewToolResultError(fmt.Sprintf("failed to list releases: %s", string(body))), nil
 			}
 
+			sanitize.Value(ctx, releases)
 			r, err := json.Marshal(releases)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -1474,6 +1559,7 @@ func GetLatestRelease(getClient GetClientFn, t translations.TranslationHelperFun
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get latest release: %s", string(body))), nil
 			}
 
+			sanitize.Value(ctx, release)
 			r, err := json.Marshal(release)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -1540,6 +1626,7 @@ func GetReleaseByTag(getClient GetClientFn, t translations.TranslationHelperFunc
 				return mcp.NewToolResultError(fmt.Sprintf("failed to get release by tag: %s", string(body))), nil
 			}
 
+			sanitize.Value(ctx, release)
 			r, err := json.Marshal(release)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -1812,6 +1899,7 @@ func StarRepository(getClient GetClientFn, t translations.TranslationHelperFunc)
 				Title:        t("TOOL_STAR_REPOSITORY_USER_TITLE", "Star repository"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -1866,6 +1954,7 @@ func UnstarRepository(getClient GetClientFn, t translations.TranslationHelperFun
 				Title:        t("TOOL_UNSTAR_REPOSITORY_USER_TITLE", "Unstar repository"),
 				ReadOnlyHint: ToBoolPtr(false),
 			}),
+			withRequiredScopes(ScopeRepo, ScopePublicRepo),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("Repository owner"),
@@ -919,6 +919,7 @@ func Test_ListCommits(t *testing.T) {
 			var returnedCommits []MinimalCommit
 			err = json.Unmarshal([]byte(textContent.Text), &returnedCommits)
 			require.NoError(t, err)
+			assert.JSONEq(t, `{"commits":`+textContent.Text+`}`, getStructuredResult(t, result))
 			assert.Len(t, returnedCommits, len(tc.expectedCommits))
 			for i, commit := range returnedCommits {
 				assert.Equal(t, tc.expectedCommits[i].GetSHA(), commit.SHA)
@@ -0,0 +1,218 @@
+package github
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/github/github-mcp-server/pkg/toolsets"
+	"github.com/google/go-github/v74/github"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// OAuth scopes of personal access tokens (classic) and OAuth App tokens.
+// See: https://docs.github.com/en/apps/oauth-apps/building-oauth-apps/scopes-for-oauth-apps
+const (
+	ScopeRepo                     = "repo"
+	ScopePublicRepo               = "public_repo"
+	ScopeSecurityEvents           = "security_events"
+	ScopeRepositoryAdvisoriesRead = "repository_advisories:read"
+	ScopeReadOrg                  = "read:org"
+	ScopeNotifications            = "notifications"
+	ScopeGist                     = "gist"
+	ScopeProject                  = "project"
+	ScopeReadProject              = "read:project"
+	ScopeCodespace                = "codespace"
+)
+
+// impliedScopes lists the scopes granted along with a parent scope.
+var impliedScopes = map[string][]string{
+	ScopeRepo:         {ScopePublicRepo, ScopeSecurityEvents, ScopeRepositoryAdvisoriesRead, "repo:status", "repo_deployment", "repo:invite"},
+	"admin:org":       {"write:org", ScopeReadOrg},
+	"write:org":       {ScopeReadOrg},
+	ScopeProject:      {ScopeReadProject},
+	"user":            {"read:user", "user:email", "user:follow"},
+	ScopeCodespace:    {"codespace:secrets"},
+	"admin:repo_hook": {"write:repo_hook", "read:repo_hook"},
+}
+
+// requiredScopesKey is the key of the tool metadata holding the scopes declared with withRequiredScopes.
+const requiredScopesKey = "requiredScopes"
+
+// withRequiredScopes declares the scopes a classic token needs to use a tool, any one of which is enough. Tools
+// declaring none work with any token, e.g. on public repositories.
+func withRequiredScopes(scopes ...string) mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		if tool.Meta == nil {
+			tool.Meta = &mcp.Meta{}
+		}
+		if tool.Meta.AdditionalFields == nil {
+			tool.Meta.AdditionalFields = make(map[string]any)
+		}
+		tool.Meta.AdditionalFields[requiredScopesKey] = scopes
+	}
+}
+
+// RequiredScopes returns the scopes a classic token needs to use the tool, any one of which is enough.
+func RequiredScopes(tool mcp.Tool) []string {
+	if tool.Meta == nil {
+		return nil
+	}
+	scopes, _ := tool.Meta.AdditionalFields[requiredScopesKey].([]string)
+	return scopes
+}
+
+// ToolsetScopes returns the scopes to request for a classic token to use every active tool of the enabled toolsets
+// of tsg, leaving out the scopes implied by others.
+func ToolsetScopes(tsg *toolsets.ToolsetGroup) []string {
+	requested := make(map[string]bool)
+	for _, toolset := range tsg.Toolsets {
+		for _, tool := range toolset.GetActiveTools() {
+			// The first of the alternative scopes is the one covering the most
+			if required := RequiredScopes(tool.Tool); len(required) > 0 {
+				requested[required[0]] = true
+			}
+		}
+	}
+
+	scopes := make([]string, 0, len(requested))
+	for scope := range requested {
+		implied := false
+		for other := range requested {
+			if other != scope && ParseTokenScopes(other).Granted[scope] {
+				implied = true
+			}
+		}
+		if !implied {
+			scopes = append(scopes, scope)
+		}
+	}
+	sort.Strings(scopes)
+	return scopes
+}
+
+// scopeProbes are the requests that tell whether a token without OAuth scopes, such as a fine-grained personal
+// access token or a GitHub App installation token, can use the APIs behind a scope. Scopes without a probe are
+// assumed to be usable, as their permissions are granted per repository or organization.
+var scopeProbes = map[string]string{
+	ScopeNotifications: "notifications?per_page=1",
+	ScopeCodespace:     "user/codespaces?per_page=1",
+}
+
+// TokenScopes describes what a token is allowed to do, as far as can be told up front.
+type TokenScopes struct {
+	// Classic is true for tokens reporting their OAuth scopes, i.e. personal access tokens (classic) and OAuth App tokens
+	Classic bool
+
+	// Granted holds the scopes of a classic token, including those implied by its other scopes
+	Granted map[string]bool
+
+	// Denied holds the scopes whose probe a token without OAuth scopes failed
+	Denied map[string]bool
+}
+
+// ParseTokenScopes parses the X-OAuth-Scopes header returned to classic tokens.
+func ParseTokenScopes(header string) *TokenScopes {
+	scopes := &TokenScopes{Classic: true, Granted: make(map[string]bool)}
+
+	var grant func(scope string)
+	grant = func(scope string) {
+		if scopes.Granted[scope] {
+			return
+		}
+		scopes.Granted[scope] = true
+		for _, implied := range impliedScopes[scope] {
+			grant(implied)
+		}
+	}
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			grant(scope)
+		}
+	}
+	return scopes
+}
+
+// FetchTokenScopes determines the scopes of the token client authenticates with. Classic tokens report their
+// scopes in the X-OAuth-Scopes header of every response, other tokens are probed for the APIs they can access.
+func FetchTokenScopes(ctx context.Context, client *github.Client) (*TokenScopes, error) {
+	req, err := client.NewRequest(http.MethodGet, "", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(ctx, req, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch token scopes: %w", err)
+	}
+	_ = resp.Body.Close()
+
+	if header, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		return ParseTokenScopes(strings.Join(header, ",")), nil
+	}
+
+	scopes := &TokenScopes{Denied: make(map[string]bool)}
+	for scope, path := range scopeProbes {
+		req, err := client.NewRequest(http.MethodGet, path, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(ctx, req, nil)
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		if err == nil {
+			continue
+		}
+		if resp == nil || (resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusNotFound) {
+			return nil, fmt.Errorf("failed to probe token access for scope %s: %w", scope, err)
+		}
+		scopes.Denied[scope] = true
+	}
+	return scopes, nil
+}
+
+// Allows reports whether the token can use a tool requiring any one of the given scopes. Tokens without
+// OAuth scopes are only held back by the scopes they failed the probe of.
+func (s *TokenScopes) Allows(required []string) bool {
+	if len(required) == 0 {
+		return true
+	}
+	for _, scope := range required {
+		if s.Classic && s.Granted[scope] {
+			return true
+		}
+		if !s.Classic && s.Denied[scope] {
+			return false
+		}
+	}
+	return !s.Classic
+}
+
+// ScopeToolFilter hides the tools of tsg that a token with the given scopes cannot use.
+func ScopeToolFilter(scopes *TokenScopes, tsg *toolsets.ToolsetGroup) toolsets.ToolFilter {
+	requiredScopes := make(map[string][]string)
+	for _, toolset := range tsg.Toolsets {
+		for _, tool := range toolset.GetAvailableTools() {
+			requiredScopes[tool.Tool.Name] = RequiredScopes(tool.Tool)
+		}
+	}
+
+	return func(tool string) (string, bool) {
+		required := requiredScopes[tool]
+		if scopes.Allows(required) {
+			return "", true
+		}
+		if scopes.Classic {
+			return fmt.Sprintf("token lacks the %s scope", strings.Join(required, " or ")), false
+		}
+		var denied []string
+		for _, scope := range required {
+			if scopes.Denied[scope] {
+				denied = append(denied, scope)
+			}
+		}
+		return fmt.Sprintf("token cannot access the APIs covered by the %s scope", strings.Join(denied, ", ")), false
+	}
+}
@@ -0,0 +1,191 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/github/github-mcp-server/pkg/translations"
+	"github.com/google/go-github/v74/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_WriteToolsDeclareRequiredScopes(t *testing.T) {
+	tsg := DefaultToolsetGroup(false, stubGetClientFn(nil), stubGetGQLClientFn(nil), stubGetRawClientFn(nil), stubGetDownloadClientFn(nil), translations.NullTranslationHelper, 5000)
+
+	// Every write needs a scope of classic tokens, so a write tool without any was most likely forgotten
+	for _, toolset := range tsg.Toolsets {
+		for _, tool := range toolset.GetAvailableTools() {
+			if tsg.IsWriteTool(tool.Tool.Name) {
+				assert.NotEmpty(t, RequiredScopes(tool.Tool), "write tool %s declares no required scopes", tool.Tool.Name)
+			}
+		}
+	}
+
+	tool, _ := ListNotifications(stubGetClientFn(nil), translations.NullTranslationHelper)
+	assert.Equal(t, []string{ScopeNotifications, ScopeRepo}, RequiredScopes(tool))
+	tool, _ = GetMe(stubGetClientFn(nil), translations.NullTranslationHelper)
+	assert.Empty(t, RequiredScopes(tool))
+}
+
+func Test_ParseTokenScopes(t *testing.T) {
+	scopes := ParseTokenScopes("repo, admin:org, gist")
+
+	assert.True(t, scopes.Classic)
+	for _, scope := range []string{ScopeRepo, ScopePublicRepo, ScopeSecurityEvents, "admin:org", "write:org", ScopeReadOrg, ScopeGist} {
+		assert.True(t, scopes.Granted[scope], "expected scope %s to be granted", scope)
+	}
+	assert.False(t, scopes.Granted[ScopeNotifications])
+}
+
+func Test_FetchTokenScopes(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        http.HandlerFunc
+		expectedScopes *TokenScopes
+	}{
+		{
+			name: "classic token reports its scopes",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("X-OAuth-Scopes", "public_repo, read:project")
+				w.WriteHeader(http.StatusOK)
+			},
+			expectedScopes: &TokenScopes{
+				Classic: true,
+				Granted: map[string]bool{ScopePublicRepo: true, ScopeReadProject: true},
+			},
+		},
+		{
+			name: "classic token without scopes",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("X-OAuth-Scopes", "")
+				w.WriteHeader(http.StatusOK)
+			},
+			expectedScopes: &TokenScopes{
+				Classic: true,
+				Granted: map[string]bool{},
+			},
+		},
+		{
+			name: "fine-grained token is probed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/notifications" {
+					w.WriteHeader(http.StatusForbidden)
+					_, _ = w.Write([]byte(`{"message": "Resource not accessible by personal access token"}`))
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			},
+			expectedScopes: &TokenScopes{
+				Denied: map[string]bool{ScopeNotifications: true},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ts := httptest.NewServer(tc.handler)
+			defer ts.Close()
+
+			client := github.NewClient(nil)
+			client.BaseURL, _ = url.Parse(ts.URL + "/")
+
+			scopes, err := FetchTokenScopes(context.Background(), client)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedScopes, scopes)
+		})
+	}
+}
+
+func Test_ScopeToolFilter(t *testing.T) {
+	tsg := DefaultToolsetGroup(false, stubGetClientFn(nil), stubGetGQLClientFn(nil), stubGetRawClientFn(nil), stubGetDownloadClientFn(nil), translations.NullTranslationHelper, 5000)
+
+	tests := []struct {
+		name           string
+		scopes         *TokenScopes
+		tool           string
+		expectedOK     bool
+		expectedReason string
+	}{
+		{
+			name:       "tool without required scopes",
+			scopes:     ParseTokenScopes(""),
+			tool:       "get_me",
+			expectedOK: true,
+		},
+		{
+			name:       "classic token with an alternative scope",
+			scopes:     ParseTokenScopes("public_repo"),
+			tool:       "create_issue",
+			expectedOK: true,
+		},
+		{
+			name:           "classic token lacking the scope",
+			scopes:         ParseTokenScopes("public_repo"),
+			tool:           "list_notifications",
+			expectedReason: "token lacks the notifications or repo scope",
+		},
+		{
+			name:       "fine-grained token passing its probes",
+			scopes:     &TokenScopes{Denied: map[string]bool{}},
+			tool:       "list_codespaces",
+			expectedOK: true,
+		},
+		{
+			name:           "fine-grained token failing a probe",
+			scopes:         &TokenScopes{Denied: map[string]bool{ScopeNotifications: true}},
+			tool:           "list_notifications",
+			expectedReason: "token cannot access the APIs covered by the notifications scope",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reason, ok := ScopeToolFilter(tc.scopes, tsg)(tc.tool)
+			assert.Equal(t, tc.expectedOK, ok)
+			assert.Equal(t, tc.expectedReason, reason)
+		})
+	}
+}
+
+func Test_ToolsetScopes(t *testing.T) {
+	tests := []struct {
+		name           string
+		readOnly       bool
+		toolsets       []string
+		expectedScopes []string
+	}{
+		{
+			name:           "default toolsets",
+			toolsets:       GetDefaultToolsetIDs(),
+			expectedScopes: []string{ScopeCodespace, ScopeReadOrg, ScopeRepo},
+		},
+		{
+			name:           "scopes implied by others are left out",
+			toolsets:       []string{"repos", "code_security"},
+			expectedScopes: []string{ScopeRepo},
+		},
+		{
+			name:           "read-only toolsets",
+			readOnly:       true,
+			toolsets:       []string{"projects", "gists", "notifications"},
+			expectedScopes: []string{ScopeNotifications, ScopeReadProject},
+		},
+		{
+			name:           "every toolset",
+			toolsets:       []string{"all"},
+			expectedScopes: []string{ScopeCodespace, ScopeGist, ScopeNotifications, ScopeProject, ScopeReadOrg, ScopeRepo},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tsg := DefaultToolsetGroup(tc.readOnly, stubGetClientFn(nil), stubGetGQLClientFn(nil), stubGetRawClientFn(nil), stubGetDownloadClientFn(nil), translations.NullTranslationHelper, 5000)
+			require.NoError(t, tsg.EnableToolsets(tc.toolsets))
+			assert.Equal(t, tc.expectedScopes, ToolsetScopes(tsg))
+		})
+	}
+}
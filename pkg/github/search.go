@@ -7,6 +7,8 @@ import (
 	"io"
 
 	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/github/github-mcp-server/pkg/policy"
+	"github.com/github/github-mcp-server/pkg/sanitize"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/google/go-github/v74/github"
 	"github.com/mark3labs/mcp-go/mcp"
@@ -31,6 +33,7 @@ func SearchRepositories(getClient GetClientFn, t translations.TranslationHelperF
 				mcp.DefaultBool(true),
 			),
 			WithPagination(),
+			mcp.WithOutputSchema[MinimalSearchRepositoriesResult](),
 		),
 		func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 			query, err := RequiredParam[string](request, "query")
@@ -52,6 +55,12 @@ func SearchRepositories(getClient GetClientFn, t translations.TranslationHelperF
 				},
 			}
 
+			// Keep the search to the repositories allowed by the access policy, if any
+			query, err = policy.FromContext(ctx).ScopeSearchQuery(query)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
@@ -74,58 +83,61 @@ func SearchRepositories(getClient GetClientFn, t translations.TranslationHelperF
 				return mcp.NewToolResultError(fmt.Sprintf("failed to search repositories: %s", string(body))), nil
 			}
 
-			// Return either minimal or full response based on parameter
-			var r []byte
-			if minimalOutput {
-				minimalRepos := make([]MinimalRepository, 0, len(result.Repositories))
-				for _, repo := range result.Repositories {
-					minimalRepo := MinimalRepository{
-						ID:            repo.GetID(),
-						Name:          repo.GetName(),
-						FullName:      repo.GetFullName(),
-						Description:   repo.GetDescription(),
-						HTMLURL:       repo.GetHTMLURL(),
-						Language:      repo.GetLanguage(),
-						Stars:         repo.GetStargazersCount(),
-						Forks:         repo.GetForksCount(),
-						OpenIssues:    repo.GetOpenIssuesCount(),
-						Private:       repo.GetPrivate(),
-						Fork:          repo.GetFork(),
-						Archived:      repo.GetArchived(),
-						DefaultBranch: repo.GetDefaultBranch(),
-					}
-
-					if repo.UpdatedAt != nil {
-						minimalRepo.UpdatedAt = repo.UpdatedAt.Format("2006-01-02T15:04:05Z")
-					}
-					if repo.CreatedAt != nil {
-						minimalRepo.CreatedAt = repo.CreatedAt.Format("2006-01-02T15:04:05Z")
-					}
-					if repo.Topics != nil {
-						minimalRepo.Topics = repo.Topics
-					}
-
-					minimalRepos = append(minimalRepos, minimalRepo)
-				}
+			result.Repositories = filterSearchItems(ctx, result.Repositories, &result.Total, &result.IncompleteResults,
+				func(p *policy.Policy, repo *github.Repository) bool { return p.AllowsFullName(repo.GetFullName()) })
 
-				minimalResult := &MinimalSearchRepositoriesResult{
-					TotalCount:        result.GetTotal(),
-					IncompleteResults: result.GetIncompleteResults(),
-					Items:             minimalRepos,
+			sanitize.Value(ctx, result)
+			minimalRepos := make([]MinimalRepository, 0, len(result.Repositories))
+			for _, repo := range result.Repositories {
+				minimalRepo := MinimalRepository{
+					ID:            repo.GetID(),
+					Name:          repo.GetName(),
+					FullName:      repo.GetFullName(),
+					Description:   repo.GetDescription(),
+					HTMLURL:       repo.GetHTMLURL(),
+					Language:      repo.GetLanguage(),
+					Stars:         repo.GetStargazersCount(),
+					Forks:         repo.GetForksCount(),
+					OpenIssues:    repo.GetOpenIssuesCount(),
+					Private:       repo.GetPrivate(),
+					Fork:          repo.GetFork(),
+					Archived:      repo.GetArchived(),
+					DefaultBranch: repo.GetDefaultBranch(),
 				}
 
-				r, err = json.Marshal(minimalResult)
-				if err != nil {
-					return nil, fmt.Errorf("failed to marshal minimal response: %w", err)
+				if repo.UpdatedAt != nil {
+					minimalRepo.UpdatedAt = repo.UpdatedAt.Format("2006-01-02T15:04:05Z")
 				}
-			} else {
-				r, err = json.Marshal(result)
-				if err != nil {
-					return nil, fmt.Errorf("failed to marshal full response: %w", err)
+				if repo.CreatedAt != nil {
+					minimalRepo.CreatedAt = repo.CreatedAt.Format("2006-01-02T15:04:05Z")
+				}
+				if repo.Topics != nil {
+					minimalRepo.Topics = repo.Topics
 				}
+
+				minimalRepos = append(minimalRepos, minimalRepo)
 			}
 
-			return mcp.NewToolResultText(string(r)), nil
+			minimalResult := &MinimalSearchRepositoriesResult{
+				TotalCount:        result.Total,
+				IncompleteResults: result.GetIncompleteResults(),
+				Items:             minimalRepos,
+			}
+
+			if minimalOutput {
+				return MarshalledStructuredResult(minimalResult), nil
+			}
+
+			// The full response has every field of the minimal one, and so also matches the output schema once
+			// its items are kept when there are none
+			fullResult := struct {
+				*github.RepositoriesSearchResult
+				Items []*github.Repository `json:"items"`
+			}{result, result.Repositories}
+			if fullResult.Items == nil {
+				fullResult.Items = []*github.Repository{}
+			}
+			return MarshalledStructuredResult(fullResult), nil
 		}
 }
 
@@ -177,6 +189,12 @@ func SearchCode(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 				},
 			}
 
+			// Keep the search to the repositories allowed by the access policy, if any
+			query, err = policy.FromContext(ctx).ScopeSearchQuery(query)
+			if err != nil {
+				return mcp.NewToolResultError(err.Error()), nil
+			}
+
 			client, err := getClient(ctx)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get GitHub client: %w", err)
@@ -200,6 +218,11 @@ func SearchCode(getClient GetClientFn, t translations.TranslationHelperFunc) (to
 				return mcp.NewToolResultError(fmt.Sprintf("failed to search code: %s", string(body))), nil
 			}
 
+			result.CodeResults = filterSearchItems(ctx, result.CodeResults, &result.Total, &result.IncompleteResults,
+				func(p *policy.Policy, code *github.CodeResult) bool {
+					return p.AllowsFullName(code.GetRepository().GetFullName())
+				})
+
 			r, err := json.Marshal(result)
 			if err != nil {
 				return nil, fmt.Errorf("failed to marshal response: %w", err)
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/github/github-mcp-server/internal/toolsnaps"
+	"github.com/github/github-mcp-server/pkg/policy"
 	"github.com/github/github-mcp-server/pkg/translations"
 	"github.com/google/go-github/v74/github"
 	"github.com/migueleliasweb/go-github-mock/src/mock"
@@ -151,7 +152,8 @@ func Test_SearchRepositories(t *testing.T) {
 			var returnedResult MinimalSearchRepositoriesResult
 			err = json.Unmarshal([]byte(textContent.Text), &returnedResult)
 			require.NoError(t, err)
-			assert.Equal(t, *tc.expectedResult.Total, returnedResult.TotalCount)
+			assert.JSONEq(t, textContent.Text, getStructuredResult(t, result))
+			assert.Equal(t, tc.expectedResult.Total, returnedResult.TotalCount)
 			assert.Equal(t, *tc.expectedResult.IncompleteResults, returnedResult.IncompleteResults)
 			assert.Len(t, returnedResult.Items, len(tc.expectedResult.Repositories))
 			for i, repo := range returnedResult.Items {
@@ -220,6 +222,9 @@ func Test_SearchRepositories_FullOutput(t *testing.T) {
 	assert.Len(t, returnedResult.Repositories, 1)
 	assert.Equal(t, *mockSearchResult.Repositories[0].ID, *returnedResult.Repositories[0].ID)
 	assert.Equal(t, *mockSearchResult.Repositories[0].Name, *returnedResult.Repositories[0].Name)
+
+	// The structured content is the same full response
+	assert.JSONEq(t, textContent.Text, getStructuredResult(t, result))
 }
 
 func Test_SearchCode(t *testing.T) {
@@ -735,3 +740,85 @@ func Test_SearchOrgs(t *testing.T) {
 		})
 	}
 }
+
+func Test_SearchRepositoriesWithPolicy(t *testing.T) {
+	repoPolicy, err := policy.New([]string{"myorg/*"}, []string{"myorg/secrets-*"})
+	require.NoError(t, err)
+
+	mockedClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetSearchRepositories,
+			expectQueryParams(t, map[string]string{
+				"q":        "user:myorg golang",
+				"page":     "1",
+				"per_page": "30",
+			}).andThen(
+				mockResponse(t, http.StatusOK, &github.RepositoriesSearchResult{
+					Total: github.Ptr(2),
+					Repositories: []*github.Repository{
+						{Name: github.Ptr("service"), FullName: github.Ptr("myorg/service")},
+						{Name: github.Ptr("secrets-prod"), FullName: github.Ptr("MyOrg/secrets-prod")},
+					},
+				}),
+			),
+		),
+	)
+	_, handler := SearchRepositories(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+	ctx := policy.ContextWithPolicy(context.Background(), repoPolicy)
+
+	// Queries without repository qualifiers are scoped to the allowed owners, and denied repositories filtered out
+	result, err := handler(ctx, createMCPRequest(map[string]interface{}{"query": "golang"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var returned MinimalSearchRepositoriesResult
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &returned))
+	require.Len(t, returned.Items, 1)
+	assert.Equal(t, "myorg/service", returned.Items[0].FullName)
+
+	// The total count is left out, as it would tell how many matches were in denied repositories
+	assert.Nil(t, returned.TotalCount)
+	assert.True(t, returned.IncompleteResults)
+
+	// Searching elsewhere is rejected
+	result, err = handler(ctx, createMCPRequest(map[string]interface{}{"query": "golang org:other"}))
+	require.NoError(t, err)
+	assert.Equal(t, "owner other is not allowed by the repository access policy", getErrorResult(t, result).Text)
+}
+
+func Test_SearchIssuesWithPolicy(t *testing.T) {
+	repoPolicy, err := policy.New([]string{"myorg/*"}, []string{"myorg/secrets-*"})
+	require.NoError(t, err)
+
+	mockedClient := mock.NewMockedHTTPClient(
+		mock.WithRequestMatchHandler(
+			mock.GetSearchIssues,
+			expectQueryParams(t, map[string]string{
+				"q":        "user:myorg is:issue bug",
+				"page":     "1",
+				"per_page": "30",
+			}).andThen(
+				mockResponse(t, http.StatusOK, &github.IssuesSearchResult{
+					Total: github.Ptr(2),
+					Issues: []*github.Issue{
+						{Number: github.Ptr(1), RepositoryURL: github.Ptr("https://api.github.com/repos/myorg/service")},
+						{Number: github.Ptr(2), RepositoryURL: github.Ptr("https://api.github.com/repos/myorg/secrets-prod")},
+					},
+				}),
+			),
+		),
+	)
+	_, handler := SearchIssues(stubGetClientFn(github.NewClient(mockedClient)), translations.NullTranslationHelper)
+	ctx := policy.ContextWithPolicy(context.Background(), repoPolicy)
+
+	result, err := handler(ctx, createMCPRequest(map[string]interface{}{"query": "bug"}))
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	var returned github.IssuesSearchResult
+	require.NoError(t, json.Unmarshal([]byte(getTextResult(t, result).Text), &returned))
+	require.Len(t, returned.Issues, 1)
+	assert.Equal(t, 1, returned.Issues[0].GetNumber())
+	assert.Nil(t, returned.Total)
+	assert.True(t, returned.GetIncompleteResults())
+}
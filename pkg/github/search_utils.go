@@ -7,7 +7,11 @@ import (
 	"io"
 	"net/http"
 	"regexp"
+	"slices"
+	"strings"
 
+	"github.com/github/github-mcp-server/pkg/policy"
+	"github.com/github/github-mcp-server/pkg/sanitize"
 	"github.com/google/go-github/v74/github"
 	"github.com/mark3labs/mcp-go/mcp"
 )
@@ -65,6 +69,12 @@ func searchHandler(
 		query = fmt.Sprintf("repo:%s/%s %s", owner, repo, query)
 	}
 
+	// Keep the search to the repositories allowed by the access policy, if any
+	query, err = policy.FromContext(ctx).ScopeSearchQuery(query)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
 	sort, err := OptionalParam[string](request, "sort")
 	if err != nil {
 		return mcp.NewToolResultError(err.Error()), nil
@@ -106,6 +116,19 @@ func searchHandler(
 		return mcp.NewToolResultError(fmt.Sprintf("%s: %s", errorPrefix, string(body))), nil
 	}
 
+	result.Issues = filterSearchItems(ctx, result.Issues, &result.Total, &result.IncompleteResults,
+		func(p *policy.Policy, issue *github.Issue) bool { return p.AllowsRepoURL(issue.GetRepositoryURL()) })
+
+	for _, issue := range result.Issues {
+		// The repository URL ends with the owner and name of the repository, e.g. .../repos/octocat/hello-world
+		var owner, repo string
+		if parts := strings.Split(strings.TrimSuffix(issue.GetRepositoryURL(), "/"), "/"); len(parts) >= 2 {
+			owner, repo = parts[len(parts)-2], parts[len(parts)-1]
+		}
+		withholdIssue(ctx, owner, repo, issue)
+	}
+
+	sanitize.Value(ctx, result)
 	r, err := json.Marshal(result)
 	if err != nil {
 		return nil, fmt.Errorf("%s: failed to marshal response: %w", errorPrefix, err)
@@ -113,3 +136,20 @@ func searchHandler(
 
 	return mcp.NewToolResultText(string(r)), nil
 }
+
+// filterSearchItems returns the items of a search result without those from the repositories the access policy of
+// the call made with ctx denies, if any. The total count is then left out, as it would include the matches in denied
+// repositories, and the results are marked incomplete when items are removed.
+func filterSearchItems[T any](ctx context.Context, items []T, total **int, incompleteResults **bool, allows func(*policy.Policy, T) bool) []T {
+	p := policy.FromContext(ctx)
+	if p == nil {
+		return items
+	}
+	n := len(items)
+	items = slices.DeleteFunc(items, func(item T) bool { return !allows(p, item) })
+	*total = nil
+	if len(items) < n {
+		*incompleteResults = github.Ptr(true)
+	}
+	return items
+}
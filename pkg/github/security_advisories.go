@@ -189,6 +189,7 @@ func ListRepositorySecurityAdvisories(getClient GetClientFn, t translations.Tran
 				Title:        t("TOOL_LIST_REPOSITORY_SECURITY_ADVISORIES_USER_TITLE", "List repository security advisories"),
 				ReadOnlyHint: ToBoolPtr(true),
 			}),
+			withRequiredScopes(ScopeRepositoryAdvisoriesRead),
 			mcp.WithString("owner",
 				mcp.Required(),
 				mcp.Description("The owner of the repository."),
@@ -323,6 +324,7 @@ func ListOrgRepositorySecurityAdvisories(getClient GetClientFn, t translations.T
 				Title:        t("TOOL_LIST_ORG_REPOSITORY_SECURITY_ADVISORIES_USER_TITLE", "List org repository security advisories"),
 				ReadOnlyHint: ToBoolPtr(true),
 			}),
+			withRequiredScopes(ScopeRepositoryAdvisoriesRead),
 			mcp.WithString("org",
 				mcp.Required(),
 				mcp.Description("The organization login."),
@@ -341,3 +341,14 @@ func MarshalledTextResult(v any) *mcp.CallToolResult {
 
 	return mcp.NewToolResultText(string(data))
 }
+
+// MarshalledStructuredResult returns v as the structured content of a tool result, matching the tool's output
+// schema, and as JSON text for clients that do not read structured content.
+func MarshalledStructuredResult(v any) *mcp.CallToolResult {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("failed to marshal structured result to json", err)
+	}
+
+	return mcp.NewToolResultStructured(v, string(data))
+}
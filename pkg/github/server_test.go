@@ -38,6 +38,12 @@ func stubGetGQLClientFn(client *githubv4.Client) GetGQLClientFn {
 	}
 }
 
+func stubGetDownloadClientFn(client *http.Client) GetDownloadClientFn {
+	return func(_ context.Context) (*http.Client, error) {
+		return client, nil
+	}
+}
+
 func stubGetRawClientFn(client *raw.Client) raw.GetRawClientFn {
 	return func(_ context.Context) (*raw.Client, error) {
 		return client, nil
@@ -0,0 +1,190 @@
+// Package httpcache caches responses of the GitHub REST API, revalidating them with conditional requests.
+// GitHub does not count requests answered with 304 Not Modified against the rate limit.
+// See: https://docs.github.com/en/rest/using-the-rest-api/best-practices-for-using-the-rest-api#use-conditional-requests-if-appropriate
+package httpcache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Entry is a cached response.
+type Entry struct {
+	Header http.Header `json:"header"`
+	Body   []byte      `json:"body"`
+}
+
+// size approximates the memory taken by the entry.
+func (e *Entry) size() int64 {
+	size := int64(len(e.Body))
+	for name, values := range e.Header {
+		size += int64(len(name))
+		for _, value := range values {
+			size += int64(len(value))
+		}
+	}
+	return size
+}
+
+// Store keeps cached responses. Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the entry stored under key, if any.
+	Get(key string) (*Entry, bool)
+
+	// Set stores entry under key, possibly evicting other entries to make room for it.
+	Set(key string, entry *Entry)
+}
+
+// Transport is an http.RoundTripper caching the successful responses to GET requests that carry an ETag or
+// Last-Modified header, and revalidating them on later requests with If-None-Match or If-Modified-Since.
+// Responses are cached per URL, Accept header and Authorization header, so that they are never shared between
+// tokens, and are served from the cache when GitHub replies 304 Not Modified.
+type Transport struct {
+	// Base is the underlying RoundTripper, http.DefaultTransport if nil.
+	Base http.RoundTripper
+
+	// Store keeps the cached responses
+	Store Store
+
+	// MaxEntrySize is the size above which responses are not cached, unlimited if zero
+	MaxEntrySize int64
+}
+
+// RoundTrip sends req using the base transport, answering it from the cache if GitHub reports it unchanged.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if !cacheable(req) {
+		return base.RoundTrip(req)
+	}
+
+	key := cacheKey(req)
+	entry, cached := t.Store.Get(key)
+	if cached {
+		req = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+			req.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if cached && resp.StatusCode == http.StatusNotModified {
+		_ = resp.Body.Close()
+		return entry.response(req, resp), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || !storable(resp) {
+		return resp, nil
+	}
+
+	limit := t.MaxEntrySize
+	if limit == 0 {
+		limit = -1
+	}
+	body, tooLarge, err := readBody(resp.Body, limit)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	if tooLarge {
+		// Hand the caller what was read so far followed by the rest of the body
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	_ = resp.Body.Close()
+
+	t.Store.Set(key, &Entry{Header: resp.Header.Clone(), Body: body})
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// response builds the response to req from the entry, updated with the headers of the 304 Not Modified
+// response that revalidated it, e.g. the current rate limit.
+func (e *Entry) response(req *http.Request, notModified *http.Response) *http.Response {
+	header := e.Header.Clone()
+	for name, values := range notModified.Header {
+		if name == "Content-Length" || name == "Content-Type" {
+			continue
+		}
+		header[name] = values
+	}
+	// go-github does not update its rate limit records for cached responses
+	header.Set("X-From-Cache", "1")
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         notModified.Proto,
+		ProtoMajor:    notModified.ProtoMajor,
+		ProtoMinor:    notModified.ProtoMinor,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheable reports whether the response to req may be served from the cache.
+func cacheable(req *http.Request) bool {
+	if req.Method != http.MethodGet {
+		return false
+	}
+	// Leave requests the caller made conditional or partial alone
+	for _, name := range []string{"If-None-Match", "If-Modified-Since", "Range"} {
+		if req.Header.Get(name) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// storable reports whether resp may be stored, and can be revalidated later.
+func storable(resp *http.Response) bool {
+	if strings.Contains(resp.Header.Get("Cache-Control"), "no-store") {
+		return false
+	}
+	return resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != ""
+}
+
+// cacheKey identifies the response to req. The Authorization header is hashed along with the rest of the
+// request, so that tokens are never written to a store.
+func cacheKey(req *http.Request) string {
+	hash := sha256.New()
+	for _, part := range []string{req.Header.Get("Authorization"), req.Header.Get("Accept"), req.URL.String()} {
+		_, _ = io.WriteString(hash, strconv.Itoa(len(part)))
+		_, _ = io.WriteString(hash, ":")
+		_, _ = io.WriteString(hash, part)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// readBody reads r up to limit bytes, or entirely if limit is negative, reporting whether r was longer.
+func readBody(r io.Reader, limit int64) ([]byte, bool, error) {
+	if limit < 0 {
+		body, err := io.ReadAll(r)
+		return body, false, err
+	}
+	body, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, false, err
+	}
+	return body, int64(len(body)) > limit, nil
+}
@@ -0,0 +1,105 @@
+package httpcache
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer returns a stand-in for the GitHub API serving body with an ETag, answering 304 Not Modified
+// when it is sent back, and counting requests by the conditional header they carried.
+func newTestServer(t *testing.T, body string) (*httptest.Server, map[string]int) {
+	t.Helper()
+
+	requests := make(map[string]int)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := `"` + r.Header.Get("Authorization") + `"`
+		requests[r.Header.Get("If-None-Match")]++
+
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-RateLimit-Remaining", "5000")
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(ts.Close)
+	return ts, requests
+}
+
+func get(t *testing.T, client *http.Client, url string, token string) (*http.Response, string) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func Test_TransportRevalidatesCachedResponses(t *testing.T) {
+	ts, requests := newTestServer(t, `{"number": 42}`)
+	client := &http.Client{Transport: &Transport{Store: NewMemoryStore(1 << 20)}}
+
+	resp, body := get(t, client, ts.URL+"/repos/owner/repo/pulls/42", "token-a")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"number": 42}`, body)
+	assert.Empty(t, resp.Header.Get("X-From-Cache"))
+
+	// The cached response is revalidated, and served once GitHub reports it unchanged
+	resp, body = get(t, client, ts.URL+"/repos/owner/repo/pulls/42", "token-a")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"number": 42}`, body)
+	assert.Equal(t, "1", resp.Header.Get("X-From-Cache"))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "4999", resp.Header.Get("X-RateLimit-Remaining"), "expected headers of the 304 response")
+	assert.Equal(t, 1, requests[`"Bearer token-a"`])
+
+	// Responses are never shared between tokens
+	resp, _ = get(t, client, ts.URL+"/repos/owner/repo/pulls/42", "token-b")
+	assert.Empty(t, resp.Header.Get("X-From-Cache"))
+	assert.Equal(t, 2, requests[""])
+}
+
+func Test_TransportSkipsUncacheableRequests(t *testing.T) {
+	ts, requests := newTestServer(t, `{"number": 42}`)
+	store := NewMemoryStore(1 << 20)
+	client := &http.Client{Transport: &Transport{Store: store}}
+
+	req, err := http.NewRequest(http.MethodPost, ts.URL+"/repos/owner/repo/issues", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	_, cached := store.Get(cacheKey(req))
+	assert.False(t, cached)
+	assert.Equal(t, 1, requests[""])
+}
+
+func Test_TransportSkipsLargeResponses(t *testing.T) {
+	large := strings.Repeat("a", 100)
+	ts, requests := newTestServer(t, large)
+	client := &http.Client{Transport: &Transport{Store: NewMemoryStore(1 << 20), MaxEntrySize: 10}}
+
+	_, body := get(t, client, ts.URL+"/repos/owner/repo/contents/file", "token")
+	assert.Equal(t, large, body, "expected the whole body despite not caching it")
+
+	_, body = get(t, client, ts.URL+"/repos/owner/repo/contents/file", "token")
+	assert.Equal(t, large, body)
+	assert.Equal(t, 2, requests[""])
+}
@@ -0,0 +1,211 @@
+package httpcache
+
+import (
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// lru tracks the size of stored entries, evicting the least recently used ones once they exceed maxSize.
+type lru struct {
+	maxSize int64
+	size    int64
+	order   *list.List // of *lruItem, most recently used first
+	items   map[string]*list.Element
+}
+
+type lruItem struct {
+	key   string
+	size  int64
+	entry *Entry // only kept by MemoryStore
+}
+
+func newLRU(maxSize int64) *lru {
+	return &lru{maxSize: maxSize, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+func (l *lru) get(key string) (*lruItem, bool) {
+	element, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	l.order.MoveToFront(element)
+	return element.Value.(*lruItem), true
+}
+
+// add records item as the most recently used, returning the keys evicted to make room for it.
+func (l *lru) add(item *lruItem) []string {
+	l.remove(item.key)
+	l.items[item.key] = l.order.PushFront(item)
+	l.size += item.size
+
+	var evicted []string
+	for l.size > l.maxSize && l.order.Len() > 0 {
+		oldest := l.order.Back().Value.(*lruItem)
+		l.remove(oldest.key)
+		evicted = append(evicted, oldest.key)
+	}
+	return evicted
+}
+
+func (l *lru) remove(key string) {
+	if element, ok := l.items[key]; ok {
+		l.size -= element.Value.(*lruItem).size
+		l.order.Remove(element)
+		delete(l.items, key)
+	}
+}
+
+// MemoryStore keeps entries in memory, up to a maximum total size.
+type MemoryStore struct {
+	mu  sync.Mutex
+	lru *lru
+}
+
+// NewMemoryStore creates a store keeping up to maxSize bytes of entries in memory.
+func NewMemoryStore(maxSize int64) *MemoryStore {
+	return &MemoryStore{lru: newLRU(maxSize)}
+}
+
+// Get returns the entry stored under key, if any.
+func (s *MemoryStore) Get(key string) (*Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	item, ok := s.lru.get(key)
+	if !ok {
+		return nil, false
+	}
+	return item.entry, true
+}
+
+// Set stores entry under key, evicting the least recently used entries to make room for it.
+func (s *MemoryStore) Set(key string, entry *Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lru.add(&lruItem{key: key, size: entry.size(), entry: entry})
+}
+
+// DiskStore keeps entries in files in a directory, up to a maximum total size. Entries are kept across
+// restarts, so the directory should only be readable by the current user.
+type DiskStore struct {
+	dir string
+
+	mu  sync.Mutex
+	lru *lru
+}
+
+// NewDiskStore creates a store keeping up to maxSize bytes of entries in dir, picking up the entries
+// left there by previous runs.
+func NewDiskStore(dir string, maxSize int64) (*DiskStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	type storedFile struct {
+		key     string
+		size    int64
+		modTime time.Time
+	}
+	var stored []storedFile
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		stored = append(stored, storedFile{
+			key:     file.Name()[:len(file.Name())-len(".json")],
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+	}
+
+	// Files are touched when used, so replaying them oldest first restores the order of use
+	sort.Slice(stored, func(i, j int) bool { return stored[i].modTime.Before(stored[j].modTime) })
+
+	s := &DiskStore{dir: dir, lru: newLRU(maxSize)}
+	for _, file := range stored {
+		s.evict(s.lru.add(&lruItem{key: file.key, size: file.size}))
+	}
+	return s, nil
+}
+
+// Get returns the entry stored under key, if any.
+func (s *DiskStore) Get(key string) (*Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lru.get(key); !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(s.path(key))
+	if err != nil {
+		s.lru.remove(key)
+		return nil, false
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		s.lru.remove(key)
+		s.evict([]string{key})
+		return nil, false
+	}
+
+	now := time.Now()
+	_ = os.Chtimes(s.path(key), now, now)
+	return &entry, true
+}
+
+// Set stores entry under key, evicting the least recently used entries to make room for it.
+// Entries that cannot be written are silently dropped, as the cache is only an optimization.
+func (s *DiskStore) Set(key string, entry *Entry) {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Write to a temporary file first so that a failed write never leaves a truncated entry behind
+	tmp, err := os.CreateTemp(s.dir, ".entry-*")
+	if err != nil {
+		return
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return
+	}
+	if err := tmp.Close(); err != nil {
+		return
+	}
+	if err := os.Rename(tmp.Name(), s.path(key)); err != nil {
+		return
+	}
+
+	s.evict(s.lru.add(&lruItem{key: key, size: int64(len(data))}))
+}
+
+func (s *DiskStore) evict(keys []string) {
+	for _, key := range keys {
+		_ = os.Remove(s.path(key))
+	}
+}
+
+func (s *DiskStore) path(key string) string {
+	return filepath.Join(s.dir, key+".json")
+}
@@ -0,0 +1,91 @@
+package httpcache
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEntry(body string) *Entry {
+	return &Entry{Header: http.Header{}, Body: []byte(body)}
+}
+
+func Test_MemoryStore(t *testing.T) {
+	store := NewMemoryStore(25)
+
+	store.Set("a", newEntry(strings.Repeat("a", 10)))
+	store.Set("b", newEntry(strings.Repeat("b", 10)))
+
+	// Using a makes b the least recently used entry, evicted to make room for c
+	_, ok := store.Get("a")
+	require.True(t, ok)
+	store.Set("c", newEntry(strings.Repeat("c", 10)))
+
+	entry, ok := store.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, strings.Repeat("a", 10), string(entry.Body))
+	_, ok = store.Get("b")
+	assert.False(t, ok)
+	_, ok = store.Get("c")
+	assert.True(t, ok)
+
+	// Entries larger than the store are not kept at all
+	store.Set("d", newEntry(strings.Repeat("d", 30)))
+	_, ok = store.Get("d")
+	assert.False(t, ok)
+}
+
+func Test_DiskStore(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewDiskStore(dir, 1<<20)
+	require.NoError(t, err)
+
+	entry := &Entry{Header: http.Header{"Etag": {`"abc"`}}, Body: []byte(`{"number": 42}`)}
+	store.Set("key", entry)
+
+	info, err := os.Stat(filepath.Join(dir, "key.json"))
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// Entries are picked up by later runs
+	store, err = NewDiskStore(dir, 1<<20)
+	require.NoError(t, err)
+	got, ok := store.Get("key")
+	require.True(t, ok)
+	assert.Equal(t, entry, got)
+
+	_, ok = store.Get("missing")
+	assert.False(t, ok)
+}
+
+func Test_DiskStoreEvictsLeastRecentlyUsedEntries(t *testing.T) {
+	dir := t.TempDir()
+
+	store, err := NewDiskStore(dir, 1<<20)
+	require.NoError(t, err)
+	store.Set("old", newEntry(strings.Repeat("o", 100)))
+	store.Set("new", newEntry(strings.Repeat("n", 100)))
+
+	// Make the order of use unambiguous despite coarse file system timestamps
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "old.json"), past, past))
+
+	info, err := os.Stat(filepath.Join(dir, "new.json"))
+	require.NoError(t, err)
+
+	// Reopening the store with room for a single entry keeps the most recently used one
+	_, err = NewDiskStore(dir, info.Size())
+	require.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, "old.json"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, "new.json"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,108 @@
+// Package lockdown withholds the content written by users without push access to a repository, such as the bodies
+// of issues and comments, from the model. Anyone can write them in a public repository, making them the easiest way
+// to inject prompts into an agent.
+package lockdown
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v74/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// Lockdown decides whose content is withheld by looking up their permission on the repository, remembering the
+// answers for the rest of the session.
+type Lockdown struct {
+	getClient func(context.Context) (*github.Client, error)
+
+	// sessions holds a *sync.Map of whether users can push to repositories for each session, keyed by its ID
+	sessions sync.Map
+}
+
+// New returns a Lockdown looking up permissions with the client returned by getClient for a tool call.
+func New(getClient func(context.Context) (*github.Client, error)) *Lockdown {
+	return &Lockdown{getClient: getClient}
+}
+
+// lockdownKey is the context key of the Lockdown of a tool call.
+type lockdownKey struct{}
+
+// ToolHandlerMiddleware puts the server in lockdown mode for every tool call, for Withhold to withhold content.
+func (l *Lockdown) ToolHandlerMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return next(context.WithValue(ctx, lockdownKey{}, l), request)
+	}
+}
+
+// ForgetSession drops the permissions looked up for a session once it is over.
+func (l *Lockdown) ForgetSession(sessionID string) {
+	l.sessions.Delete(sessionID)
+}
+
+// Withhold returns text written by author in owner/repo, or a placeholder in its stead if the server is in
+// lockdown mode and author cannot push to the repository. Content is also withheld when the permission of its
+// author cannot be looked up.
+func Withhold(ctx context.Context, owner, repo, author, text string) string {
+	l, ok := ctx.Value(lockdownKey{}).(*Lockdown)
+	if !ok || text == "" || l.canPush(ctx, owner, repo, author) {
+		return text
+	}
+	if author == "" {
+		author = "a deleted user"
+	}
+	return fmt.Sprintf("[Withheld in lockdown mode: written by %s, who cannot push to %s/%s]", author, owner, repo)
+}
+
+// canPush tells whether login can push to owner/repo, looking it up once per session.
+func (l *Lockdown) canPush(ctx context.Context, owner, repo, login string) bool {
+	if login == "" {
+		return false
+	}
+
+	var sessionID string
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		sessionID = session.SessionID()
+	}
+	permissions, _ := l.sessions.LoadOrStore(sessionID, &sync.Map{})
+
+	key := strings.ToLower(owner + "/" + repo + "/" + login)
+	if canPush, ok := permissions.(*sync.Map).Load(key); ok {
+		return canPush.(bool)
+	}
+	canPush, err := l.lookup(ctx, owner, repo, login)
+	if err != nil {
+		// Withhold the content, but look the permission up again next time
+		return false
+	}
+	permissions.(*sync.Map).Store(key, canPush)
+	return canPush
+}
+
+// lookup asks GitHub whether login can push to owner/repo.
+func (l *Lockdown) lookup(ctx context.Context, owner, repo, login string) (bool, error) {
+	client, err := l.getClient(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	level, resp, err := client.Repositories.GetPermissionLevel(ctx, owner, repo, login)
+	if err != nil {
+		// Neither users who are not collaborators, nor the collaborators of repositories the token cannot see
+		// the collaborators of, are known to have push access
+		if resp != nil && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if canPush, ok := level.GetUser().GetPermissions()["push"]; ok {
+		return canPush, nil
+	}
+	// The maintain role is reported as write
+	permission := level.GetPermission()
+	return permission == "admin" || permission == "write", nil
+}
@@ -0,0 +1,105 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+// RotatingFile is a log file that is rotated once it would grow beyond a maximum size: the file is renamed with the
+// suffix .1, the previous .1 becomes .2 and so on, up to a number of backups, and a new file is started.
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// OpenRotatingFile opens the log file at path for appending, rotating it before it grows beyond maxSize bytes and
+// keeping maxBackups rotated files. The file is never rotated if maxSize is 0.
+func OpenRotatingFile(path string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(os.O_APPEND); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open opens the file at the path, with flag added to those creating it for writing.
+func (f *RotatingFile) open(flag int) error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|flag, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to open log file: %w", err)
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write writes p to the file, rotating it first if p would take it beyond its maximum size. Records written at
+// once are never split across files. If the file cannot be rotated, it keeps growing.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var rotateErr error
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		rotateErr = f.rotate()
+		if f.file == nil {
+			return 0, rotateErr
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err == nil {
+		err = rotateErr
+	}
+	return n, err
+}
+
+// rotate moves the file to the first backup, shifting the others, and starts a new one.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to rotate log file: %w", err)
+	}
+	f.file = nil
+
+	var renameErr error
+	for i := f.maxBackups; i >= 1 && renameErr == nil; i-- {
+		from := f.path
+		if i > 1 {
+			from = f.backup(i - 1)
+		}
+		if err := os.Rename(from, f.backup(i)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			renameErr = fmt.Errorf("failed to rotate log file: %w", err)
+		}
+	}
+	if renameErr != nil {
+		// Carry on with the current file rather than losing it
+		if err := f.open(os.O_APPEND); err != nil {
+			return err
+		}
+		return renameErr
+	}
+	return f.open(os.O_TRUNC)
+}
+
+// backup returns the path of the ith backup.
+func (f *RotatingFile) backup(i int) string {
+	return fmt.Sprintf("%s.%d", f.path, i)
+}
+
+// Close closes the file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Close()
+}
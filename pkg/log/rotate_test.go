@@ -0,0 +1,79 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RotatingFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxSize       int64
+		maxBackups    int
+		expectedFiles map[string]string
+	}{
+		{
+			name:       "rotated",
+			maxSize:    10,
+			maxBackups: 2,
+			expectedFiles: map[string]string{
+				"server.log":   "line 5\n",
+				"server.log.1": "line 4\n",
+				"server.log.2": "line 3\n",
+			},
+		},
+		{
+			name:       "several records per file",
+			maxSize:    14,
+			maxBackups: 1,
+			expectedFiles: map[string]string{
+				"server.log":   "line 4\nline 5\n",
+				"server.log.1": "line 2\nline 3\n",
+			},
+		},
+		{
+			name:    "without backups",
+			maxSize: 10,
+			expectedFiles: map[string]string{
+				"server.log": "line 5\n",
+			},
+		},
+		{
+			name: "never rotated",
+			expectedFiles: map[string]string{
+				"server.log": "line 0\nline 1\nline 2\nline 3\nline 4\nline 5\n",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "server.log")
+
+			// The size of the file already there counts
+			require.NoError(t, os.WriteFile(path, []byte("line 0\n"), 0600))
+			f, err := OpenRotatingFile(path, tc.maxSize, tc.maxBackups)
+			require.NoError(t, err)
+			for _, line := range []string{"line 1\n", "line 2\n", "line 3\n", "line 4\n", "line 5\n"} {
+				_, err := f.Write([]byte(line))
+				require.NoError(t, err)
+			}
+			require.NoError(t, f.Close())
+
+			files := make(map[string]string)
+			entries, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			for _, entry := range entries {
+				data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+				require.NoError(t, err)
+				files[entry.Name()] = string(data)
+			}
+			assert.Equal(t, tc.expectedFiles, files)
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// Redacted replaces the text matching a redaction pattern in logged messages.
+const Redacted = "[REDACTED]"
+
+// tokenPattern matches GitHub tokens, which are always redacted.
+var tokenPattern = regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)
+
+// Redactor replaces secrets in text, such as GitHub tokens.
+type Redactor struct {
+	patterns []*regexp.Regexp
+}
+
+// NewRedactor returns a Redactor replacing the text matching any of the regular expressions given, in addition to
+// GitHub tokens.
+func NewRedactor(patterns []string) (*Redactor, error) {
+	r := &Redactor{patterns: []*regexp.Regexp{tokenPattern}}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %w", pattern, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+// Redact returns s with its secrets replaced by Redacted.
+func (r *Redactor) Redact(s string) string {
+	for _, re := range r.patterns {
+		s = re.ReplaceAllString(s, Redacted)
+	}
+	return s
+}
+
+// message holds the parts of a JSON-RPC message that are logged.
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params struct {
+		Name string `json:"name"`
+	} `json:"params"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// pendingRequest is a request waiting for its response, to log the method and duration of the response.
+type pendingRequest struct {
+	method string
+	tool   string
+	start  time.Time
+}
+
+// TrafficLogger wraps the reader and writer of the stdio transport, logging each newline-delimited JSON-RPC message
+// read from the client and written to it, with secrets redacted. Responses are logged with the method of their
+// request and how long it took to answer it.
+type TrafficLogger struct {
+	reader   io.Reader
+	writer   io.Writer
+	logger   *slog.Logger
+	redactor *Redactor
+
+	mu       sync.Mutex
+	received []byte
+	sent     []byte
+	// pending holds the requests not answered yet, keyed by direction and ID
+	pending map[string]pendingRequest
+
+	// now is overridable for testing
+	now func() time.Time
+}
+
+// NewTrafficLogger returns a TrafficLogger reading the client's messages from r and writing the server's to w,
+// logging them to logger with their text redacted by redactor.
+func NewTrafficLogger(r io.Reader, w io.Writer, logger *slog.Logger, redactor *Redactor) *TrafficLogger {
+	return &TrafficLogger{
+		reader:   r,
+		writer:   w,
+		logger:   logger,
+		redactor: redactor,
+		pending:  make(map[string]pendingRequest),
+		now:      time.Now,
+	}
+}
+
+// Read reads data from the underlying io.Reader, logging the messages it completes.
+func (l *TrafficLogger) Read(p []byte) (n int, err error) {
+	if l.reader == nil {
+		return 0, io.EOF
+	}
+	n, err = l.reader.Read(p)
+	if n > 0 {
+		l.mu.Lock()
+		l.received = l.logLines(append(l.received, p[:n]...), "received")
+		l.mu.Unlock()
+	}
+	return n, err
+}
+
+// Write writes data to the underlying io.Writer, logging the messages it completes.
+func (l *TrafficLogger) Write(p []byte) (n int, err error) {
+	if l.writer == nil {
+		return 0, io.ErrClosedPipe
+	}
+	l.mu.Lock()
+	l.sent = l.logLines(append(l.sent, p...), "sent")
+	l.mu.Unlock()
+	return l.writer.Write(p)
+}
+
+// logLines logs the complete lines of buf as messages going in direction, returning the incomplete line left.
+func (l *TrafficLogger) logLines(buf []byte, direction string) []byte {
+	for {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			return buf
+		}
+		if line := bytes.TrimSpace(buf[:i]); len(line) > 0 {
+			l.logMessage(line, direction)
+		}
+		buf = buf[i+1:]
+	}
+}
+
+// logMessage logs a line holding a JSON-RPC message, or a batch of them.
+func (l *TrafficLogger) logMessage(line []byte, direction string) {
+	attrs := []any{"direction", direction, "size", len(line)}
+
+	var batch []json.RawMessage
+	if line[0] == '[' && json.Unmarshal(line, &batch) == nil {
+		for _, raw := range batch {
+			l.logMessage(raw, direction)
+		}
+		return
+	}
+
+	var msg message
+	if err := json.Unmarshal(line, &msg); err != nil {
+		l.logger.Warn("invalid JSON-RPC message", append(attrs, "message", l.redactor.Redact(string(line)))...)
+		return
+	}
+
+	id := string(msg.ID)
+	if id == "null" {
+		id = ""
+	}
+	if id != "" {
+		attrs = append(attrs, "id", id)
+	}
+	switch {
+	case msg.Method != "" && id != "":
+		// Requests are answered in the other direction
+		l.pending[direction+" "+id] = pendingRequest{method: msg.Method, tool: msg.Params.Name, start: l.now()}
+		attrs = append(attrs, "kind", "request", "method", msg.Method)
+		if msg.Params.Name != "" && msg.Method == "tools/call" {
+			attrs = append(attrs, "tool", msg.Params.Name)
+		}
+	case msg.Method != "":
+		attrs = append(attrs, "kind", "notification", "method", msg.Method)
+	default:
+		attrs = append(attrs, "kind", "response")
+		requestDirection := "received"
+		if direction == "received" {
+			requestDirection = "sent"
+		}
+		if request, ok := l.pending[requestDirection+" "+id]; ok {
+			delete(l.pending, requestDirection+" "+id)
+			attrs = append(attrs, "method", request.method)
+			if request.tool != "" && request.method == "tools/call" {
+				attrs = append(attrs, "tool", request.tool)
+			}
+			attrs = append(attrs, "duration", l.now().Sub(request.start))
+		}
+		if msg.Error != nil {
+			attrs = append(attrs, "error_code", msg.Error.Code, "error", l.redactor.Redact(msg.Error.Message))
+		}
+	}
+
+	l.logger.Info(direction+" JSON-RPC message", append(attrs, "message", l.redactor.Redact(string(line)))...)
+}
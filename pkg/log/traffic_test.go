@@ -0,0 +1,99 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TrafficLogger(t *testing.T) {
+	token := "ghp_" + strings.Repeat("a", 36)
+	client := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"get_me","arguments":{}}}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":0}}` + "\n" +
+		`{"jsonrpc":"2.0","id":"elicit-1","result":{"action":"accept"}}` + "\n" +
+		"not json\n"
+
+	var logBuffer bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logBuffer, &slog.HandlerOptions{ReplaceAttr: removeTimeAttr}))
+	redactor, err := NewRedactor([]string{`secret-[0-9]+`})
+	require.NoError(t, err)
+
+	// Messages are read in chunks splitting them arbitrarily
+	var out bytes.Buffer
+	l := NewTrafficLogger(io.MultiReader(strings.NewReader(client[:30]), strings.NewReader(client[30:])), &out, logger, redactor)
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+
+	read := make([]byte, 0, len(client))
+	buf := make([]byte, 64)
+	for {
+		n, err := l.Read(buf)
+		read = append(read, buf[:n]...)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		// The server asks to elicit a confirmation while the tool call is being answered
+		if n > 0 && len(read) > 100 && out.Len() == 0 {
+			_, err := l.Write([]byte(`{"jsonrpc":"2.0","id":"elicit-1","method":"elicitation/create","params":{"message":"Confirm?"}}` + "\n"))
+			require.NoError(t, err)
+		}
+	}
+	assert.Equal(t, client, string(read))
+
+	now = now.Add(1500 * time.Millisecond)
+	response := `{"jsonrpc":"2.0","id":1,"result":{"content":[{"type":"text","text":"{\"login\":\"octocat\",\"token\":\"` + token + `\",\"note\":\"secret-42\"}"}]}}` + "\n"
+	_, err = l.Write([]byte(response[:20]))
+	require.NoError(t, err)
+	_, err = l.Write([]byte(response[20:]))
+	require.NoError(t, err)
+	_, err = l.Write([]byte(`{"jsonrpc":"2.0","id":2,"error":{"code":-32601,"message":"Method not found"}}` + "\n"))
+	require.NoError(t, err)
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logBuffer.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		delete(record, "message")
+		records = append(records, record)
+	}
+	assert.Equal(t, []map[string]any{
+		{"level": "INFO", "msg": "received JSON-RPC message", "direction": "received", "size": float64(88), "id": "1", "kind": "request", "method": "tools/call", "tool": "get_me"},
+		{"level": "INFO", "msg": "sent JSON-RPC message", "direction": "sent", "size": float64(95), "id": `"elicit-1"`, "kind": "request", "method": "elicitation/create"},
+		{"level": "INFO", "msg": "received JSON-RPC message", "direction": "received", "size": float64(77), "kind": "notification", "method": "notifications/cancelled"},
+		{"level": "INFO", "msg": "received JSON-RPC message", "direction": "received", "size": float64(62), "id": `"elicit-1"`, "kind": "response", "method": "elicitation/create", "duration": float64(0)},
+		{"level": "WARN", "msg": "invalid JSON-RPC message", "direction": "received", "size": float64(8)},
+		{"level": "INFO", "msg": "sent JSON-RPC message", "direction": "sent", "size": float64(len(response) - 1), "id": "1", "kind": "response", "method": "tools/call", "tool": "get_me", "duration": float64(1500 * time.Millisecond)},
+		{"level": "INFO", "msg": "sent JSON-RPC message", "direction": "sent", "size": float64(77), "id": "2", "kind": "response", "error_code": float64(-32601), "error": "Method not found"},
+	}, records)
+
+	// Secrets are redacted from the messages logged, but not from those sent
+	assert.NotContains(t, logBuffer.String(), token)
+	assert.NotContains(t, logBuffer.String(), "secret-42")
+	assert.Contains(t, logBuffer.String(), `\"token\\\":\\\"[REDACTED]\\\"`)
+	assert.Contains(t, out.String(), token)
+}
+
+func Test_NewRedactor(t *testing.T) {
+	_, err := NewRedactor([]string{`(unclosed`})
+	assert.ErrorContains(t, err, `invalid redaction pattern "(unclosed"`)
+
+	redactor, err := NewRedactor(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer [REDACTED] and [REDACTED]", redactor.Redact("Bearer github_pat_"+strings.Repeat("A", 30)+" and ghs_"+strings.Repeat("1", 36)))
+}
+
+func removeTimeAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.TimeKey && len(groups) == 0 {
+		return slog.Attr{}
+	}
+	return a
+}
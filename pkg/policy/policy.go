@@ -0,0 +1,275 @@
+// Package policy restricts the repositories the server's tools can access, whatever the token allows.
+package policy
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// pattern matches repositories by owner and name, each a glob as understood by path.Match.
+type pattern struct {
+	owner string
+	repo  string
+}
+
+func parsePattern(s string) (pattern, error) {
+	owner, repo, found := strings.Cut(strings.ToLower(s), "/")
+	if !found {
+		// A bare owner covers all of its repositories
+		repo = "*"
+	}
+	if owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return pattern{}, fmt.Errorf("invalid repository pattern %q, expected owner/repo", s)
+	}
+	for _, glob := range []string{owner, repo} {
+		if _, err := path.Match(glob, ""); err != nil {
+			return pattern{}, fmt.Errorf("invalid repository pattern %q: %w", s, err)
+		}
+	}
+	return pattern{owner: owner, repo: repo}, nil
+}
+
+func (p pattern) matchesOwner(owner string) bool {
+	ok, _ := path.Match(p.owner, owner)
+	return ok
+}
+
+func (p pattern) matches(owner, repo string) bool {
+	ok, _ := path.Match(p.repo, repo)
+	return ok && p.matchesOwner(owner)
+}
+
+// Policy allows access to the repositories matching any of its allow patterns, or to every repository if there
+// are none, except those matching any of its deny patterns. A nil Policy allows everything.
+type Policy struct {
+	allow []pattern
+	deny  []pattern
+}
+
+// New creates a policy from owner/repo patterns such as myorg/* or myorg/secrets-*. A bare owner, such as
+// myorg, stands for all of its repositories.
+func New(allow, deny []string) (*Policy, error) {
+	p := &Policy{}
+	for _, s := range allow {
+		pattern, err := parsePattern(s)
+		if err != nil {
+			return nil, err
+		}
+		p.allow = append(p.allow, pattern)
+	}
+	for _, s := range deny {
+		pattern, err := parsePattern(s)
+		if err != nil {
+			return nil, err
+		}
+		p.deny = append(p.deny, pattern)
+	}
+	return p, nil
+}
+
+// AllowsRepo reports whether the repository may be accessed.
+func (p *Policy) AllowsRepo(owner, repo string) bool {
+	if p == nil {
+		return true
+	}
+	owner, repo = strings.ToLower(owner), strings.ToLower(repo)
+	for _, pattern := range p.deny {
+		if pattern.matches(owner, repo) {
+			return false
+		}
+	}
+	if len(p.allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.allow {
+		if pattern.matches(owner, repo) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsOwner reports whether some of the owner's repositories may be accessed, for tools acting on an owner
+// rather than a single repository.
+func (p *Policy) AllowsOwner(owner string) bool {
+	if p == nil {
+		return true
+	}
+	owner = strings.ToLower(owner)
+	for _, pattern := range p.deny {
+		if pattern.repo == "*" && pattern.matchesOwner(owner) {
+			return false
+		}
+	}
+	if len(p.allow) == 0 {
+		return true
+	}
+	for _, pattern := range p.allow {
+		if pattern.matchesOwner(owner) {
+			return true
+		}
+	}
+	return false
+}
+
+// AllowsRepoURL reports whether the repository of a GitHub API or web URL, such as the repository_url of an
+// issue, may be accessed. URLs not naming a repository are not allowed.
+func (p *Policy) AllowsRepoURL(repoURL string) bool {
+	if p == nil {
+		return true
+	}
+	parts := strings.Split(strings.TrimSuffix(repoURL, "/"), "/")
+	if len(parts) < 2 {
+		return false
+	}
+	return p.AllowsRepo(parts[len(parts)-2], parts[len(parts)-1])
+}
+
+// AllowsFullName reports whether the repository with the given owner/repo name may be accessed.
+func (p *Policy) AllowsFullName(fullName string) bool {
+	if p == nil {
+		return true
+	}
+	owner, repo, ok := strings.Cut(fullName, "/")
+	return ok && p.AllowsRepo(owner, repo)
+}
+
+// searchQualifierPattern matches the search qualifiers restricting the repositories searched.
+var searchQualifierPattern = regexp.MustCompile(`(?:^|[\s(])(-?)(repo|org|user):"?([^\s"()]+)"?`)
+
+// ScopeSearchQuery checks the repository qualifiers of a search query against the policy, and restricts queries
+// without any to the owners of the allowed repositories. As search qualifiers cannot express every pattern,
+// search results must still be checked with AllowsRepo.
+func (p *Policy) ScopeSearchQuery(query string) (string, error) {
+	if p == nil {
+		return query, nil
+	}
+
+	scoped := false
+	for _, match := range searchQualifierPattern.FindAllStringSubmatch(query, -1) {
+		if match[1] == "-" {
+			// Excluding repositories never widens the search
+			continue
+		}
+		scoped = true
+		if match[2] == "repo" {
+			owner, repo, _ := strings.Cut(match[3], "/")
+			if !p.AllowsRepo(owner, repo) {
+				return "", fmt.Errorf("repository %s is not allowed by the repository access policy", match[3])
+			}
+			continue
+		}
+		if !p.AllowsOwner(match[3]) {
+			return "", fmt.Errorf("owner %s is not allowed by the repository access policy", match[3])
+		}
+	}
+	if scoped {
+		return query, nil
+	}
+
+	owners := p.allowedOwners()
+	if len(owners) == 0 {
+		return query, nil
+	}
+	qualifiers := make([]string, 0, len(owners))
+	for _, owner := range owners {
+		qualifiers = append(qualifiers, "user:"+owner)
+	}
+	return strings.Join(qualifiers, " ") + " " + query, nil
+}
+
+// allowedOwners returns the owners of the allowed repositories, or nothing if they cannot be listed because
+// there are no allow patterns or some of them match several owners.
+func (p *Policy) allowedOwners() []string {
+	seen := make(map[string]bool)
+	var owners []string
+	for _, pattern := range p.allow {
+		if strings.ContainsAny(pattern.owner, `*?[\`) {
+			return nil
+		}
+		if !seen[pattern.owner] {
+			seen[pattern.owner] = true
+			owners = append(owners, pattern.owner)
+		}
+	}
+	sort.Strings(owners)
+	return owners
+}
+
+// CheckArguments rejects the owner and repo arguments of a tool call, and the org and organization arguments of
+// tools acting on an organization, that the policy does not allow.
+func (p *Policy) CheckArguments(args map[string]any) error {
+	if p == nil {
+		return nil
+	}
+
+	owner, _ := args["owner"].(string)
+	repo, _ := args["repo"].(string)
+	switch {
+	case owner != "" && repo != "":
+		if !p.AllowsRepo(owner, repo) {
+			return fmt.Errorf("repository %s/%s is not allowed by the repository access policy", owner, repo)
+		}
+	case owner != "":
+		if !p.AllowsOwner(owner) {
+			return fmt.Errorf("owner %s is not allowed by the repository access policy", owner)
+		}
+	}
+
+	for _, param := range []string{"org", "organization"} {
+		if org, _ := args[param].(string); org != "" && !p.AllowsOwner(org) {
+			return fmt.Errorf("organization %s is not allowed by the repository access policy", org)
+		}
+	}
+	return nil
+}
+
+// ToolHandlerMiddleware rejects tool calls whose arguments the policy does not allow, and makes the policy
+// available to the tools through their context, for them to scope searches and filter results.
+func (p *Policy) ToolHandlerMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		if err := p.CheckArguments(request.GetArguments()); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+		return next(ContextWithPolicy(ctx, p), request)
+	}
+}
+
+type policyContextKey struct{}
+
+// ContextWithPolicy returns a copy of ctx carrying the policy.
+func ContextWithPolicy(ctx context.Context, p *Policy) context.Context {
+	return context.WithValue(ctx, policyContextKey{}, p)
+}
+
+// FromContext returns the policy carried by ctx, or nil, which allows everything, if there is none.
+func FromContext(ctx context.Context) *Policy {
+	p, _ := ctx.Value(policyContextKey{}).(*Policy)
+	return p
+}
+
+// ResourceTemplateHandler rejects reads of resources, such as repo://{owner}/{repo}/contents{/path*}, whose owner
+// and repo the policy does not allow.
+func (p *Policy) ResourceTemplateHandler(next server.ResourceTemplateHandlerFunc) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		args := make(map[string]any, len(request.Params.Arguments))
+		for name, value := range request.Params.Arguments {
+			// Template variables are matched as lists of values
+			if values, ok := value.([]string); ok && len(values) > 0 {
+				value = values[0]
+			}
+			args[name] = value
+		}
+		if err := p.CheckArguments(args); err != nil {
+			return nil, err
+		}
+		return next(ContextWithPolicy(ctx, p), request)
+	}
+}
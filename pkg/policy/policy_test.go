@@ -0,0 +1,187 @@
+package policy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPolicy(t *testing.T) *Policy {
+	t.Helper()
+	p, err := New([]string{"myorg/*", "octocat/hello-world"}, []string{"myorg/secrets-*"})
+	require.NoError(t, err)
+	return p
+}
+
+func Test_AllowsRepo(t *testing.T) {
+	p := newTestPolicy(t)
+
+	tests := []struct {
+		owner    string
+		repo     string
+		expected bool
+	}{
+		{"myorg", "service", true},
+		{"MyOrg", "Service", true},
+		{"myorg", "secrets-prod", false},
+		{"octocat", "hello-world", true},
+		{"octocat", "other", false},
+		{"other", "service", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.owner+"/"+tc.repo, func(t *testing.T) {
+			assert.Equal(t, tc.expected, p.AllowsRepo(tc.owner, tc.repo))
+		})
+	}
+
+	var nilPolicy *Policy
+	assert.True(t, nilPolicy.AllowsRepo("anyone", "anything"))
+
+	denyOnly, err := New(nil, []string{"myorg"})
+	require.NoError(t, err)
+	assert.True(t, denyOnly.AllowsRepo("other", "service"))
+	assert.False(t, denyOnly.AllowsRepo("myorg", "service"))
+	assert.False(t, denyOnly.AllowsOwner("myorg"))
+}
+
+func Test_AllowsOwner(t *testing.T) {
+	p := newTestPolicy(t)
+
+	assert.True(t, p.AllowsOwner("myorg"))
+	assert.True(t, p.AllowsOwner("octocat"))
+	assert.False(t, p.AllowsOwner("other"))
+}
+
+func Test_NewRejectsInvalidPatterns(t *testing.T) {
+	_, err := New([]string{"myorg/service/extra"}, nil)
+	assert.EqualError(t, err, `invalid repository pattern "myorg/service/extra", expected owner/repo`)
+
+	_, err = New(nil, []string{"myorg/[secrets"})
+	assert.ErrorContains(t, err, `invalid repository pattern "myorg/[secrets"`)
+}
+
+func Test_ScopeSearchQuery(t *testing.T) {
+	p := newTestPolicy(t)
+
+	tests := []struct {
+		name           string
+		query          string
+		expected       string
+		expectedErrMsg string
+	}{
+		{
+			name:     "unscoped query gets owner qualifiers",
+			query:    "is:issue bug",
+			expected: "user:myorg user:octocat is:issue bug",
+		},
+		{
+			name:     "allowed repository",
+			query:    "repo:myorg/service bug",
+			expected: "repo:myorg/service bug",
+		},
+		{
+			name:     "allowed owner",
+			query:    "bug (org:myorg)",
+			expected: "bug (org:myorg)",
+		},
+		{
+			name:     "excluded repositories do not scope the query",
+			query:    "bug -repo:myorg/other",
+			expected: "user:myorg user:octocat bug -repo:myorg/other",
+		},
+		{
+			name:           "denied repository",
+			query:          "repo:myorg/secrets-prod password",
+			expectedErrMsg: "repository myorg/secrets-prod is not allowed by the repository access policy",
+		},
+		{
+			name:           "other owner",
+			query:          "user:other bug",
+			expectedErrMsg: "owner other is not allowed by the repository access policy",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := p.ScopeSearchQuery(tc.query)
+			if tc.expectedErrMsg != "" {
+				require.EqualError(t, err, tc.expectedErrMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, query)
+		})
+	}
+
+	// Owners cannot be listed when they are matched by a pattern, leaving results to be filtered
+	wildcardOwners, err := New([]string{"team-*/*"}, nil)
+	require.NoError(t, err)
+	query, err := wildcardOwners.ScopeSearchQuery("bug")
+	require.NoError(t, err)
+	assert.Equal(t, "bug", query)
+}
+
+func Test_ToolHandlerMiddleware(t *testing.T) {
+	p := newTestPolicy(t)
+
+	var called bool
+	handler := p.ToolHandlerMiddleware(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		called = true
+		assert.Same(t, p, FromContext(ctx))
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	tests := []struct {
+		name           string
+		args           map[string]any
+		expectedErrMsg string
+	}{
+		{name: "allowed repository", args: map[string]any{"owner": "myorg", "repo": "service"}},
+		{name: "no repository", args: map[string]any{"query": "bug"}},
+		{name: "allowed owner", args: map[string]any{"owner": "octocat"}},
+		{name: "denied repository", args: map[string]any{"owner": "myorg", "repo": "secrets-prod"}, expectedErrMsg: "repository myorg/secrets-prod is not allowed by the repository access policy"},
+		{name: "other owner", args: map[string]any{"owner": "other"}, expectedErrMsg: "owner other is not allowed by the repository access policy"},
+		{name: "other organization", args: map[string]any{"org": "other", "team_slug": "admins"}, expectedErrMsg: "organization other is not allowed by the repository access policy"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			called = false
+			request := mcp.CallToolRequest{}
+			request.Params.Arguments = tc.args
+
+			result, err := handler(context.Background(), request)
+			require.NoError(t, err)
+			if tc.expectedErrMsg != "" {
+				require.True(t, result.IsError)
+				assert.False(t, called)
+				assert.Equal(t, tc.expectedErrMsg, result.Content[0].(mcp.TextContent).Text)
+				return
+			}
+			assert.False(t, result.IsError)
+			assert.True(t, called)
+		})
+	}
+}
+
+func Test_ResourceTemplateHandler(t *testing.T) {
+	p := newTestPolicy(t)
+
+	handler := p.ResourceTemplateHandler(func(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return []mcp.ResourceContents{mcp.TextResourceContents{Text: "contents"}}, nil
+	})
+
+	request := mcp.ReadResourceRequest{}
+	request.Params.Arguments = map[string]any{"owner": []string{"myorg"}, "repo": []string{"service"}}
+	contents, err := handler(context.Background(), request)
+	require.NoError(t, err)
+	assert.Len(t, contents, 1)
+
+	request.Params.Arguments = map[string]any{"owner": []string{"myorg"}, "repo": []string{"secrets-prod"}}
+	_, err = handler(context.Background(), request)
+	assert.EqualError(t, err, "repository myorg/secrets-prod is not allowed by the repository access policy")
+}
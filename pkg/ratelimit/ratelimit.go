@@ -0,0 +1,266 @@
+// Package ratelimit retries requests to the GitHub API that hit its primary or secondary rate limits.
+// See: https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// DefaultMaxRetries is the default maximum number of times a rate limited request is retried.
+const DefaultMaxRetries = 3
+
+// DefaultMaxWait is the default maximum total time spent waiting to retry a rate limited request, leaving room
+// for at least one retry after a secondary rate limit.
+const DefaultMaxWait = 3 * time.Minute
+
+// secondaryLimitWait is how long to wait after hitting a secondary rate limit without a Retry-After header,
+// as recommended by GitHub's documentation.
+const secondaryLimitWait = time.Minute
+
+// Error is returned for requests that were rate limited and could not be retried within the budget.
+type Error struct {
+	// Until is when the rate limit is expected to be lifted
+	Until time.Time
+
+	// Secondary is true if a secondary rate limit was hit, rather than the primary one
+	Secondary bool
+}
+
+func (e *Error) Error() string {
+	kind := "primary"
+	if e.Secondary {
+		kind = "secondary"
+	}
+	return fmt.Sprintf("GitHub API %s rate limit exceeded, rate limited until %s", kind, e.Until.UTC().Format(time.RFC3339))
+}
+
+// Transport is an http.RoundTripper retrying rate limited requests, waiting for the limit to be lifted with
+// some jitter. Only requests that are safe to repeat are retried: those with idempotent methods, and GraphQL
+// queries. Other rate limited requests, and those that cannot be retried within the budget, fail with an *Error.
+type Transport struct {
+	// Base is the underlying RoundTripper, http.DefaultTransport if nil.
+	Base http.RoundTripper
+
+	// MaxRetries is the maximum number of times a request is retried
+	MaxRetries int
+
+	// MaxWait is the maximum total time spent waiting to retry a request
+	MaxWait time.Duration
+
+	// now and sleep are overridable for testing
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// RoundTrip sends req using the base transport, retrying it while it is rate limited and the budget allows.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	now := t.now
+	if now == nil {
+		now = time.Now
+	}
+	sleep := t.sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	retryable := isRetryable(req)
+	var waited time.Duration
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.Body != nil {
+			// The body of the previous attempt has been consumed
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		resp, err := base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+
+		wait, secondary, limited := rateLimited(resp, now())
+		if !limited {
+			return resp, nil
+		}
+		_ = resp.Body.Close()
+
+		// Back off exponentially when GitHub does not say how long to wait
+		if wait == 0 {
+			wait = secondaryLimitWait << attempt
+		}
+		// Jitter never takes a wait over the budget
+		wait += min(jitter(wait), max(t.MaxWait-waited-wait, 0))
+
+		if !retryable || attempt >= t.MaxRetries || waited+wait > t.MaxWait {
+			rateLimitErr := &Error{Until: now().Add(wait), Secondary: secondary}
+			record(req.Context(), rateLimitErr)
+			return nil, rateLimitErr
+		}
+
+		if err := sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+		waited += wait
+	}
+}
+
+// rateLimited reports whether resp was rate limited, how long to wait before retrying, if known, and whether
+// the limit hit was a secondary one.
+func rateLimited(resp *http.Response, now time.Time) (time.Duration, bool, bool) {
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	retryAfter := resp.Header.Get("Retry-After")
+
+	switch resp.StatusCode {
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		// Both are also returned for reasons other than rate limits
+		if remaining != "0" && retryAfter == "" && !bodyContains(resp, "rate limit") {
+			return 0, false, false
+		}
+	case http.StatusOK:
+		// The GraphQL API reports exceeding the primary rate limit as an error in a successful response
+		if remaining != "0" || !bodyContains(resp, `"RATE_LIMITED"`) {
+			return 0, false, false
+		}
+	default:
+		return 0, false, false
+	}
+
+	if retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(seconds) * time.Second, true, true
+		}
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return max(date.Sub(now), 0), true, true
+		}
+	}
+
+	if remaining == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0), false, true
+		}
+	}
+
+	return 0, true, true
+}
+
+// bodyContains reports whether the body of resp contains s, leaving the body intact for the caller.
+func bodyContains(resp *http.Response, s string) bool {
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return err == nil && bytes.Contains(bytes.ToLower(body), bytes.ToLower([]byte(s)))
+}
+
+// isRetryable reports whether req can safely be sent again.
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	case http.MethodPost:
+		// GraphQL queries are sent as POST requests, but unlike mutations have no side effects
+		if req.GetBody == nil || !strings.HasSuffix(req.URL.Path, "/graphql") {
+			return false
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return false
+		}
+		defer func() { _ = body.Close() }()
+
+		var graphQLRequest struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(body).Decode(&graphQLRequest); err != nil {
+			return false
+		}
+		query := strings.TrimSpace(graphQLRequest.Query)
+		return strings.HasPrefix(query, "query") || strings.HasPrefix(query, "{")
+	default:
+		return false
+	}
+}
+
+// jitter returns a random duration of up to a tenth of d, so that concurrent requests do not retry in lockstep.
+func jitter(d time.Duration) time.Duration {
+	if d < 10 {
+		return 0
+	}
+	return rand.N(d / 10)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type recorderKey struct{}
+
+// recorder keeps the last rate limit error hit by the requests made for a tool call.
+type recorder struct {
+	mu  sync.Mutex
+	err *Error
+}
+
+func record(ctx context.Context, err *Error) {
+	if r, ok := ctx.Value(recorderKey{}).(*recorder); ok {
+		r.mu.Lock()
+		r.err = err
+		r.mu.Unlock()
+	}
+}
+
+// ToolHandlerMiddleware replaces the result of tool calls that failed because of a rate limit with a structured
+// error telling the model when it can try again, however the tool reported the failure.
+func ToolHandlerMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		r := &recorder{}
+		result, err := next(context.WithValue(ctx, recorderKey{}, r), request)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.err == nil || (err == nil && result != nil && !result.IsError) {
+			return result, err
+		}
+		return NewToolResultError(r.err), nil
+	}
+}
+
+// NewToolResultError returns a tool error reporting when the rate limit is lifted, in both text and structured form.
+func NewToolResultError(err *Error) *mcp.CallToolResult {
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{mcp.NewTextContent(err.Error())},
+		StructuredContent: map[string]any{
+			"error":              "rate_limited",
+			"secondary_limit":    err.Secondary,
+			"rate_limited_until": err.Until.UTC().Format(time.RFC3339),
+		},
+		IsError: true,
+	}
+}
@@ -0,0 +1,291 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Transport(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	primaryLimit := func(w http.ResponseWriter) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(now.Add(30*time.Second).Unix()))
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "API rate limit exceeded"}`))
+	}
+	secondaryLimit := func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "5")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit"}`))
+	}
+
+	secondaryLimitWithoutRetryAfter := func(w http.ResponseWriter) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit"}`))
+	}
+	secondaryLimitOfAMinute := func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "You have exceeded a secondary rate limit"}`))
+	}
+
+	tests := []struct {
+		name             string
+		method           string
+		path             string
+		body             string
+		limits           []func(w http.ResponseWriter)
+		maxRetries       int
+		maxWait          time.Duration
+		expectedAttempts int
+		expectedWaits    []time.Duration
+		expectedErr      *Error
+	}{
+		{
+			name:             "not rate limited",
+			method:           http.MethodGet,
+			path:             "/repos/owner/repo",
+			maxRetries:       3,
+			maxWait:          time.Minute,
+			expectedAttempts: 1,
+		},
+		{
+			name:             "GET retried until the primary limit resets",
+			method:           http.MethodGet,
+			path:             "/repos/owner/repo",
+			limits:           []func(w http.ResponseWriter){primaryLimit},
+			maxRetries:       3,
+			maxWait:          time.Minute,
+			expectedAttempts: 2,
+			expectedWaits:    []time.Duration{30 * time.Second},
+		},
+		{
+			name:             "GET retried after the secondary limit's Retry-After",
+			method:           http.MethodGet,
+			path:             "/search/code",
+			limits:           []func(w http.ResponseWriter){secondaryLimit, secondaryLimit},
+			maxRetries:       3,
+			maxWait:          time.Minute,
+			expectedAttempts: 3,
+			expectedWaits:    []time.Duration{5 * time.Second, 5 * time.Second},
+		},
+		{
+			name:             "GraphQL query retried",
+			method:           http.MethodPost,
+			path:             "/graphql",
+			body:             `{"query":"query{viewer{login}}"}`,
+			limits:           []func(w http.ResponseWriter){secondaryLimit},
+			maxRetries:       3,
+			maxWait:          time.Minute,
+			expectedAttempts: 2,
+			expectedWaits:    []time.Duration{5 * time.Second},
+		},
+		{
+			name:             "GraphQL mutation not retried",
+			method:           http.MethodPost,
+			path:             "/graphql",
+			body:             `{"query":"mutation{addStar(input:{}){clientMutationId}}"}`,
+			limits:           []func(w http.ResponseWriter){secondaryLimit},
+			maxRetries:       3,
+			maxWait:          time.Minute,
+			expectedAttempts: 1,
+			expectedErr:      &Error{Until: now.Add(5 * time.Second), Secondary: true},
+		},
+		{
+			name:             "REST POST not retried",
+			method:           http.MethodPost,
+			path:             "/repos/owner/repo/issues",
+			body:             `{"title":"title"}`,
+			limits:           []func(w http.ResponseWriter){primaryLimit},
+			maxRetries:       3,
+			maxWait:          time.Minute,
+			expectedAttempts: 1,
+			expectedErr:      &Error{Until: now.Add(30 * time.Second)},
+		},
+		{
+			name:             "retries exhausted",
+			method:           http.MethodGet,
+			path:             "/search/issues",
+			limits:           []func(w http.ResponseWriter){secondaryLimit, secondaryLimit},
+			maxRetries:       1,
+			maxWait:          time.Minute,
+			expectedAttempts: 2,
+			expectedWaits:    []time.Duration{5 * time.Second},
+			expectedErr:      &Error{Until: now.Add(5 * time.Second), Secondary: true},
+		},
+		{
+			name:             "secondary limit without Retry-After retried with the default budget",
+			method:           http.MethodGet,
+			path:             "/search/code",
+			limits:           []func(w http.ResponseWriter){secondaryLimitWithoutRetryAfter},
+			maxRetries:       DefaultMaxRetries,
+			maxWait:          DefaultMaxWait,
+			expectedAttempts: 2,
+			expectedWaits:    []time.Duration{time.Minute},
+		},
+		{
+			name:             "secondary limit of a minute retried with the default budget",
+			method:           http.MethodGet,
+			path:             "/search/issues",
+			limits:           []func(w http.ResponseWriter){secondaryLimitOfAMinute},
+			maxRetries:       DefaultMaxRetries,
+			maxWait:          DefaultMaxWait,
+			expectedAttempts: 2,
+			expectedWaits:    []time.Duration{time.Minute},
+		},
+		{
+			name:             "jitter not taking a wait over the budget",
+			method:           http.MethodGet,
+			path:             "/repos/owner/repo",
+			limits:           []func(w http.ResponseWriter){primaryLimit},
+			maxRetries:       3,
+			maxWait:          30 * time.Second,
+			expectedAttempts: 2,
+			expectedWaits:    []time.Duration{30 * time.Second},
+		},
+		{
+			name:             "wait exceeding the budget",
+			method:           http.MethodGet,
+			path:             "/repos/owner/repo",
+			limits:           []func(w http.ResponseWriter){primaryLimit},
+			maxRetries:       3,
+			maxWait:          10 * time.Second,
+			expectedAttempts: 1,
+			expectedErr:      &Error{Until: now.Add(30 * time.Second)},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				assert.Equal(t, tc.body, string(body))
+
+				attempts++
+				if attempts <= len(tc.limits) {
+					tc.limits[attempts-1](w)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer ts.Close()
+
+			var waits []time.Duration
+			transport := &Transport{
+				MaxRetries: tc.maxRetries,
+				MaxWait:    tc.maxWait,
+				now:        func() time.Time { return now },
+				sleep: func(_ context.Context, d time.Duration) error {
+					waits = append(waits, d)
+					return nil
+				},
+			}
+
+			req, err := http.NewRequest(tc.method, ts.URL+tc.path, strings.NewReader(tc.body))
+			require.NoError(t, err)
+			if tc.body == "" {
+				req.Body, req.GetBody = nil, nil
+			}
+
+			resp, err := transport.RoundTrip(req)
+			assert.Equal(t, tc.expectedAttempts, attempts)
+
+			// Waits are extended by up to a tenth for jitter
+			require.Len(t, waits, len(tc.expectedWaits))
+			for i, wait := range waits {
+				assert.GreaterOrEqual(t, wait, tc.expectedWaits[i])
+				assert.LessOrEqual(t, wait, tc.expectedWaits[i]+tc.expectedWaits[i]/10)
+			}
+
+			if tc.expectedErr != nil {
+				var rateLimitErr *Error
+				require.ErrorAs(t, err, &rateLimitErr)
+				assert.Equal(t, tc.expectedErr.Secondary, rateLimitErr.Secondary)
+				assert.WithinDuration(t, tc.expectedErr.Until, rateLimitErr.Until, rateLimitErr.Until.Sub(now)/10)
+				return
+			}
+			require.NoError(t, err)
+			_ = resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+		})
+	}
+}
+
+func Test_TransportIgnoresOtherForbiddenResponses(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &Transport{MaxRetries: 3, MaxWait: time.Minute}}
+	resp, err := client.Get(ts.URL)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "Resource not accessible by integration")
+}
+
+func Test_ToolHandlerMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer ts.Close()
+
+	client := &http.Client{Transport: &Transport{MaxRetries: 3, MaxWait: time.Minute}}
+
+	// A tool reporting the failure as a plain error result, hiding the cause
+	handler := ToolHandlerMiddleware(func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		if err != nil {
+			return mcp.NewToolResultError("failed to get repository"), nil
+		}
+		_ = resp.Body.Close()
+		return mcp.NewToolResultText("ok"), nil
+	})
+
+	result, err := handler(context.Background(), mcp.CallToolRequest{})
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+
+	structured, ok := result.StructuredContent.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "rate_limited", structured["error"])
+	assert.Equal(t, false, structured["secondary_limit"])
+
+	until, err := time.Parse(time.RFC3339, structured["rate_limited_until"].(string))
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), until, 10*time.Minute)
+
+	text, ok := result.Content[0].(mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, text.Text, "rate limited until")
+}
+
+func Test_ErrorMessage(t *testing.T) {
+	err := &Error{Until: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC), Secondary: true}
+	assert.Equal(t, "GitHub API secondary rate limit exceeded, rate limited until 2025-01-01T12:00:00Z", err.Error())
+	assert.True(t, errors.As(fmt.Errorf("wrapped: %w", err), new(*Error)))
+}
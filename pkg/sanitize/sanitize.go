@@ -0,0 +1,225 @@
+// Package sanitize removes the content of user-written text that people reading it on GitHub never see, but models
+// read and may follow all the same: HTML comments, invisible Unicode tag characters, bidirectional controls and
+// zero-width characters.
+package sanitize
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// htmlComment matches an HTML comment, or one left open, which hides the rest of the text when rendered.
+var htmlComment = regexp.MustCompile(`(?s)<!--.*?(?:-->|$)`)
+
+// Removed counts the hidden content removed from text.
+type Removed struct {
+	HTMLComments        int
+	TagCharacters       int
+	BidiControls        int
+	ZeroWidthCharacters int
+}
+
+// Any tells whether anything was removed.
+func (r Removed) Any() bool {
+	return r != Removed{}
+}
+
+// add adds the counts of o to r.
+func (r *Removed) add(o Removed) {
+	r.HTMLComments += o.HTMLComments
+	r.TagCharacters += o.TagCharacters
+	r.BidiControls += o.BidiControls
+	r.ZeroWidthCharacters += o.ZeroWidthCharacters
+}
+
+// String lists what was removed, e.g. "1 HTML comment, 12 Unicode tag characters".
+func (r Removed) String() string {
+	var parts []string
+	for _, count := range []struct {
+		n        int
+		singular string
+		plural   string
+	}{
+		{r.HTMLComments, "HTML comment", "HTML comments"},
+		{r.TagCharacters, "Unicode tag character", "Unicode tag characters"},
+		{r.BidiControls, "bidirectional control character", "bidirectional control characters"},
+		{r.ZeroWidthCharacters, "zero-width character", "zero-width characters"},
+	} {
+		switch {
+		case count.n == 1:
+			parts = append(parts, "1 "+count.singular)
+		case count.n > 1:
+			parts = append(parts, fmt.Sprintf("%d %s", count.n, count.plural))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Text returns s without its hidden content, and what was removed from it.
+func Text(s string) (string, Removed) {
+	var removed Removed
+	s = htmlComment.ReplaceAllStringFunc(s, func(string) string {
+		removed.HTMLComments++
+		return ""
+	})
+	runes := []rune(s)
+	var b strings.Builder
+	for i, r := range runes {
+		switch {
+		case r >= 0xE0000 && r <= 0xE007F:
+			// Tag characters spell out ASCII text that is not rendered at all
+			removed.TagCharacters++
+		case r >= 0x202A && r <= 0x202E, r >= 0x2066 && r <= 0x2069:
+			// Embeddings, overrides and isolates reorder the text that is displayed
+			removed.BidiControls++
+		case r == 0x200D && i > 0 && i < len(runes)-1 && isEmoji(runes[i-1]) && isEmoji(runes[i+1]):
+			// Joiners combine emoji into one, such as the members of a family
+			b.WriteRune(r)
+		case (r == 0x200C || r == 0x200D) && i > 0 && i < len(runes)-1 && isJoinable(runes[i-1]) && isJoinable(runes[i+1]):
+			// Joiners and non-joiners between letters choose the forms of letters in scripts such as Persian and
+			// Devanagari
+			b.WriteRune(r)
+		case r >= 0x200B && r <= 0x200D, r >= 0x2060 && r <= 0x2064, r == 0xFEFF, r == 0x180E:
+			// Zero-width spaces, joiners and invisible operators can encode text between visible characters
+			removed.ZeroWidthCharacters++
+		default:
+			b.WriteRune(r)
+		}
+	}
+	if removed.TagCharacters+removed.BidiControls+removed.ZeroWidthCharacters > 0 {
+		s = b.String()
+	}
+	return s, removed
+}
+
+// isEmoji tells whether r is part of an emoji, which zero-width joiners may combine with another: a symbol, a skin
+// tone modifier or the emoji presentation selector. Other modifier symbols, such as ^ and `, are not.
+func isEmoji(r rune) bool {
+	return unicode.Is(unicode.So, r) || (r >= 0x1F3FB && r <= 0x1F3FF) || r == 0xFE0F
+}
+
+// isJoinable tells whether r is a letter or mark, outside of ASCII, whose form zero-width joiners and non-joiners
+// next to it may change.
+func isJoinable(r rune) bool {
+	return r > unicode.MaxASCII && unicode.In(r, unicode.L, unicode.Mn, unicode.Mc)
+}
+
+// proseFields are the JSON names of the fields holding user-written text, compared case-insensitively.
+var proseFields = map[string]bool{
+	"title":       true,
+	"body":        true,
+	"message":     true,
+	"description": true,
+}
+
+// report collects what was removed from the results of a tool call.
+type report struct {
+	removed Removed
+}
+
+// reportKey is the context key of the report of a tool call.
+type reportKey struct{}
+
+// ToolHandlerMiddleware enables Value for every tool call, and ends the results the tools sanitized with a note of
+// what was removed from them.
+func ToolHandlerMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		r := &report{}
+		result, err := next(context.WithValue(ctx, reportKey{}, r), request)
+		if err != nil || result == nil || !r.removed.Any() {
+			return result, err
+		}
+		result.Content = append(result.Content, mcp.NewTextContent(fmt.Sprintf(
+			"[Removed hidden content from user-written text: %s.]", r.removed)))
+		return result, nil
+	}
+}
+
+// Value removes the hidden content of the user-written text fields of v in place, if the tool call made with ctx
+// goes through ToolHandlerMiddleware. These are the string fields, and map values, named title, body, message or
+// description, however deeply nested in v, which must be a pointer, slice or map for them to be changed.
+func Value(ctx context.Context, v any) {
+	r, ok := ctx.Value(reportKey{}).(*report)
+	if !ok || v == nil {
+		return
+	}
+	w := walker{seen: make(map[uintptr]bool)}
+	w.walk(reflect.ValueOf(v), false)
+	r.removed.add(w.removed)
+}
+
+// walker walks a value, sanitizing the text of its prose fields.
+type walker struct {
+	removed Removed
+	// seen holds the pointers already walked, in case of cycles
+	seen map[uintptr]bool
+}
+
+// walk sanitizes v if it is a string held by a prose field, or the prose fields within it otherwise.
+func (w *walker) walk(v reflect.Value, prose bool) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || w.seen[v.Pointer()] {
+			return
+		}
+		w.seen[v.Pointer()] = true
+		w.walk(v.Elem(), prose)
+	case reflect.Interface:
+		if v.IsNil() || !v.CanSet() {
+			return
+		}
+		// The value held by an interface cannot be changed in place, so a copy of it is
+		elem := reflect.New(v.Elem().Type()).Elem()
+		elem.Set(v.Elem())
+		w.walk(elem, prose)
+		v.Set(elem)
+	case reflect.Struct:
+		t := v.Type()
+		for i := range t.NumField() {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			w.walk(v.Field(i), proseFields[strings.ToLower(name)])
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			w.walk(v.Index(i), prose)
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			return
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key()
+			elem := reflect.New(iter.Value().Type()).Elem()
+			elem.Set(iter.Value())
+			w.walk(elem, key.Kind() == reflect.String && proseFields[strings.ToLower(key.String())])
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.String:
+		if !prose || !v.CanSet() {
+			return
+		}
+		text, removed := Text(v.String())
+		if removed.Any() {
+			v.SetString(text)
+			w.removed.add(removed)
+		}
+	}
+}
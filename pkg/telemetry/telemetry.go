@@ -0,0 +1,261 @@
+// Package telemetry traces tool calls, resource reads and GitHub API requests with OpenTelemetry, and measures
+// them with Prometheus metrics.
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans of the server to the tracer provider.
+const instrumentationName = "github.com/github/github-mcp-server/pkg/telemetry"
+
+// requestIDHeader is the header GitHub identifies each API request with, for support to trace it.
+const requestIDHeader = "X-GitHub-Request-Id"
+
+// Attributes of the spans, alongside the standard HTTP ones.
+const (
+	ToolNameKey    = attribute.Key("mcp.tool.name")
+	ResourceURIKey = attribute.Key("mcp.resource.uri")
+	OwnerKey       = attribute.Key("github.owner")
+	RepoKey        = attribute.Key("github.repo")
+	APIKey         = attribute.Key("github.api")
+	RequestIDKey   = attribute.Key("github.request_id")
+	StatusCodeKey  = attribute.Key("http.response.status_code")
+)
+
+// APIs a request to GitHub can be made to, as the github.api attribute.
+const (
+	APIREST    = "rest"
+	APIGraphQL = "graphql"
+	APIRaw     = "raw"
+)
+
+// statusNone labels the errors of tool calls not caused by a GitHub API error, such as invalid arguments.
+const statusNone = "none"
+
+// Telemetry traces and measures the server's work. Spans are created by the tracer provider it is given, metrics
+// only kept if it is given a registerer.
+type Telemetry struct {
+	tracer  trace.Tracer
+	metrics *metrics
+}
+
+type metrics struct {
+	toolDuration       *prometheus.HistogramVec
+	toolErrors         *prometheus.CounterVec
+	rateLimitRemaining *prometheus.GaugeVec
+}
+
+// New returns a Telemetry creating spans with tracerProvider, or the global one if nil, and registering its
+// metrics with registerer, if not nil.
+func New(tracerProvider trace.TracerProvider, registerer prometheus.Registerer) (*Telemetry, error) {
+	if tracerProvider == nil {
+		tracerProvider = otel.GetTracerProvider()
+	}
+	t := &Telemetry{tracer: tracerProvider.Tracer(instrumentationName)}
+	if registerer == nil {
+		return t, nil
+	}
+
+	t.metrics = &metrics{
+		toolDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "github_mcp_tool_call_duration_seconds",
+			Help:    "Duration of tool calls, in seconds.",
+			Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		}, []string{"tool"}),
+		toolErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "github_mcp_tool_call_errors_total",
+			Help: "Number of failed tool calls, by the HTTP status of the GitHub API error causing them, or none.",
+		}, []string{"tool", "status"}),
+		rateLimitRemaining: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "github_mcp_rate_limit_remaining",
+			Help: "Requests remaining in the current GitHub rate limit window, as of the last response.",
+		}, []string{"host", "resource"}),
+	}
+	for _, collector := range []prometheus.Collector{t.metrics.toolDuration, t.metrics.toolErrors, t.metrics.rateLimitRemaining} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// ToolHandlerMiddleware traces every tool call, measuring its duration and counting its failures.
+func (t *Telemetry) ToolHandlerMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		tool := request.Params.Name
+		ctx, span := t.tracer.Start(ctx, "tools/call "+tool,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(append([]attribute.KeyValue{ToolNameKey.String(tool)}, repoAttributes(request.GetArguments())...)...),
+		)
+		defer span.End()
+
+		start := time.Now()
+		result, err := next(ctx, request)
+		if t.metrics != nil {
+			t.metrics.toolDuration.WithLabelValues(tool).Observe(time.Since(start).Seconds())
+		}
+
+		var message string
+		switch {
+		case err != nil:
+			span.RecordError(err)
+			message = err.Error()
+		case result != nil && result.IsError:
+			message = resultText(result)
+		default:
+			return result, err
+		}
+		span.SetStatus(codes.Error, message)
+
+		// Tell the failures GitHub caused apart from the others
+		status := statusNone
+		if apiErr := lastAPIError(ctx); apiErr != nil {
+			status = strconv.Itoa(apiErr.Response.StatusCode)
+			span.SetAttributes(StatusCodeKey.Int(apiErr.Response.StatusCode))
+			if id := apiErr.Response.Header.Get(requestIDHeader); id != "" {
+				span.SetAttributes(RequestIDKey.String(id))
+			}
+		}
+		if t.metrics != nil {
+			t.metrics.toolErrors.WithLabelValues(tool, status).Inc()
+		}
+		return result, err
+	}
+}
+
+// ResourceTemplateHandler traces the reads of resources, such as repo://{owner}/{repo}/contents{/path*}.
+func (t *Telemetry) ResourceTemplateHandler(next server.ResourceTemplateHandlerFunc) server.ResourceTemplateHandlerFunc {
+	return func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		args := make(map[string]any, len(request.Params.Arguments))
+		for name, value := range request.Params.Arguments {
+			// Template variables are matched as lists of values
+			if values, ok := value.([]string); ok && len(values) > 0 {
+				value = values[0]
+			}
+			args[name] = value
+		}
+		ctx, span := t.tracer.Start(ctx, "resources/read",
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(append([]attribute.KeyValue{ResourceURIKey.String(request.Params.URI)}, repoAttributes(args)...)...),
+		)
+		defer span.End()
+
+		contents, err := next(ctx, request)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		return contents, err
+	}
+}
+
+// Transport is an http.RoundTripper tracing the requests made to GitHub, and keeping track of the rate limits
+// their responses report.
+type Transport struct {
+	// Base is the underlying RoundTripper, http.DefaultTransport if nil.
+	Base http.RoundTripper
+
+	// Telemetry creates the spans and keeps the metrics
+	Telemetry *Telemetry
+
+	// API returns the API a request is made to, APIREST if nil
+	API func(req *http.Request) string
+}
+
+// RoundTrip sends req using the base transport, within a client span.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	api := APIREST
+	if t.API != nil {
+		api = t.API(req)
+	}
+
+	ctx, span := t.Telemetry.tracer.Start(req.Context(), req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("url.full", req.URL.Redacted()),
+			attribute.String("server.address", req.URL.Hostname()),
+			APIKey.String(api),
+		),
+	)
+	defer span.End()
+
+	resp, err := base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+
+	span.SetAttributes(StatusCodeKey.Int(resp.StatusCode))
+	if id := resp.Header.Get(requestIDHeader); id != "" {
+		span.SetAttributes(RequestIDKey.String(id))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+
+	if t.Telemetry.metrics != nil {
+		if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+			resource := resp.Header.Get("X-RateLimit-Resource")
+			if resource == "" {
+				resource = "core"
+			}
+			t.Telemetry.metrics.rateLimitRemaining.WithLabelValues(req.URL.Host, resource).Set(float64(remaining))
+		}
+	}
+	return resp, nil
+}
+
+// repoAttributes returns the attributes of the owner and repo arguments of a call, if any.
+func repoAttributes(args map[string]any) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	if owner, ok := args["owner"].(string); ok && owner != "" {
+		attrs = append(attrs, OwnerKey.String(owner))
+	}
+	if repo, ok := args["repo"].(string); ok && repo != "" {
+		attrs = append(attrs, RepoKey.String(repo))
+	}
+	return attrs
+}
+
+// lastAPIError returns the last GitHub API error with a response that the tool reported in ctx, if any.
+func lastAPIError(ctx context.Context) *ghErrors.GitHubAPIError {
+	apiErrors, err := ghErrors.GetGitHubAPIErrors(ctx)
+	if err != nil {
+		return nil
+	}
+	for i := len(apiErrors) - 1; i >= 0; i-- {
+		if apiErrors[i].Response != nil && apiErrors[i].Response.Response != nil {
+			return apiErrors[i]
+		}
+	}
+	return nil
+}
+
+// resultText returns the text of an error result.
+func resultText(result *mcp.CallToolResult) string {
+	for _, content := range result.Content {
+		if text, ok := content.(mcp.TextContent); ok {
+			return text.Text
+		}
+	}
+	return ""
+}
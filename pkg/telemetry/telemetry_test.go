@@ -0,0 +1,226 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ghErrors "github.com/github/github-mcp-server/pkg/errors"
+	gogithub "github.com/google/go-github/v74/github"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestTelemetry(t *testing.T) (*Telemetry, *tracetest.SpanRecorder) {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	tel, err := New(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), prometheus.NewRegistry())
+	require.NoError(t, err)
+	return tel, recorder
+}
+
+// attributes returns the attributes of a span by key.
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func Test_ToolHandlerMiddleware(t *testing.T) {
+	notFound := &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{"X-Github-Request-Id": []string{"ABCD:1234"}}}
+
+	tests := []struct {
+		name               string
+		handler            server.ToolHandlerFunc
+		expectedStatus     codes.Code
+		expectedErrorLabel string
+		expectedStatusCode int64
+		expectedRequestID  string
+	}{
+		{
+			name: "success",
+			handler: func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText("issue"), nil
+			},
+			expectedStatus: codes.Unset,
+		},
+		{
+			name: "GitHub API error",
+			handler: func(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return ghErrors.NewGitHubAPIErrorResponse(ctx, "failed to get issue", &gogithub.Response{Response: notFound}, errors.New("404 Not Found")), nil
+			},
+			expectedStatus:     codes.Error,
+			expectedErrorLabel: "404",
+			expectedStatusCode: http.StatusNotFound,
+			expectedRequestID:  "ABCD:1234",
+		},
+		{
+			name: "invalid arguments",
+			handler: func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultError("missing required parameter: issue_number"), nil
+			},
+			expectedStatus:     codes.Error,
+			expectedErrorLabel: "none",
+		},
+		{
+			name: "handler error",
+			handler: func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return nil, errors.New("failed to get GitHub client")
+			},
+			expectedStatus:     codes.Error,
+			expectedErrorLabel: "none",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tel, recorder := newTestTelemetry(t)
+
+			request := mcp.CallToolRequest{}
+			request.Params.Name = "get_issue"
+			request.Params.Arguments = map[string]any{"owner": "octocat", "repo": "hello-world", "issue_number": float64(1)}
+			_, _ = tel.ToolHandlerMiddleware(tc.handler)(ghErrors.ContextWithGitHubErrors(context.Background()), request)
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			span := spans[0]
+			assert.Equal(t, "tools/call get_issue", span.Name())
+			assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+			assert.Equal(t, tc.expectedStatus, span.Status().Code)
+
+			attrs := attributes(span)
+			assert.Equal(t, "get_issue", attrs[ToolNameKey].AsString())
+			assert.Equal(t, "octocat", attrs[OwnerKey].AsString())
+			assert.Equal(t, "hello-world", attrs[RepoKey].AsString())
+			assert.Equal(t, tc.expectedStatusCode, attrs[StatusCodeKey].AsInt64())
+			assert.Equal(t, tc.expectedRequestID, attrs[RequestIDKey].AsString())
+
+			metrics := tel.metrics
+			assert.Equal(t, 1, testutil.CollectAndCount(metrics.toolDuration))
+			if tc.expectedErrorLabel == "" {
+				assert.Equal(t, 0, testutil.CollectAndCount(metrics.toolErrors))
+			} else {
+				assert.Equal(t, float64(1), testutil.ToFloat64(metrics.toolErrors.WithLabelValues("get_issue", tc.expectedErrorLabel)))
+			}
+		})
+	}
+}
+
+func Test_ResourceTemplateHandler(t *testing.T) {
+	tel, recorder := newTestTelemetry(t)
+
+	handler := tel.ResourceTemplateHandler(func(_ context.Context, _ mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return nil, errors.New("404 Not Found")
+	})
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = "repo://octocat/hello-world/contents/README.md"
+	request.Params.Arguments = map[string]any{"owner": []string{"octocat"}, "repo": []string{"hello-world"}, "path": []string{"README.md"}}
+	_, err := handler(context.Background(), request)
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "resources/read", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	attrs := attributes(spans[0])
+	assert.Equal(t, request.Params.URI, attrs[ResourceURIKey].AsString())
+	assert.Equal(t, "octocat", attrs[OwnerKey].AsString())
+	assert.Equal(t, "hello-world", attrs[RepoKey].AsString())
+}
+
+func Test_Transport(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-GitHub-Request-Id", "ABCD:1234")
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+		if r.URL.Path == "/graphql" {
+			w.Header().Set("X-RateLimit-Resource", "graphql")
+			_, _ = w.Write([]byte(`{"data": {}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(api.Close)
+
+	tel, recorder := newTestTelemetry(t)
+	client := &http.Client{Transport: &Transport{
+		Telemetry: tel,
+		API: func(req *http.Request) string {
+			if req.URL.Path == "/graphql" {
+				return APIGraphQL
+			}
+			return APIREST
+		},
+	}}
+
+	// Requests are traced within the span of the tool call making them
+	ctx, parent := tel.tracer.Start(context.Background(), "tools/call get_issue")
+	for _, path := range []string{"/repos/octocat/hello-world/issues/1", "/graphql"} {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, api.URL+path, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+	}
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	for i, expected := range []struct {
+		api        string
+		statusCode int64
+		status     codes.Code
+	}{
+		{api: APIREST, statusCode: http.StatusNotFound, status: codes.Error},
+		{api: APIGraphQL, statusCode: http.StatusOK, status: codes.Unset},
+	} {
+		span := spans[i]
+		assert.Equal(t, http.MethodGet, span.Name())
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Equal(t, expected.status, span.Status().Code)
+
+		attrs := attributes(span)
+		assert.Equal(t, expected.api, attrs[APIKey].AsString())
+		assert.Equal(t, expected.statusCode, attrs[StatusCodeKey].AsInt64())
+		assert.Equal(t, "ABCD:1234", attrs[RequestIDKey].AsString())
+	}
+
+	host := api.Listener.Addr().String()
+	assert.Equal(t, float64(4999), testutil.ToFloat64(tel.metrics.rateLimitRemaining.WithLabelValues(host, "core")))
+	assert.Equal(t, float64(4999), testutil.ToFloat64(tel.metrics.rateLimitRemaining.WithLabelValues(host, "graphql")))
+}
+
+func Test_New(t *testing.T) {
+	// Without a registerer, nothing is measured
+	tel, err := New(nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, tel.metrics)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "get_me"
+	_, err = tel.ToolHandlerMiddleware(func(_ context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return mcp.NewToolResultError("failed"), nil
+	})(context.Background(), request)
+	require.NoError(t, err)
+
+	// Metrics are only registered once
+	registry := prometheus.NewRegistry()
+	_, err = New(nil, registry)
+	require.NoError(t, err)
+	_, err = New(nil, registry)
+	require.Error(t, err)
+}
@@ -1,7 +1,13 @@
 package toolsets
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"path"
+	"sort"
+	"sync"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
@@ -93,6 +99,25 @@ func (t *Toolset) RegisterTools(s *server.MCPServer) {
 	}
 }
 
+// filterTools removes the tools rejected by filter, returning them.
+func (t *Toolset) filterTools(filter ToolFilter) []HiddenTool {
+	var hidden []HiddenTool
+	keep := func(tools []server.ServerTool) []server.ServerTool {
+		kept := tools[:0]
+		for _, tool := range tools {
+			if reason, ok := filter(tool.Tool.Name); !ok {
+				hidden = append(hidden, HiddenTool{Name: tool.Tool.Name, Toolset: t.Name, Reason: reason})
+				continue
+			}
+			kept = append(kept, tool)
+		}
+		return kept
+	}
+	t.readTools = keep(t.readTools)
+	t.writeTools = keep(t.writeTools)
+	return hidden
+}
+
 func (t *Toolset) AddResourceTemplates(templates ...server.ServerResourceTemplate) *Toolset {
 	t.resourceTemplates = append(t.resourceTemplates, templates...)
 	return t
@@ -160,17 +185,66 @@ func (t *Toolset) AddReadTools(tools ...server.ServerTool) *Toolset {
 	return t
 }
 
+// ToolFilter decides whether the named tool is offered, returning the reason if it is not.
+type ToolFilter func(tool string) (reason string, ok bool)
+
+// HiddenTool is a tool removed by a ToolFilter.
+type HiddenTool struct {
+	Name    string
+	Toolset string
+	Reason  string
+}
+
+// NewPatternToolFilter returns a filter offering the tools matching any of the include patterns, or every tool if
+// there are none, unless they match any of the exclude patterns. Patterns are globs as understood by path.Match,
+// such as create_* or delete_file.
+func NewPatternToolFilter(include, exclude []string) (ToolFilter, error) {
+	for _, pattern := range append(append([]string(nil), include...), exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+
+	return func(tool string) (string, bool) {
+		if len(include) > 0 && matchingPattern(include, tool) == "" {
+			return "not included by any tool pattern", false
+		}
+		if pattern := matchingPattern(exclude, tool); pattern != "" {
+			return fmt.Sprintf("excluded by tool pattern %s", pattern), false
+		}
+		return "", true
+	}, nil
+}
+
+// matchingPattern returns the first of patterns matching tool, or an empty string if there is none.
+func matchingPattern(patterns []string, tool string) string {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, tool); ok {
+			return pattern
+		}
+	}
+	return ""
+}
+
 type ToolsetGroup struct {
 	Toolsets     map[string]*Toolset
 	everythingOn bool
 	readOnly     bool
+
+	// sessionToolsets holds the toolsets enabled by individual sessions, keyed by session ID,
+	// on top of the toolsets enabled for every session.
+	sessionMu       sync.RWMutex
+	sessionToolsets map[string]map[string]bool
+	// toolsetsByTool maps the name of each tool registered by RegisterSessionTools to the name of its toolset.
+	toolsetsByTool map[string]string
 }
 
 func NewToolsetGroup(readOnly bool) *ToolsetGroup {
 	return &ToolsetGroup{
-		Toolsets:     make(map[string]*Toolset),
-		everythingOn: false,
-		readOnly:     readOnly,
+		Toolsets:        make(map[string]*Toolset),
+		everythingOn:    false,
+		readOnly:        readOnly,
+		sessionToolsets: make(map[string]map[string]bool),
 	}
 }
 
@@ -238,6 +312,163 @@ func (tg *ToolsetGroup) EnableToolset(name string) error {
 	return nil
 }
 
+// EnableSessionToolset enables a toolset for a single session only, leaving other sessions unaffected.
+func (tg *ToolsetGroup) EnableSessionToolset(sessionID string, name string) error {
+	if _, exists := tg.Toolsets[name]; !exists {
+		return NewToolsetDoesNotExistError(name)
+	}
+
+	tg.sessionMu.Lock()
+	defer tg.sessionMu.Unlock()
+	if tg.sessionToolsets[sessionID] == nil {
+		tg.sessionToolsets[sessionID] = make(map[string]bool)
+	}
+	tg.sessionToolsets[sessionID][name] = true
+	return nil
+}
+
+// IsEnabledForSession reports whether a toolset is enabled for every session, or for the given one.
+func (tg *ToolsetGroup) IsEnabledForSession(sessionID string, name string) bool {
+	if tg.IsEnabled(name) {
+		return true
+	}
+
+	tg.sessionMu.RLock()
+	defer tg.sessionMu.RUnlock()
+	return tg.sessionToolsets[sessionID][name]
+}
+
+// ForgetSession drops the toolsets enabled by a session, e.g. once it has ended.
+func (tg *ToolsetGroup) ForgetSession(sessionID string) {
+	tg.sessionMu.Lock()
+	defer tg.sessionMu.Unlock()
+	delete(tg.sessionToolsets, sessionID)
+}
+
+// RegisterSessionTools registers the tools of every toolset that is not enabled for all sessions, so that
+// sessions can enable them individually with EnableSessionToolset. Servers doing so must be created with
+// FilterSessionTools as a tool filter and SessionToolMiddleware as a tool handler middleware, which hide
+// these tools from, and reject calls to them by, sessions that have not enabled them.
+func (tg *ToolsetGroup) RegisterSessionTools(s *server.MCPServer) {
+	tg.toolsetsByTool = make(map[string]string)
+	for name, toolset := range tg.Toolsets {
+		if tg.IsEnabled(name) {
+			continue
+		}
+		tools := toolset.GetAvailableTools()
+		for _, tool := range tools {
+			tg.toolsetsByTool[tool.Tool.Name] = name
+		}
+		s.AddTools(tools...)
+	}
+}
+
+// FilterSessionTools filters out the tools of toolsets that are not enabled for the session in ctx.
+func (tg *ToolsetGroup) FilterSessionTools(ctx context.Context, tools []mcp.Tool) []mcp.Tool {
+	sessionID := SessionIDFromContext(ctx)
+	filtered := make([]mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		if name, ok := tg.toolsetsByTool[tool.Name]; ok && !tg.IsEnabledForSession(sessionID, name) {
+			continue
+		}
+		filtered = append(filtered, tool)
+	}
+	return filtered
+}
+
+// SessionToolMiddleware rejects calls to tools of toolsets that are not enabled for the calling session.
+func (tg *ToolsetGroup) SessionToolMiddleware(next server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		name, ok := tg.toolsetsByTool[request.Params.Name]
+		if ok && !tg.IsEnabledForSession(SessionIDFromContext(ctx), name) {
+			return mcp.NewToolResultError(fmt.Sprintf("tool %s belongs to toolset %s, which is not enabled", request.Params.Name, name)), nil
+		}
+		return next(ctx, request)
+	}
+}
+
+// SessionIDFromContext returns the ID of the client session in ctx, or an empty string if there is none.
+func SessionIDFromContext(ctx context.Context) string {
+	if session := server.ClientSessionFromContext(ctx); session != nil {
+		return session.SessionID()
+	}
+	return ""
+}
+
+// FilterTools removes the tools rejected by filter from every toolset, so that they are neither registered
+// nor reported by dynamic tool discovery, and returns the removed tools.
+func (tg *ToolsetGroup) FilterTools(filter ToolFilter) []HiddenTool {
+	var hidden []HiddenTool
+	for _, toolset := range tg.Toolsets {
+		hidden = append(hidden, toolset.filterTools(filter)...)
+	}
+	sort.Slice(hidden, func(i, j int) bool { return hidden[i].Name < hidden[j].Name })
+	return hidden
+}
+
+// IsWriteTool reports whether the named tool was added to any toolset with AddWriteTools.
+func (tg *ToolsetGroup) IsWriteTool(name string) bool {
+	for _, toolset := range tg.Toolsets {
+		for _, tool := range toolset.writeTools {
+			if tool.Tool.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UpdateTools applies update to every tool of every toolset before they are registered, e.g. to add a
+// parameter to all of them.
+func (tg *ToolsetGroup) UpdateTools(update func(tool *mcp.Tool)) {
+	for _, toolset := range tg.Toolsets {
+		for i := range toolset.readTools {
+			updateTool(&toolset.readTools[i].Tool, update)
+		}
+		for i := range toolset.writeTools {
+			updateTool(&toolset.writeTools[i].Tool, update)
+		}
+	}
+}
+
+// noParametersSchema is the input schema of tools taking no parameters.
+var noParametersSchema = json.RawMessage(`{"properties":{},"type":"object"}`)
+
+// WithNoParameters declares that a tool takes no parameters. Its input schema keeps an empty properties object,
+// which mcp-go otherwise leaves out but some clients require.
+func WithNoParameters() mcp.ToolOption {
+	return func(tool *mcp.Tool) {
+		tool.InputSchema = mcp.ToolInputSchema{}
+		tool.RawInputSchema = noParametersSchema
+	}
+}
+
+// updateTool applies update to tool. A tool declared with WithNoParameters is given a structured input schema
+// for update to add parameters to, and keeps its own if none are added.
+func updateTool(tool *mcp.Tool, update func(tool *mcp.Tool)) {
+	if !bytes.Equal(tool.RawInputSchema, noParametersSchema) {
+		update(tool)
+		return
+	}
+
+	tool.RawInputSchema = nil
+	tool.InputSchema = mcp.ToolInputSchema{Type: "object", Properties: make(map[string]any)}
+	update(tool)
+	if len(tool.InputSchema.Properties) == 0 {
+		WithNoParameters()(tool)
+	}
+}
+
+// UpdateResourceTemplates applies update to every resource template of every toolset before they are registered,
+// e.g. to wrap their handlers.
+func (tg *ToolsetGroup) UpdateResourceTemplates(update func(template *server.ServerResourceTemplate)) {
+	for _, toolset := range tg.Toolsets {
+		for i := range toolset.resourceTemplates {
+			update(&toolset.resourceTemplates[i])
+		}
+	}
+}
+
 func (tg *ToolsetGroup) RegisterAll(s *server.MCPServer) {
 	for _, toolset := range tg.Toolsets {
 		toolset.RegisterTools(s)
@@ -1,8 +1,14 @@
 package toolsets
 
 import (
+	"encoding/json"
 	"errors"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
 )
 
 func TestNewToolsetGroupIsEmptyWithoutEverythingOn(t *testing.T) {
@@ -250,3 +256,173 @@ func TestToolsetGroup_GetToolset(t *testing.T) {
 		t.Errorf("expected error to be ToolsetDoesNotExistError, got %v", err)
 	}
 }
+
+func TestEnableSessionToolset(t *testing.T) {
+	tsg := NewToolsetGroup(false)
+	tsg.AddToolset(NewToolset("test-toolset", "A test toolset"))
+
+	err := tsg.EnableSessionToolset("session-a", "non-existent")
+	if !errors.Is(err, NewToolsetDoesNotExistError("non-existent")) {
+		t.Errorf("Expected ToolsetDoesNotExistError, got %v", err)
+	}
+
+	if err := tsg.EnableSessionToolset("session-a", "test-toolset"); err != nil {
+		t.Fatalf("Expected no error when enabling toolset for a session, got: %v", err)
+	}
+
+	if !tsg.IsEnabledForSession("session-a", "test-toolset") {
+		t.Error("Expected toolset to be enabled for the session that enabled it")
+	}
+	if tsg.IsEnabledForSession("session-b", "test-toolset") {
+		t.Error("Expected toolset to remain disabled for other sessions")
+	}
+	if tsg.IsEnabled("test-toolset") {
+		t.Error("Expected toolset to remain disabled globally")
+	}
+
+	tsg.ForgetSession("session-a")
+	if tsg.IsEnabledForSession("session-a", "test-toolset") {
+		t.Error("Expected toolset to be disabled once the session is forgotten")
+	}
+}
+
+func TestIsEnabledForSessionWithGlobalToolset(t *testing.T) {
+	tsg := NewToolsetGroup(false)
+	tsg.AddToolset(NewToolset("test-toolset", "A test toolset"))
+
+	if err := tsg.EnableToolset("test-toolset"); err != nil {
+		t.Fatalf("Expected no error when enabling toolset, got: %v", err)
+	}
+
+	if !tsg.IsEnabledForSession("any-session", "test-toolset") {
+		t.Error("Expected globally enabled toolset to be enabled for every session")
+	}
+}
+
+func TestFilterTools(t *testing.T) {
+	readOnly, writable := true, false
+	newTool := func(name string, readOnlyHint *bool) server.ServerTool {
+		return NewServerTool(mcp.NewTool(name, mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: readOnlyHint})), nil)
+	}
+
+	tsg := NewToolsetGroup(false)
+	tsg.AddToolset(NewToolset("test-toolset", "A test toolset").
+		AddReadTools(newTool("read_kept", &readOnly), newTool("read_hidden", &readOnly)).
+		AddWriteTools(newTool("write_hidden", &writable)))
+
+	hidden := tsg.FilterTools(func(tool string) (string, bool) {
+		if strings.HasSuffix(tool, "_hidden") {
+			return "hidden by test", false
+		}
+		return "", true
+	})
+
+	expected := []HiddenTool{
+		{Name: "read_hidden", Toolset: "test-toolset", Reason: "hidden by test"},
+		{Name: "write_hidden", Toolset: "test-toolset", Reason: "hidden by test"},
+	}
+	if !reflect.DeepEqual(hidden, expected) {
+		t.Errorf("Expected hidden tools %v, got %v", expected, hidden)
+	}
+
+	available := tsg.Toolsets["test-toolset"].GetAvailableTools()
+	if len(available) != 1 || available[0].Tool.Name != "read_kept" {
+		t.Errorf("Expected only read_kept to remain available, got %v", available)
+	}
+}
+
+func TestUpdateTools(t *testing.T) {
+	readOnly, writable := true, false
+	tsg := NewToolsetGroup(false)
+	tsg.AddToolset(NewToolset("test-toolset", "A test toolset").
+		AddReadTools(NewServerTool(mcp.NewTool("read", mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: &readOnly})), nil)).
+		AddWriteTools(NewServerTool(mcp.NewTool("write", mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: &writable})), nil)))
+
+	tsg.UpdateTools(mcp.WithString("extra"))
+
+	for _, tool := range tsg.Toolsets["test-toolset"].GetAvailableTools() {
+		if _, ok := tool.Tool.InputSchema.Properties["extra"]; !ok {
+			t.Errorf("Expected tool %s to have the extra parameter", tool.Tool.Name)
+		}
+	}
+}
+
+func TestUpdateToolsWithNoParameters(t *testing.T) {
+	readOnly := true
+	tsg := NewToolsetGroup(false)
+	tsg.AddToolset(NewToolset("test-toolset", "A test toolset").
+		AddReadTools(NewServerTool(mcp.NewTool("read", mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: &readOnly}), WithNoParameters()), nil)))
+	tool := &tsg.Toolsets["test-toolset"].readTools[0].Tool
+
+	// The empty properties are kept when no parameter is added
+	tsg.UpdateTools(func(_ *mcp.Tool) {})
+	data, err := json.Marshal(tool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"inputSchema":{"properties":{},"type":"object"}`) {
+		t.Errorf("Expected the input schema to keep its empty properties, got %s", data)
+	}
+
+	// Parameters can still be added
+	tsg.UpdateTools(mcp.WithString("extra"))
+	if _, ok := tool.InputSchema.Properties["extra"]; !ok || tool.RawInputSchema != nil {
+		t.Errorf("Expected the tool to have the extra parameter, got %+v", tool.InputSchema)
+	}
+	if _, err := json.Marshal(tool); err != nil {
+		t.Errorf("Expected the tool to marshal, got %v", err)
+	}
+}
+
+func TestIsWriteTool(t *testing.T) {
+	readOnly, writable := true, false
+	tsg := NewToolsetGroup(false)
+	tsg.AddToolset(NewToolset("test-toolset", "A test toolset").
+		AddReadTools(NewServerTool(mcp.NewTool("read", mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: &readOnly})), nil)).
+		AddWriteTools(NewServerTool(mcp.NewTool("write", mcp.WithToolAnnotation(mcp.ToolAnnotation{ReadOnlyHint: &writable})), nil)))
+
+	if !tsg.IsWriteTool("write") {
+		t.Errorf("Expected write to be a write tool")
+	}
+	if tsg.IsWriteTool("read") {
+		t.Errorf("Expected read not to be a write tool")
+	}
+	if tsg.IsWriteTool("unknown") {
+		t.Errorf("Expected unknown not to be a write tool")
+	}
+}
+
+func TestNewPatternToolFilter(t *testing.T) {
+	tests := []struct {
+		name           string
+		include        []string
+		exclude        []string
+		tool           string
+		expectedOK     bool
+		expectedReason string
+	}{
+		{name: "no patterns", tool: "create_issue", expectedOK: true},
+		{name: "included", include: []string{"get_*", "create_*"}, tool: "create_issue", expectedOK: true},
+		{name: "not included", include: []string{"get_*"}, tool: "create_issue", expectedReason: "not included by any tool pattern"},
+		{name: "excluded", exclude: []string{"merge_pull_request"}, tool: "merge_pull_request", expectedReason: "excluded by tool pattern merge_pull_request"},
+		{name: "exclude wins over include", include: []string{"*_pull_request"}, exclude: []string{"merge_*"}, tool: "merge_pull_request", expectedReason: "excluded by tool pattern merge_*"},
+		{name: "not excluded", exclude: []string{"delete_*"}, tool: "delete", expectedOK: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := NewPatternToolFilter(tc.include, tc.exclude)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			reason, ok := filter(tc.tool)
+			if ok != tc.expectedOK || reason != tc.expectedReason {
+				t.Errorf("Expected (%q, %v), got (%q, %v)", tc.expectedReason, tc.expectedOK, reason, ok)
+			}
+		})
+	}
+
+	if _, err := NewPatternToolFilter(nil, []string{"delete_["}); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}